@@ -0,0 +1,129 @@
+// Command crystal-decrypt writes the plaintext of encrypted log files to standard output.
+//
+// Usage:
+//
+//	crystal-decrypt -key-file log.keys app.log 'log.*.log.gz.enc'
+//	crystal-decrypt -key-file log.keys < app.log | grep ERROR
+//
+// Files are decrypted in the order given, as glob patterns or names; with no files the
+// log is read from standard input. Rotated .gz.enc files are decompressed after decryption.
+// The key file holds one key per line as ID=HEX, or HEX alone for a key with an empty
+// ID; lines starting with # are ignored. Several keys can be given so files written
+// before a key rotation still decrypt. Without -key-file a single key is read from the
+// CRYSTAL_LOG_KEY environment variable.
+//
+// The exit status is 0 when every file decrypts, 1 when a file fails authentication or
+// is not an encrypted log, and 2 on usage or I/O errors.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"crystal/internal/encryption"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("crystal-decrypt", flag.ContinueOnError)
+	keyFile := flags.String("key-file", "", "file holding ID=HEX keys, one per line (default $CRYSTAL_LOG_KEY)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	keys, err := loadKeys(*keyFile)
+	if err != nil {
+		return fail(err, 2)
+	}
+
+	var files []string
+	for _, arg := range flags.Args() {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return fail(err, 2)
+		}
+		if len(matches) == 0 {
+			matches = []string{arg}
+		}
+		files = append(files, matches...)
+	}
+
+	out := bufio.NewWriterSize(os.Stdout, 64*1024)
+	defer out.Flush()
+	if len(files) == 0 {
+		return decrypt(out, os.Stdin, "stdin", keys)
+	}
+	for _, path := range files {
+		f, err := os.Open(path)
+		if err != nil {
+			return fail(err, 2)
+		}
+		status := decrypt(out, f, path, keys)
+		f.Close()
+		if status != 0 {
+			return status
+		}
+	}
+	return 0
+}
+
+func decrypt(out io.Writer, in io.Reader, name string, keys encryption.KeyFunc) int {
+	r, err := encryption.NewReader(in, keys)
+	if err == nil {
+		_, err = io.Copy(out, r)
+	}
+	if err == nil {
+		return 0
+	}
+	status := 2
+	for _, target := range []error{encryption.ErrAuthFailed, encryption.ErrCorrupt, encryption.ErrNotEncrypted,
+		encryption.ErrUnknownKey, io.ErrUnexpectedEOF} {
+		if errors.Is(err, target) {
+			status = 1
+		}
+	}
+	return fail(fmt.Errorf("%s: %w", name, err), status)
+}
+
+func loadKeys(path string) (encryption.KeyFunc, error) {
+	var lines []string
+	if path == "" {
+		lines = []string{os.Getenv("CRYSTAL_LOG_KEY")}
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		lines = strings.Split(string(data), "\n")
+	}
+	var keys []encryption.Key
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, err := encryption.ParseKey(line)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no keys; use -key-file or CRYSTAL_LOG_KEY")
+	}
+	return encryption.Keys(keys...), nil
+}
+
+func fail(err error, status int) int {
+	fmt.Fprintln(os.Stderr, "crystal-decrypt:", err)
+	return status
+}
@@ -0,0 +1,151 @@
+// Command crystal-verify checks hash-chained log files written through integrity.Writer.
+//
+// Usage:
+//
+//	crystal-verify -secret-file chain.key [-pubkey-file checkpoint.pub] [-start SEQ:MAC | -allow-missing-head]
+//		[-end SEQ:MAC] [-require-checkpoint] app.log app-*.log.gz
+//
+// Files may be given in any order and as glob patterns; rotated files, compressed or not,
+// are ordered by their first record. The secret is read from -secret-file or from the
+// CRYSTAL_CHAIN_SECRET environment variable. The optional public key file holds a
+// hex-encoded Ed25519 key that every checkpoint must be signed with.
+//
+// The chain must start at record 1 unless the oldest files were deleted on purpose. Then
+// pass the head they ended at, as printed after OK by an earlier run, with -start, or
+// accept the first remaining record on trust with -allow-missing-head, which prints a
+// warning to standard error.
+//
+// Deleting the newest records or files leaves a shorter chain that still verifies. Keep
+// the head printed after OK somewhere the log host cannot write to and pass it with -end
+// on the next run; the chain must then reach that head. A chain that does not end with a
+// checkpoint, as written on Close, prints a warning, or fails with -require-checkpoint.
+//
+// The exit status is 0 when the chain verifies, 1 at the first broken link and 2 on usage
+// or I/O errors.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"crystal/internal/integrity"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("crystal-verify", flag.ContinueOnError)
+	secretFile := flags.String("secret-file", "", "file holding the HMAC chain secret (default $CRYSTAL_CHAIN_SECRET)")
+	pubkeyFile := flags.String("pubkey-file", "", "file holding the hex Ed25519 public key checkpoints are signed with")
+	start := flags.String("start", "", "chain head SEQ:MAC before the first file, from an earlier run")
+	end := flags.String("end", "", "chain head SEQ:MAC published by an earlier run that the chain must reach")
+	requireCheckpoint := flags.Bool("require-checkpoint", false, "fail when the last record is not a checkpoint")
+	allowMissingHead := flags.Bool("allow-missing-head", false, "take the first record on trust when the chain does not start at record 1")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	secret := []byte(os.Getenv("CRYSTAL_CHAIN_SECRET"))
+	if *secretFile != "" {
+		data, err := os.ReadFile(*secretFile)
+		if err != nil {
+			return fail(err)
+		}
+		secret = []byte(strings.TrimRight(string(data), "\r\n"))
+	}
+	if len(secret) == 0 {
+		return fail(errors.New("no chain secret; use -secret-file or CRYSTAL_CHAIN_SECRET"))
+	}
+	verifier := &integrity.Verifier{Secret: secret, AllowMissingHead: *allowMissingHead, RequireCheckpoint: *requireCheckpoint}
+	if *start != "" {
+		link, err := parseLink("-start", *start)
+		if err != nil {
+			return fail(err)
+		}
+		verifier.Start = link
+	}
+	if *end != "" {
+		link, err := parseLink("-end", *end)
+		if err != nil {
+			return fail(err)
+		}
+		verifier.End = link
+	}
+	if *pubkeyFile != "" {
+		data, err := os.ReadFile(*pubkeyFile)
+		if err != nil {
+			return fail(err)
+		}
+		key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fail(fmt.Errorf("%s: not a hex Ed25519 public key", *pubkeyFile))
+		}
+		verifier.PublicKey = key
+	}
+
+	var files []string
+	for _, arg := range flags.Args() {
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return fail(err)
+		}
+		if len(matches) == 0 {
+			matches = []string{arg}
+		}
+		files = append(files, matches...)
+	}
+	if len(files) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	result, err := verifier.VerifyFiles(files...)
+	var broken *integrity.BrokenLinkError
+	if errors.As(err, &broken) {
+		fmt.Printf("BROKEN %s\n", broken)
+		return 1
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if result.Trusted {
+		fmt.Fprintf(os.Stderr, "crystal-verify: warning: records before %d are missing; record %d was taken on trust\n",
+			result.First, result.First)
+	}
+	if !result.EndsWithCheckpoint {
+		fmt.Fprintf(os.Stderr, "crystal-verify: warning: the chain does not end with a checkpoint; records after %d may have been deleted\n",
+			result.Last.Seq)
+	}
+	fmt.Printf("OK %d records (%d-%d) in %d files, %d checkpoints, head %d:%x\n",
+		result.Records, result.First, result.Last.Seq, len(result.Files), result.Checkpoints, result.Last.Seq, result.Last.MAC)
+	return 0
+}
+
+// parseLink parses a chain head written as SEQ:MAC with the MAC in hex.
+func parseLink(name, s string) (integrity.Link, error) {
+	var link integrity.Link
+	seq, mac, ok := strings.Cut(s, ":")
+	n, err := strconv.ParseUint(seq, 10, 64)
+	if !ok || err != nil || hex.DecodedLen(len(mac)) != len(link.MAC) {
+		return link, fmt.Errorf("%s %q: want SEQ:MAC with a 64-digit hex MAC", name, s)
+	}
+	if _, err := hex.Decode(link.MAC[:], []byte(mac)); err != nil {
+		return link, fmt.Errorf("%s %q: %w", name, s, err)
+	}
+	link.Seq = n
+	return link, nil
+}
+
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, "crystal-verify:", err)
+	return 2
+}
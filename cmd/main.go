@@ -50,7 +50,7 @@ func main() {
 	traceLogger := crystal.NewLogger(traceConfig)
 	
 	// Test context logging
-	ctx := context.WithValue(context.Background(), "request_id", "req-123")
+	ctx := core.WithRequestID(context.Background(), "req-123")
 	traceLogger.InfoContext(ctx, "Processing request with context")
 	
 	// Test sensitive data masking
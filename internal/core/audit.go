@@ -0,0 +1,137 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// AuditSchemaVersion identifies the layout of audit records and changes whenever a field is renamed or removed
+// AuditSchemaVersion mengidentifikasi tata letak record audit dan berubah setiap kali field diganti nama atau dihapus
+const AuditSchemaVersion = "1"
+
+// Audit outcomes recorded in AuditEvent.Outcome
+// Hasil audit yang dicatat dalam AuditEvent.Outcome
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditEvent is the fixed schema written for every audit record; every field is always present so consumers can rely on it
+// AuditEvent adalah skema tetap yang ditulis untuk setiap record audit; setiap field selalu ada sehingga konsumen dapat mengandalkannya
+type AuditEvent struct {
+	SchemaVersion string                 `json:"schema_version"` // Set by the logger - Diatur oleh logger
+	Timestamp     time.Time              `json:"timestamp"`      // Always written in UTC - Selalu ditulis dalam UTC
+	EventType     string                 `json:"event_type"`     // Category such as USER_LOGIN - Kategori seperti USER_LOGIN
+	Actor         string                 `json:"actor"`          // Who performed the action - Siapa yang melakukan aksi
+	Action        string                 `json:"action"`         // What was done - Apa yang dilakukan
+	Resource      string                 `json:"resource"`       // What it was done to - Terhadap apa aksi dilakukan
+	Outcome       string                 `json:"outcome"`        // AuditOutcomeSuccess or AuditOutcomeFailure - AuditOutcomeSuccess atau AuditOutcomeFailure
+	SourceIP      string                 `json:"source_ip"`      // Client address - Alamat klien
+	TraceID       string                 `json:"trace_id"`       // Correlation IDs taken from context when empty - ID korelasi yang diambil dari konteks jika kosong
+	SpanID        string                 `json:"span_id"`
+	RequestID     string                 `json:"request_id"`
+	SessionID     string                 `json:"session_id"`
+	Application   string                 `json:"application"` // Set by the logger - Diatur oleh logger
+	Hostname      string                 `json:"hostname"`    // Set by the logger - Diatur oleh logger
+	Details       map[string]interface{} `json:"details"`     // Event-specific data - Data khusus event
+}
+
+// auditSink serializes audit writes to the dedicated audit writer, shared by a logger and its children
+// auditSink menserialisasi penulisan audit ke writer audit khusus, dibagi oleh logger dan turunannya
+type auditSink struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// Audit records a security-relevant event; it matches the README signature and uses no context
+// Audit mencatat event yang relevan dengan keamanan; sesuai dengan signature README dan tidak menggunakan konteks
+func (l *Logger) Audit(eventType, action, resource, userID string, success bool, details map[string]interface{}) error {
+	return l.AuditContext(context.Background(), eventType, action, resource, userID, success, details)
+}
+
+// AuditContext records a security-relevant event, taking the actor, source IP and correlation IDs from ctx when available
+// AuditContext mencatat event yang relevan dengan keamanan, mengambil aktor, IP sumber, dan ID korelasi dari ctx jika tersedia
+func (l *Logger) AuditContext(ctx context.Context, eventType, action, resource, userID string, success bool, details map[string]interface{}) error {
+	outcome := AuditOutcomeFailure
+	if success {
+		outcome = AuditOutcomeSuccess
+	}
+	return l.WriteAudit(ctx, AuditEvent{
+		EventType: eventType,
+		Actor:     userID,
+		Action:    action,
+		Resource:  resource,
+		Outcome:   outcome,
+		Details:   details,
+	})
+}
+
+// WriteAudit writes the event synchronously to the audit writer, bypassing level filtering, sampling, buffering and the async queue
+// WriteAudit menulis event secara sinkron ke writer audit, melewati filter tingkat, sampling, buffering, dan antrian async
+//
+// The writer is synced after each event so records survive a crash; failures are returned and reported to the ErrorHandler.
+// Writer di-sync setelah setiap event agar record bertahan saat crash; kegagalan dikembalikan dan dilaporkan ke ErrorHandler.
+func (l *Logger) WriteAudit(ctx context.Context, event AuditEvent) error {
+	event.SchemaVersion = AuditSchemaVersion
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	event.Timestamp = event.Timestamp.UTC()
+	event.Application = l.config.Application
+	event.Hostname = l.config.Hostname
+	if ctx != nil {
+		// Explicit values win over context so callers can override what middleware stored
+		// Nilai eksplisit mengalahkan konteks agar pemanggil dapat mengganti apa yang disimpan middleware
+		fillFromContext(&event.Actor, ctx, UserIDKey)
+		fillFromContext(&event.SourceIP, ctx, SourceIPKey)
+		fillFromContext(&event.TraceID, ctx, TraceIDKey)
+		fillFromContext(&event.SpanID, ctx, SpanIDKey)
+		fillFromContext(&event.RequestID, ctx, RequestIDKey)
+		fillFromContext(&event.SessionID, ctx, SessionIDKey)
+	}
+	if event.Details == nil {
+		event.Details = map[string]interface{}{}
+	}
+	if l.config.Redactor != nil {
+		event.Details = redactDetails(l.config.Redactor, event.Details)
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return l.auditFailed(err)
+	}
+	data = append(data, '\n')
+	l.audit.mu.Lock()
+	defer l.audit.mu.Unlock()
+	if _, err := l.audit.out.Write(data); err != nil {
+		return l.auditFailed(err)
+	}
+	if err := syncWriter(l.audit.out); err != nil {
+		return l.auditFailed(err)
+	}
+	return nil
+}
+
+// fillFromContext sets *dst from a string context value when dst is empty
+// fillFromContext mengatur *dst dari nilai string konteks jika dst kosong
+func fillFromContext(dst *string, ctx context.Context, key contextKey) {
+	if *dst != "" {
+		return
+	}
+	if v, ok := ctx.Value(key).(string); ok {
+		*dst = v
+	}
+}
+
+// auditFailed reports an audit write failure to the error handler and returns it wrapped
+// auditFailed melaporkan kegagalan penulisan audit ke handler kesalahan dan mengembalikannya dalam bentuk terbungkus
+func (l *Logger) auditFailed(err error) error {
+	err = fmt.Errorf("failed to write audit event: %w", err)
+	if l.errorHandler != nil {
+		l.errorHandler(err)
+	}
+	return err
+}
@@ -0,0 +1,293 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// binaryEncoder appends values in a self-describing binary format such as MessagePack or CBOR
+// binaryEncoder menambahkan nilai dalam format biner yang mendeskripsikan dirinya sendiri seperti MessagePack atau CBOR
+type binaryEncoder interface {
+	appendNil(b []byte) []byte
+	appendBool(b []byte, v bool) []byte
+	appendInt(b []byte, v int64) []byte
+	appendUint(b []byte, v uint64) []byte
+	appendFloat32(b []byte, v float32) []byte
+	appendFloat64(b []byte, v float64) []byte
+	appendString(b []byte, s string) []byte
+	appendBytes(b []byte, v []byte) []byte
+	appendTime(b []byte, t time.Time) []byte
+	appendArrayHeader(b []byte, n int) []byte
+	appendMapHeader(b []byte, n int) []byte
+	// beginMap reserves a header for a map whose size is not known yet; endMap writes the final size
+	// beginMap menyediakan header untuk map yang ukurannya belum diketahui; endMap menulis ukuran akhirnya
+	beginMap(b []byte) ([]byte, int)
+	endMap(b []byte, pos, n int) []byte
+}
+
+// appendBinaryEntry encodes the whole entry as one map using the same keys as JSONFormatter
+// appendBinaryEntry mengkodekan seluruh entri sebagai satu map menggunakan key yang sama dengan JSONFormatter
+func appendBinaryEntry(enc binaryEncoder, b []byte, logEntry LogEntryInterface) []byte {
+	b, pos := enc.beginMap(b)
+	n := 4
+	b = enc.appendString(b, jsonKeyTimestamp)
+	b = enc.appendTime(b, logEntry.GetTimestamp())
+	b = enc.appendString(b, jsonKeyLevel)
+	b = enc.appendString(b, logEntry.GetLevel().String())
+	b = enc.appendString(b, jsonKeyMessage)
+	b = enc.appendString(b, logEntry.GetMessage())
+	b = enc.appendString(b, jsonKeyPID)
+	b = enc.appendInt(b, int64(logEntry.GetPID()))
+	if file := logEntry.GetCallerFile(); file != "" {
+		b = enc.appendString(b, jsonKeyCaller)
+		b = enc.appendMapHeader(b, 2)
+		b = enc.appendString(b, "file")
+		b = enc.appendString(b, file)
+		b = enc.appendString(b, "line")
+		b = enc.appendInt(b, int64(logEntry.GetCallerLine()))
+		n++
+	}
+	for _, kv := range [...][2]string{
+		{jsonKeyGoroutineID, logEntry.GetGoroutineID()},
+		{jsonKeyTraceID, logEntry.GetTraceID()},
+		{jsonKeySpanID, logEntry.GetSpanID()},
+		{jsonKeyUserID, logEntry.GetUserID()},
+		{jsonKeySessionID, logEntry.GetSessionID()},
+		{jsonKeyRequestID, logEntry.GetRequestID()},
+	} {
+		if kv[1] != "" {
+			b = enc.appendString(b, kv[0])
+			b = enc.appendString(b, kv[1])
+			n++
+		}
+	}
+	// Duration is stored as integer nanoseconds so it converts back to time.Duration exactly
+	// Durasi disimpan sebagai nanodetik integer agar dapat dikonversi kembali ke time.Duration secara tepat
+	if d := logEntry.GetDuration(); d > 0 {
+		b = enc.appendString(b, jsonKeyDuration)
+		b = enc.appendInt(b, int64(d))
+		n++
+	}
+	for _, kv := range [...][2]string{
+		{jsonKeyHostname, logEntry.GetHostname()},
+		{jsonKeyApplication, logEntry.GetApplication()},
+		{jsonKeyVersion, logEntry.GetVersion()},
+		{jsonKeyEnvironment, logEntry.GetEnvironment()},
+	} {
+		if kv[1] != "" {
+			b = enc.appendString(b, kv[0])
+			b = enc.appendString(b, kv[1])
+			n++
+		}
+	}
+	if fields := entryFieldPairs(logEntry); len(fields) > 0 {
+		count := 0
+		for i := range fields {
+			if !fieldOverridden(fields, i) {
+				count++
+			}
+		}
+		b = enc.appendString(b, jsonKeyFields)
+		b = enc.appendMapHeader(b, count)
+		for i := range fields {
+			if fieldOverridden(fields, i) {
+				continue
+			}
+			b = enc.appendString(b, bToString(fields[i].Key[:fields[i].KeyLen]))
+			b = appendBinaryFieldValue(enc, b, &fields[i])
+		}
+		n++
+	}
+	if tags := entryTags(logEntry); len(tags) > 0 {
+		b = enc.appendString(b, jsonKeyTags)
+		b = enc.appendArrayHeader(b, len(tags))
+		for _, tag := range tags {
+			b = enc.appendString(b, tag)
+		}
+		n++
+	}
+	if metrics := entryMetricPairs(logEntry); len(metrics) > 0 {
+		b = enc.appendString(b, jsonKeyCustomMetrics)
+		b = enc.appendMapHeader(b, len(metrics))
+		for i := range metrics {
+			b = enc.appendString(b, bToString(metrics[i].Key[:metrics[i].KeyLen]))
+			b = enc.appendFloat64(b, metrics[i].Value)
+		}
+		n++
+	}
+	if err := logEntry.GetError(); err != nil {
+		b = enc.appendString(b, jsonKeyError)
+		b = enc.appendString(b, err.Error())
+		b = enc.appendString(b, jsonKeyErrorType)
+		b = enc.appendString(b, errorTypeName(err))
+		n += 2
+		if chain := errorChain(err); len(chain) > 0 {
+			b = enc.appendString(b, jsonKeyErrorChain)
+			b = enc.appendArrayHeader(b, len(chain))
+			for _, wrapped := range chain {
+				b = enc.appendMapHeader(b, 2)
+				b = enc.appendString(b, "type")
+				b = enc.appendString(b, errorTypeName(wrapped))
+				b = enc.appendString(b, "message")
+				b = enc.appendString(b, wrapped.Error())
+			}
+			n++
+		}
+	}
+	if stack := logEntry.GetStackTrace(); stack != "" {
+		b = enc.appendString(b, jsonKeyStackTrace)
+		b = enc.appendString(b, stack)
+		n++
+	}
+	return enc.endMap(b, pos, n)
+}
+
+// appendBinaryFieldValue appends a field value, keeping the type of the typed zero-allocation slots
+// appendBinaryFieldValue menambahkan nilai field, mempertahankan tipe dari slot bertipe zero-allocation
+func appendBinaryFieldValue(enc binaryEncoder, b []byte, fp *FieldPair) []byte {
+	switch {
+	case fp.IsString:
+		return enc.appendString(b, bToString(fp.StringValue[:fp.StringValueLen]))
+	case fp.IsInt:
+		return enc.appendInt(b, fp.IntValue)
+	case fp.IsFloat64:
+		return enc.appendFloat64(b, fp.Float64Value)
+	case fp.IsBool:
+		return enc.appendBool(b, fp.BoolValue)
+	}
+	return appendBinaryValue(enc, b, fp.Value)
+}
+
+// appendBinaryValue appends common value types directly and converts anything else through encoding/json first
+// appendBinaryValue menambahkan tipe nilai umum secara langsung dan mengonversi sisanya melalui encoding/json terlebih dahulu
+func appendBinaryValue(enc binaryEncoder, b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return enc.appendNil(b)
+	case string:
+		return enc.appendString(b, v)
+	case []byte:
+		return enc.appendBytes(b, v)
+	case bool:
+		return enc.appendBool(b, v)
+	case int:
+		return enc.appendInt(b, int64(v))
+	case int8:
+		return enc.appendInt(b, int64(v))
+	case int16:
+		return enc.appendInt(b, int64(v))
+	case int32:
+		return enc.appendInt(b, int64(v))
+	case int64:
+		return enc.appendInt(b, v)
+	case uint:
+		return enc.appendUint(b, uint64(v))
+	case uint8:
+		return enc.appendUint(b, uint64(v))
+	case uint16:
+		return enc.appendUint(b, uint64(v))
+	case uint32:
+		return enc.appendUint(b, uint64(v))
+	case uint64:
+		return enc.appendUint(b, v)
+	case float32:
+		return enc.appendFloat32(b, v)
+	case float64:
+		return enc.appendFloat64(b, v)
+	case time.Duration:
+		return enc.appendInt(b, int64(v))
+	case time.Time:
+		return enc.appendTime(b, v)
+	case error:
+		return enc.appendString(b, v.Error())
+	case []string:
+		b = enc.appendArrayHeader(b, len(v))
+		for _, s := range v {
+			b = enc.appendString(b, s)
+		}
+		return b
+	case []interface{}:
+		b = enc.appendArrayHeader(b, len(v))
+		for _, item := range v {
+			b = appendBinaryValue(enc, b, item)
+		}
+		return b
+	case map[string]string:
+		// Keys are sorted like encoding/json so equal maps encode identically
+		// Key diurutkan seperti encoding/json agar map yang sama dikodekan secara identik
+		b = enc.appendMapHeader(b, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			b = enc.appendString(b, k)
+			b = enc.appendString(b, v[k])
+		}
+		return b
+	case map[string]interface{}:
+		b = enc.appendMapHeader(b, len(v))
+		for _, k := range slices.Sorted(maps.Keys(v)) {
+			b = enc.appendString(b, k)
+			b = appendBinaryValue(enc, b, v[k])
+		}
+		return b
+	case fmt.Stringer:
+		return enc.appendString(b, v.String())
+	}
+	// Structs and other types keep their JSON shape
+	// Struct dan tipe lainnya mempertahankan bentuk JSON-nya
+	data, err := json.Marshal(value)
+	if err != nil {
+		return enc.appendString(b, "!ERROR: "+err.Error())
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return enc.appendString(b, "!ERROR: "+err.Error())
+	}
+	return appendBinaryValue(enc, b, generic)
+}
+
+// formatBinaryEntry encodes the entry into a pooled buffer and returns a copy
+// formatBinaryEntry mengkodekan entri ke buffer dari pool dan mengembalikan salinannya
+func formatBinaryEntry(enc binaryEncoder, entry interface{}) ([]byte, error) {
+	// Cast entry to LogEntryInterface
+	logEntry, ok := entry.(LogEntryInterface)
+	if !ok {
+		return nil, fmt.Errorf("invalid entry type")
+	}
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	b := appendBinaryEntry(enc, buf.buf[:0], logEntry)
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, len(b))
+	copy(result, b)
+	return result, nil
+}
+
+// maxBinaryLength bounds lengths read by the decoders so corrupt input cannot trigger huge allocations
+// maxBinaryLength membatasi panjang yang dibaca decoder agar input rusak tidak memicu alokasi besar
+const maxBinaryLength = 64 << 20
+
+// maxBinaryDepth bounds nesting read by the decoders
+// maxBinaryDepth membatasi kedalaman bersarang yang dibaca decoder
+const maxBinaryDepth = 64
+
+// binaryRecord converts a decoded top-level value into a record map
+// binaryRecord mengonversi nilai tingkat atas yang didekode menjadi map record
+func binaryRecord(value interface{}, format string) (map[string]interface{}, error) {
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a map record, got %T", format, value)
+	}
+	return record, nil
+}
+
+// binaryMapKey converts a decoded map key to a string
+// binaryMapKey mengonversi key map yang didekode menjadi string
+func binaryMapKey(key interface{}) string {
+	if s, ok := key.(string); ok {
+		return s
+	}
+	return fmt.Sprint(key)
+}
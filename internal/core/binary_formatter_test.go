@@ -0,0 +1,198 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type binaryDecoder interface {
+	Decode() (map[string]interface{}, error)
+}
+
+func TestBinaryEncodersKnownBytes(t *testing.T) {
+	ts := time.Unix(1714564800, 0)
+	tests := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"msgpack fixint", msgpackEncoder{}.appendInt(nil, -32), []byte{0xe0}},
+		{"msgpack int8", msgpackEncoder{}.appendInt(nil, -33), []byte{0xd0, 0xdf}},
+		{"msgpack uint16", msgpackEncoder{}.appendInt(nil, 1000), []byte{0xcd, 0x03, 0xe8}},
+		{"msgpack fixstr", msgpackEncoder{}.appendString(nil, "hi"), []byte{0xa2, 'h', 'i'}},
+		{"msgpack timestamp32", msgpackEncoder{}.appendTime(nil, ts), []byte{0xd6, 0xff, 0x66, 0x32, 0x2e, 0xc0}},
+		{"cbor negative", cborEncoder{}.appendInt(nil, -500), []byte{0x39, 0x01, 0xf3}},
+		{"cbor text", cborEncoder{}.appendString(nil, "hi"), []byte{0x62, 'h', 'i'}},
+		{"cbor extended time", cborEncoder{}.appendTime(nil, ts), []byte{0xd9, 0x03, 0xe9, 0xa1, 0x01, 0x1a, 0x66, 0x32, 0x2e, 0xc0}},
+	}
+	for _, tt := range tests {
+		if !bytes.Equal(tt.got, tt.want) {
+			t.Errorf("%s: got % x, want % x", tt.name, tt.got, tt.want)
+		}
+	}
+
+	// Reserved map headers shrink to the shortest form
+	for _, enc := range []binaryEncoder{msgpackEncoder{}, cborEncoder{}} {
+		b, pos := enc.beginMap([]byte{0x00})
+		b = enc.appendString(b, "k")
+		b = enc.appendBool(b, true)
+		b = enc.endMap(b, pos, 1)
+		if len(b) != 5 || b[0] != 0x00 || (b[1] != 0x81 && b[1] != 0xa1) {
+			t.Errorf("%T: unexpected map encoding % x", enc, b)
+		}
+	}
+}
+
+func TestBinaryFormattersRoundTrip(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter Formatter
+		decoder   func(io.Reader) binaryDecoder
+	}{
+		{"msgpack", NewMsgpackFormatter(), func(r io.Reader) binaryDecoder { return NewMsgpackDecoder(r) }},
+		{"cbor", NewCBORFormatter(), func(r io.Reader) binaryDecoder { return NewCBORDecoder(r) }},
+	}
+	entry := newTestEntry(ERROR, "payment failed", "order", "o-1", "amount", -1250, "ratio", 0.25, "retried", true,
+		"items", []string{"a", "b"}, "raw", []byte{0, 1})
+	entry.Timestamp = testEntryTime.Add(123_456_789)
+	entry.PID = 42
+	entry.Duration = 1500 * time.Millisecond
+	entry.Error = fmt.Errorf("charge card: %w", errors.New("declined"))
+	entry.TraceIDLen = copy(entry.TraceID[:], "abc123")
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "pay.go")
+	entry.Caller.Line = 12
+	entry.SetTag("billing")
+	entry.SetMetric("latency_ms", 12.5)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output, err := tt.formatter.Format(entry)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			record, err := tt.decoder(bytes.NewReader(output)).Decode()
+			if err != nil {
+				t.Fatalf("Unexpected decode error: %v", err)
+			}
+
+			ts, ok := record["timestamp"].(time.Time)
+			if !ok || !ts.Equal(entry.Timestamp) {
+				t.Errorf("Unexpected timestamp %#v", record["timestamp"])
+			}
+			if record["level"] != "ERROR" || record["message"] != "payment failed" || record["pid"] != int64(42) {
+				t.Errorf("Unexpected header values: %v %v %v", record["level"], record["message"], record["pid"])
+			}
+			if record["duration"] != int64(1500*time.Millisecond) || record["trace_id"] != "abc123" {
+				t.Errorf("Unexpected duration or trace ID: %v %v", record["duration"], record["trace_id"])
+			}
+			caller := map[string]interface{}{"file": "pay.go", "line": int64(12)}
+			if !reflect.DeepEqual(record["caller"], caller) {
+				t.Errorf("Unexpected caller %#v", record["caller"])
+			}
+			fields := map[string]interface{}{
+				"order":   "o-1",
+				"amount":  int64(-1250),
+				"ratio":   0.25,
+				"retried": true,
+				"items":   []interface{}{"a", "b"},
+				"raw":     []byte{0, 1},
+			}
+			if !reflect.DeepEqual(record["fields"], fields) {
+				t.Errorf("Unexpected fields %#v", record["fields"])
+			}
+			if !reflect.DeepEqual(record["tags"], []interface{}{"billing"}) {
+				t.Errorf("Unexpected tags %#v", record["tags"])
+			}
+			if !reflect.DeepEqual(record["custom_metrics"], map[string]interface{}{"latency_ms": 12.5}) {
+				t.Errorf("Unexpected metrics %#v", record["custom_metrics"])
+			}
+			if record["error"] != "charge card: declined" || record["error.type"] != "*fmt.wrapError" {
+				t.Errorf("Unexpected error values: %v %v", record["error"], record["error.type"])
+			}
+			chain := []interface{}{
+				map[string]interface{}{"type": "*errors.errorString", "message": "declined"},
+			}
+			if !reflect.DeepEqual(record["error.chain"], chain) {
+				t.Errorf("Unexpected error chain %#v", record["error.chain"])
+			}
+		})
+	}
+}
+
+func TestBinaryDecodersStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		formatter Formatter
+		decoder   func(io.Reader) binaryDecoder
+	}{
+		{"msgpack", NewMsgpackFormatter(), func(r io.Reader) binaryDecoder { return NewMsgpackDecoder(r) }},
+		{"cbor", NewCBORFormatter(), func(r io.Reader) binaryDecoder { return NewCBORDecoder(r) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stream bytes.Buffer
+			for _, msg := range []string{"first", "second", "third"} {
+				output, err := tt.formatter.Format(newTestEntry(INFO, msg, "order", "o-1"))
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				stream.Write(output)
+			}
+			data := stream.Bytes()
+
+			decoder := tt.decoder(bytes.NewReader(data))
+			for _, want := range []string{"first", "second", "third"} {
+				record, err := decoder.Decode()
+				if err != nil {
+					t.Fatalf("Unexpected decode error: %v", err)
+				}
+				if record["message"] != want {
+					t.Errorf("Expected message %q, got %v", want, record["message"])
+				}
+			}
+			if _, err := decoder.Decode(); err != io.EOF {
+				t.Errorf("Expected io.EOF at the end of the stream, got %v", err)
+			}
+
+			truncated := tt.decoder(bytes.NewReader(data[:len(data)-3]))
+			truncated.Decode()
+			truncated.Decode()
+			if _, err := truncated.Decode(); err != io.ErrUnexpectedEOF {
+				t.Errorf("Expected io.ErrUnexpectedEOF for a truncated record, got %v", err)
+			}
+		})
+	}
+}
+
+func TestCBORDecoderIndefiniteAndTags(t *testing.T) {
+	// {_ "a": (_ "x", "y"), "t": 1(1714564800), "h": 1.5 as half float, "u": undefined}
+	data := []byte{
+		0xbf,
+		0x61, 'a', 0x7f, 0x61, 'x', 0x61, 'y', 0xff,
+		0x61, 't', 0xc1, 0x1a, 0x66, 0x32, 0x2e, 0xc0,
+		0x61, 'h', 0xf9, 0x3e, 0x00,
+		0x61, 'u', 0xf7,
+		0xff,
+	}
+	record, err := NewCBORDecoder(bytes.NewReader(data)).Decode()
+	if err != nil {
+		t.Fatalf("Unexpected decode error: %v", err)
+	}
+	if record["a"] != "xy" {
+		t.Errorf("Expected joined string, got %#v", record["a"])
+	}
+	if ts, ok := record["t"].(time.Time); !ok || ts.Unix() != 1714564800 {
+		t.Errorf("Expected epoch time, got %#v", record["t"])
+	}
+	if record["h"] != 1.5 {
+		t.Errorf("Expected half float 1.5, got %#v", record["h"])
+	}
+	if v, ok := record["u"]; !ok || v != nil {
+		t.Errorf("Expected undefined to decode as nil, got %#v", v)
+	}
+}
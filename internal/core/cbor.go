@@ -0,0 +1,417 @@
+package core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// CBOR major types
+// Tipe mayor CBOR
+const (
+	cborUint   = 0 << 5
+	cborNegint = 1 << 5
+	cborBytes  = 2 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborTag    = 6 << 5
+	cborSimple = 7 << 5
+)
+
+// CBOR tags used for timestamps
+// Tag CBOR yang digunakan untuk timestamp
+const (
+	cborTagDateString   = 0    // RFC 3339 text - Teks RFC 3339
+	cborTagEpoch        = 1    // Seconds since the epoch - Detik sejak epoch
+	cborTagExtendedTime = 1001 // RFC 9581 map with seconds and nanoseconds - Map RFC 9581 dengan detik dan nanodetik
+)
+
+// cborBreak ends an indefinite-length item
+// cborBreak mengakhiri item dengan panjang tidak tentu
+const cborBreak = 0xff
+
+// CBORFormatter writes each log entry as one CBOR map (RFC 8949)
+// CBORFormatter menulis setiap entri log sebagai satu map CBOR (RFC 8949)
+//
+// Timestamps use the RFC 9581 extended time tag 1001 so nanoseconds survive; entries are written back to back,
+// read them with CBORDecoder.
+// Timestamp menggunakan tag waktu diperluas RFC 9581 1001 agar nanodetik tetap terjaga; entri ditulis berurutan,
+// baca dengan CBORDecoder.
+type CBORFormatter struct{}
+
+// NewCBORFormatter creates a new CBORFormatter
+// NewCBORFormatter membuat CBORFormatter baru
+func NewCBORFormatter() *CBORFormatter {
+	return &CBORFormatter{}
+}
+
+// Format encodes a log entry as CBOR
+// Format mengkodekan entri log sebagai CBOR
+func (f *CBORFormatter) Format(entry interface{}) ([]byte, error) {
+	return formatBinaryEntry(cborEncoder{}, entry)
+}
+
+// cborEncoder implements binaryEncoder for CBOR using the preferred (shortest) serialization of heads
+// cborEncoder mengimplementasikan binaryEncoder untuk CBOR menggunakan serialisasi head yang disarankan (terpendek)
+type cborEncoder struct{}
+
+// appendCBORHead appends the initial byte and argument of an item
+// appendCBORHead menambahkan byte awal dan argumen dari sebuah item
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+func (cborEncoder) appendNil(b []byte) []byte {
+	return append(b, cborSimple|22)
+}
+
+func (cborEncoder) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, cborSimple|21)
+	}
+	return append(b, cborSimple|20)
+}
+
+func (cborEncoder) appendInt(b []byte, v int64) []byte {
+	if v >= 0 {
+		return appendCBORHead(b, cborUint, uint64(v))
+	}
+	return appendCBORHead(b, cborNegint, uint64(-1-v))
+}
+
+func (cborEncoder) appendUint(b []byte, v uint64) []byte {
+	return appendCBORHead(b, cborUint, v)
+}
+
+func (cborEncoder) appendFloat32(b []byte, v float32) []byte {
+	return binary.BigEndian.AppendUint32(append(b, cborSimple|26), math.Float32bits(v))
+}
+
+func (cborEncoder) appendFloat64(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, cborSimple|27), math.Float64bits(v))
+}
+
+func (cborEncoder) appendString(b []byte, s string) []byte {
+	return append(appendCBORHead(b, cborText, uint64(len(s))), s...)
+}
+
+func (cborEncoder) appendBytes(b []byte, v []byte) []byte {
+	return append(appendCBORHead(b, cborBytes, uint64(len(v))), v...)
+}
+
+// appendTime writes tag 1001 with key 1 for seconds and key -9 for nanoseconds when they are not zero
+// appendTime menulis tag 1001 dengan key 1 untuk detik dan key -9 untuk nanodetik jika tidak nol
+func (e cborEncoder) appendTime(b []byte, t time.Time) []byte {
+	b = appendCBORHead(b, cborTag, cborTagExtendedTime)
+	nsec := t.Nanosecond()
+	if nsec == 0 {
+		b = append(b, cborMap|1)
+	} else {
+		b = append(b, cborMap|2)
+	}
+	b = e.appendInt(b, 1)
+	b = e.appendInt(b, t.Unix())
+	if nsec != 0 {
+		b = e.appendInt(b, -9)
+		b = e.appendInt(b, int64(nsec))
+	}
+	return b
+}
+
+func (cborEncoder) appendArrayHeader(b []byte, n int) []byte {
+	return appendCBORHead(b, cborArray, uint64(n))
+}
+
+func (cborEncoder) appendMapHeader(b []byte, n int) []byte {
+	return appendCBORHead(b, cborMap, uint64(n))
+}
+
+func (cborEncoder) beginMap(b []byte) ([]byte, int) {
+	return append(b, cborMap|26, 0, 0, 0, 0), len(b)
+}
+
+// endMap shrinks the reserved 4-byte map head to the shortest form once the size is known
+// endMap mengecilkan head map 4 byte yang disediakan ke bentuk terpendek setelah ukurannya diketahui
+func (cborEncoder) endMap(b []byte, pos, n int) []byte {
+	var head [5]byte
+	h := appendCBORHead(head[:0], cborMap, uint64(n))
+	shift := 5 - len(h)
+	copy(b[pos:], h)
+	copy(b[pos+len(h):], b[pos+5:])
+	return b[:len(b)-shift]
+}
+
+// CBORDecoder reads a stream of CBOR log records written by CBORFormatter
+// CBORDecoder membaca aliran record log CBOR yang ditulis oleh CBORFormatter
+//
+// Values decode like MsgpackDecoder; tags 0, 1 and 1001 decode to time.Time and other tags to their content.
+// Nilai didekode seperti MsgpackDecoder; tag 0, 1, dan 1001 didekode menjadi time.Time dan tag lain menjadi isinya.
+type CBORDecoder struct {
+	r *bufio.Reader
+}
+
+// errCBORBreak signals a break byte where an item was expected
+// errCBORBreak menandakan byte break di tempat yang seharusnya berisi item
+var errCBORBreak = errors.New("cbor: unexpected break")
+
+// NewCBORDecoder creates a decoder reading from r
+// NewCBORDecoder membuat decoder yang membaca dari r
+func NewCBORDecoder(r io.Reader) *CBORDecoder {
+	return &CBORDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next record, returning io.EOF when the stream ends cleanly between records
+// Decode membaca record berikutnya, mengembalikan io.EOF jika aliran berakhir dengan bersih di antara record
+func (d *CBORDecoder) Decode() (map[string]interface{}, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	value, err := d.decodeValue(0)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return binaryRecord(value, "cbor")
+}
+
+// readArgument reads the argument encoded by the additional information bits; indefinite reports length 31
+// readArgument membaca argumen yang dikodekan oleh bit informasi tambahan; indefinite melaporkan panjang 31
+func (d *CBORDecoder) readArgument(info byte) (n uint64, indefinite bool, err error) {
+	switch {
+	case info < 24:
+		return uint64(info), false, nil
+	case info <= 27:
+		var buf [8]byte
+		size := 1 << (info - 24)
+		if _, err := io.ReadFull(d.r, buf[:size]); err != nil {
+			return 0, false, err
+		}
+		for _, c := range buf[:size] {
+			n = n<<8 | uint64(c)
+		}
+		return n, false, nil
+	case info == 31:
+		return 0, true, nil
+	}
+	return 0, false, fmt.Errorf("cbor: invalid additional information %d", info)
+}
+
+// decodeValue reads one data item
+// decodeValue membaca satu item data
+func (d *CBORDecoder) decodeValue(depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("cbor: nesting too deep")
+	}
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	major, info := c&0xe0, c&0x1f
+	if major == cborSimple {
+		return d.decodeSimple(c, info)
+	}
+	n, indefinite, err := d.readArgument(info)
+	if err != nil {
+		return nil, err
+	}
+	if indefinite && (major == cborUint || major == cborNegint || major == cborTag) {
+		return nil, fmt.Errorf("cbor: invalid indefinite length for major type %d", major>>5)
+	}
+	switch major {
+	case cborUint:
+		if n > math.MaxInt64 {
+			return n, nil
+		}
+		return int64(n), nil
+	case cborNegint:
+		if n > math.MaxInt64 {
+			return nil, errors.New("cbor: negative integer overflows int64")
+		}
+		return -1 - int64(n), nil
+	case cborBytes, cborText:
+		data, err := d.readString(major, n, indefinite)
+		if err != nil || major == cborBytes {
+			return data, err
+		}
+		return string(data), nil
+	case cborArray:
+		items := make([]interface{}, 0, min(n, 1024))
+		for i := uint64(0); indefinite || i < n; i++ {
+			item, err := d.decodeValue(depth + 1)
+			if indefinite && err == errCBORBreak {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case cborMap:
+		m := make(map[string]interface{}, min(n, 1024))
+		for i := uint64(0); indefinite || i < n; i++ {
+			key, err := d.decodeValue(depth + 1)
+			if indefinite && err == errCBORBreak {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.decodeValue(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			m[binaryMapKey(key)] = value
+		}
+		return m, nil
+	}
+	content, err := d.decodeValue(depth + 1)
+	if err != nil {
+		return nil, err
+	}
+	return cborTagged(n, content)
+}
+
+// readString reads a byte or text string, joining the chunks of an indefinite-length string
+// readString membaca string byte atau teks, menggabungkan potongan dari string dengan panjang tidak tentu
+func (d *CBORDecoder) readString(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if n > maxBinaryLength {
+			return nil, fmt.Errorf("cbor: length %d exceeds limit", n)
+		}
+		buf := make([]byte, n)
+		_, err := io.ReadFull(d.r, buf)
+		return buf, err
+	}
+	var out []byte
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == cborBreak {
+			return out, nil
+		}
+		if c&0xe0 != major {
+			return nil, errors.New("cbor: invalid chunk in indefinite-length string")
+		}
+		size, indefiniteChunk, err := d.readArgument(c & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+		if indefiniteChunk || uint64(len(out))+size > maxBinaryLength {
+			return nil, errors.New("cbor: invalid chunk in indefinite-length string")
+		}
+		chunk, err := d.readString(major, size, false)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, chunk...)
+	}
+}
+
+// decodeSimple decodes major type 7: booleans, null, undefined, floats and break
+// decodeSimple mendekode tipe mayor 7: boolean, null, undefined, float, dan break
+func (d *CBORDecoder) decodeSimple(c, info byte) (interface{}, error) {
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		var buf [2]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return nil, err
+		}
+		return halfToFloat64(binary.BigEndian.Uint16(buf[:])), nil
+	case 26:
+		var buf [4]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return nil, err
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf[:]))), nil
+	case 27:
+		var buf [8]byte
+		if _, err := io.ReadFull(d.r, buf[:]); err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(buf[:])), nil
+	case 31:
+		return nil, errCBORBreak
+	}
+	return nil, fmt.Errorf("cbor: unsupported simple value 0x%02x", c)
+}
+
+// halfToFloat64 converts an IEEE 754 half-precision float
+// halfToFloat64 mengonversi float presisi setengah IEEE 754
+func halfToFloat64(h uint16) float64 {
+	exp := int(h>>10) & 0x1f
+	mant := float64(h & 0x3ff)
+	var v float64
+	switch exp {
+	case 0:
+		v = math.Ldexp(mant, -24)
+	case 31:
+		if mant == 0 {
+			v = math.Inf(1)
+		} else {
+			v = math.NaN()
+		}
+	default:
+		v = math.Ldexp(mant+1024, exp-25)
+	}
+	if h&0x8000 != 0 {
+		return -v
+	}
+	return v
+}
+
+// cborTagged interprets the timestamp tags and returns the content of any other tag unchanged
+// cborTagged menafsirkan tag timestamp dan mengembalikan isi tag lain tanpa perubahan
+func cborTagged(tag uint64, content interface{}) (interface{}, error) {
+	switch tag {
+	case cborTagDateString:
+		s, ok := content.(string)
+		if !ok {
+			return nil, errors.New("cbor: tag 0 requires a text string")
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case cborTagEpoch:
+		switch v := content.(type) {
+		case int64:
+			return time.Unix(v, 0), nil
+		case float64:
+			sec, frac := math.Modf(v)
+			return time.Unix(int64(sec), int64(frac*1e9)), nil
+		}
+		return nil, errors.New("cbor: tag 1 requires a number")
+	case cborTagExtendedTime:
+		m, ok := content.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("cbor: tag 1001 requires a map")
+		}
+		sec, ok := m["1"].(int64)
+		if !ok {
+			return nil, errors.New("cbor: tag 1001 requires integer seconds")
+		}
+		nsec, _ := m["-9"].(int64)
+		return time.Unix(sec, nsec), nil
+	}
+	return content, nil
+}
@@ -0,0 +1,484 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// ConsoleColorMode controls when ConsoleFormatter writes ANSI colors
+// ConsoleColorMode mengontrol kapan ConsoleFormatter menulis warna ANSI
+type ConsoleColorMode uint8
+
+const (
+	// ConsoleColorAuto colors output only when Output, or else the logger's output, is a terminal and NO_COLOR is not set
+	// ConsoleColorAuto mewarnai output hanya jika Output, atau output logger, adalah terminal dan NO_COLOR tidak diatur
+	ConsoleColorAuto ConsoleColorMode = iota
+	// ConsoleColorAlways always writes colors
+	// ConsoleColorAlways selalu menulis warna
+	ConsoleColorAlways
+	// ConsoleColorNever never writes colors
+	// ConsoleColorNever tidak pernah menulis warna
+	ConsoleColorNever
+)
+
+// consoleDefaultCallerWidth is the caller column width used when CallerWidth is zero
+// consoleDefaultCallerWidth adalah lebar kolom pemanggil yang digunakan jika CallerWidth nol
+const consoleDefaultCallerWidth = 24
+
+// consoleMinWrapWidth is the narrowest message column worth wrapping into
+// consoleMinWrapWidth adalah kolom pesan tersempit yang masih layak untuk dibungkus
+const consoleMinWrapWidth = 20
+
+// processStart is the reference for relative timestamps when ConsoleFormatter.Start is zero
+// processStart adalah acuan timestamp relatif jika ConsoleFormatter.Start nol
+var processStart = time.Now()
+
+// consoleIcons are single-width symbols shown before each level name
+// consoleIcons adalah simbol selebar satu karakter yang ditampilkan sebelum setiap nama level
+var consoleIcons = [...]string{"·", "•", "ℹ", "✦", "⚠", "✖", "☠", "‼"}
+
+// ConsoleFormatter writes entries for humans reading a terminal during development
+// ConsoleFormatter menulis entri untuk manusia yang membaca terminal selama pengembangan
+//
+// Each entry starts with the time since Start, a level icon, an aligned caller column and the message wrapped to
+// the terminal width. Fields follow on their own lines under the message, with maps, structs and slices
+// pretty-printed. Use TextFormatter or a structured formatter in production.
+// Setiap entri diawali waktu sejak Start, ikon level, kolom pemanggil yang sejajar, dan pesan yang dibungkus sesuai
+// lebar terminal. Field mengikuti di barisnya sendiri di bawah pesan, dengan map, struct, dan slice dicetak rapi.
+// Gunakan TextFormatter atau formatter terstruktur di produksi.
+type ConsoleFormatter struct {
+	Colors          ConsoleColorMode // Colors selects when colors are written, by default only on a terminal without NO_COLOR
+	Output          io.Writer        // Output is checked for a terminal in ConsoleColorAuto mode, nil means the logger's output
+	Start           time.Time        // Start is the reference for relative timestamps, zero means process start
+	CallerWidth     int              // CallerWidth is the caller column width, 0 uses 24 and a negative value hides the column
+	Width           int              // Width wraps messages to this many columns, 0 reads COLUMNS and a negative value disables wrapping
+	StackTraceDepth int              // StackTraceDepth limits the number of stack frames rendered, 0 renders all
+
+	output io.Writer // Output of the logger created with this formatter
+	colors bool      // Color setting resolved for the entry being formatted
+	width  int       // Wrap width resolved for the entry being formatted, 0 disables wrapping
+}
+
+// NewConsoleFormatter creates a ConsoleFormatter measuring time from now
+// NewConsoleFormatter membuat ConsoleFormatter yang mengukur waktu mulai sekarang
+func NewConsoleFormatter() *ConsoleFormatter {
+	return &ConsoleFormatter{Start: time.Now()}
+}
+
+// setOutput records the output of the logger the formatter was given to, for terminal detection
+// setOutput mencatat output logger yang menerima formatter ini, untuk deteksi terminal
+func (f *ConsoleFormatter) setOutput(w io.Writer) {
+	f.output = w
+}
+
+// resolve returns the color setting and wrap width from the current options, output and environment
+// resolve mengembalikan pengaturan warna dan lebar pembungkusan dari opsi, output, dan environment saat ini
+//
+// It runs for every entry, so changes to Colors, Output and Width apply from the next entry on.
+// Fungsi ini berjalan untuk setiap entri, sehingga perubahan Colors, Output, dan Width berlaku mulai entri berikutnya.
+func (f *ConsoleFormatter) resolve() (colors bool, width int) {
+	switch f.Colors {
+	case ConsoleColorAlways:
+		colors = true
+	case ConsoleColorAuto:
+		out := f.Output
+		if out == nil {
+			out = f.output
+		}
+		if out == nil {
+			out = os.Stdout
+		}
+		colors = os.Getenv("NO_COLOR") == "" && os.Getenv("TERM") != "dumb" && isTerminal(out)
+	}
+	switch {
+	case f.Width > 0:
+		width = f.Width
+	case f.Width == 0:
+		if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+			width = columns
+		}
+	}
+	return colors, width
+}
+
+// terminalFiles caches isTerminal per file, because a file does not change kind while it is open
+// terminalFiles menyimpan hasil isTerminal per file, karena jenis file tidak berubah selama file terbuka
+var terminalFiles sync.Map
+
+// isTerminal reports whether w is a character device such as a terminal
+// isTerminal melaporkan apakah w adalah perangkat karakter seperti terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	if cached, ok := terminalFiles.Load(file); ok {
+		return cached.(bool)
+	}
+	info, err := file.Stat()
+	terminal := err == nil && info.Mode()&os.ModeCharDevice != 0
+	terminalFiles.Store(file, terminal)
+	return terminal
+}
+
+// Format formats a log entry for the console
+// Format memformat entri log untuk konsol
+func (f *ConsoleFormatter) Format(entry interface{}) ([]byte, error) {
+	// Cast entry to LogEntryInterface
+	logEntry, ok := entry.(LogEntryInterface)
+	if !ok {
+		return nil, fmt.Errorf("invalid entry type")
+	}
+	// Format on a copy holding the resolved settings, so concurrent entries never share them
+	// Format pada salinan yang menyimpan pengaturan terselesaikan, agar entri konkuren tidak pernah berbagi pengaturan
+	view := *f
+	view.colors, view.width = f.resolve()
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	b := view.appendEntry(buf.buf[:0], logEntry)
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, len(b))
+	copy(result, b)
+	return result, nil
+}
+
+// appendEntry writes the header line, the wrapped message and the detail lines
+// appendEntry menulis baris header, pesan yang dibungkus, dan baris detail
+func (f *ConsoleFormatter) appendEntry(b []byte, logEntry LogEntryInterface) []byte {
+	level := logEntry.GetLevel()
+	levelColor := ""
+	if int(level) < len(levelColors) {
+		levelColor = levelColors[level]
+	}
+
+	// Relative timestamp, right-aligned so the columns after it line up
+	// Timestamp relatif, rata kanan agar kolom setelahnya sejajar
+	start := f.Start
+	if start.IsZero() {
+		start = processStart
+	}
+	b = f.color(b, "\033[38;5;245m")
+	var scratch [24]byte
+	b = padConsole(appendRelativeTime(scratch[:0], logEntry.GetTimestamp().Sub(start)), 10, b)
+	b = f.reset(b, "\033[38;5;245m")
+	b = append(b, ' ')
+
+	b = f.color(b, levelColor)
+	if int(level) < len(consoleIcons) {
+		b = append(b, consoleIcons[level]...)
+	} else {
+		b = append(b, '?')
+	}
+	b = append(b, ' ')
+	b = appendPadRight(b, level.String(), 6)
+	b = f.reset(b, levelColor)
+	b = append(b, ' ')
+
+	column := 10 + 1 + 2 + 6 + 1
+	if width := f.callerWidth(); width > 0 {
+		caller := ""
+		if file := logEntry.GetCallerFile(); file != "" {
+			caller = shortCallerPath(file) + ":" + strconv.Itoa(logEntry.GetCallerLine())
+		}
+		b = f.color(b, "\033[38;5;240m")
+		b = appendPadRight(b, truncateConsoleLeft(caller, width), width)
+		b = f.reset(b, "\033[38;5;240m")
+		b = append(b, ' ')
+		column += width + 1
+	}
+
+	b = f.color(b, "\033[1m")
+	b = f.appendWrapped(b, logEntry.GetMessage(), column)
+	b = f.reset(b, "\033[1m")
+	b = append(b, '\n')
+
+	return f.appendDetails(b, logEntry, column)
+}
+
+// appendDetails writes metadata, fields, metrics, the error and the stack trace indented under the message
+// appendDetails menulis metadata, field, metrik, error, dan stack trace yang diindentasi di bawah pesan
+func (f *ConsoleFormatter) appendDetails(b []byte, logEntry LogEntryInterface, indent int) []byte {
+	for _, kv := range [...][2]string{
+		{"trace_id", logEntry.GetTraceID()},
+		{"span_id", logEntry.GetSpanID()},
+		{"request_id", logEntry.GetRequestID()},
+		{"user_id", logEntry.GetUserID()},
+		{"session_id", logEntry.GetSessionID()},
+	} {
+		if kv[1] != "" {
+			b = f.appendDetailKey(b, indent, kv[0], "\033[38;5;141m")
+			b = append(b, kv[1]...)
+			b = append(b, '\n')
+		}
+	}
+	if d := logEntry.GetDuration(); d > 0 {
+		b = f.appendDetailKey(b, indent, "duration", "\033[38;5;75m")
+		b = append(b, d.String()...)
+		b = append(b, '\n')
+	}
+	fields := entryFieldPairs(logEntry)
+	for i := range fields {
+		if fieldOverridden(fields, i) {
+			continue
+		}
+		b = f.appendDetailKey(b, indent, bToString(fields[i].Key[:fields[i].KeyLen]), "\033[38;5;75m")
+		b = appendConsoleFieldValue(b, &fields[i], indent)
+		b = append(b, '\n')
+	}
+	if metrics := entryMetricPairs(logEntry); len(metrics) > 0 {
+		b = f.appendDetailKey(b, indent, "metrics", "\033[38;5;75m")
+		for i := range metrics {
+			if i > 0 {
+				b = append(b, ' ')
+			}
+			b = append(b, metrics[i].Key[:metrics[i].KeyLen]...)
+			b = append(b, '=')
+			b = strconv.AppendFloat(b, metrics[i].Value, 'f', -1, 64)
+		}
+		b = append(b, '\n')
+	}
+	if tags := entryTags(logEntry); len(tags) > 0 {
+		b = f.appendDetailKey(b, indent, "tags", "\033[38;5;172m")
+		b = append(b, strings.Join(tags, ", ")...)
+		b = append(b, '\n')
+	}
+	if err := logEntry.GetError(); err != nil {
+		b = f.appendDetailKey(b, indent, "error", "\033[31m")
+		b = f.color(b, "\033[31m")
+		b = append(b, err.Error()...)
+		b = f.reset(b, "\033[31m")
+		b = append(b, " ("...)
+		b = append(b, errorTypeName(err)...)
+		b = append(b, ")\n"...)
+		for _, wrapped := range errorChain(err) {
+			b = appendConsoleIndent(b, indent+2)
+			b = append(b, "caused by: "...)
+			b = append(b, errorLink(wrapped)...)
+			b = append(b, '\n')
+		}
+	}
+	if stack := logEntry.GetStackTrace(); stack != "" {
+		b = f.appendStack(b, stack, indent)
+	}
+	return b
+}
+
+// appendStack writes one frame per line with trimmed paths, so traces from different machines diff cleanly
+// appendStack menulis satu frame per baris dengan path yang dipangkas, agar trace dari mesin berbeda mudah dibandingkan
+func (f *ConsoleFormatter) appendStack(b []byte, stack string, indent int) []byte {
+	b = appendConsoleIndent(b, indent)
+	b = f.color(b, "\033[38;5;240m")
+	b = append(b, "stack:"...)
+	b = f.reset(b, "\033[38;5;240m")
+	b = append(b, '\n')
+	forEachStackFrame(stack, f.StackTraceDepth, func(function, file string, line int) bool {
+		b = appendConsoleIndent(b, indent+2)
+		b = append(b, "at "...)
+		b = f.color(b, "\033[38;5;250m")
+		b = append(b, function...)
+		b = f.reset(b, "\033[38;5;250m")
+		b = append(b, ' ')
+		b = f.color(b, "\033[38;5;240m")
+		b = append(b, '(')
+		b = append(b, shortCallerPath(file)...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(line), 10)
+		b = append(b, ')')
+		b = f.reset(b, "\033[38;5;240m")
+		b = append(b, '\n')
+		return true
+	})
+	return b
+}
+
+// appendDetailKey indents a detail line and writes its colored "key = " prefix
+// appendDetailKey mengindentasi baris detail dan menulis awalan "key = " yang berwarna
+func (f *ConsoleFormatter) appendDetailKey(b []byte, indent int, key, color string) []byte {
+	b = appendConsoleIndent(b, indent)
+	b = f.color(b, color)
+	b = append(b, key...)
+	b = f.reset(b, color)
+	return append(b, " = "...)
+}
+
+// appendWrapped writes the message, wrapping at spaces to the width and indenting continuation lines to column
+// appendWrapped menulis pesan, membungkus di spasi sesuai lebar dan mengindentasi baris lanjutan ke kolom
+func (f *ConsoleFormatter) appendWrapped(b []byte, message string, column int) []byte {
+	available := 0
+	if f.width > 0 {
+		available = f.width - column
+	}
+	for i, line := range strings.Split(message, "\n") {
+		if i > 0 {
+			b = append(b, '\n')
+			b = appendConsoleIndent(b, column)
+		}
+		if available < consoleMinWrapWidth {
+			b = append(b, line...)
+			continue
+		}
+		used := 0
+		for j, word := range strings.Split(line, " ") {
+			n := utf8.RuneCountInString(word)
+			if j > 0 {
+				if used+1+n > available {
+					b = append(b, '\n')
+					b = appendConsoleIndent(b, column)
+					used = 0
+				} else {
+					b = append(b, ' ')
+					used++
+				}
+			}
+			b = append(b, word...)
+			used += n
+		}
+	}
+	return b
+}
+
+// color starts an ANSI color when colors are enabled
+// color memulai warna ANSI jika warna diaktifkan
+func (f *ConsoleFormatter) color(b []byte, code string) []byte {
+	if f.colors && code != "" {
+		b = append(b, code...)
+	}
+	return b
+}
+
+// reset ends a color started with color
+// reset mengakhiri warna yang dimulai dengan color
+func (f *ConsoleFormatter) reset(b []byte, code string) []byte {
+	if f.colors && code != "" {
+		b = append(b, "\033[0m"...)
+	}
+	return b
+}
+
+// callerWidth returns the caller column width, 0 when the column is hidden
+// callerWidth mengembalikan lebar kolom pemanggil, 0 jika kolom disembunyikan
+func (f *ConsoleFormatter) callerWidth() int {
+	switch {
+	case f.CallerWidth < 0:
+		return 0
+	case f.CallerWidth == 0:
+		return consoleDefaultCallerWidth
+	}
+	return f.CallerWidth
+}
+
+// appendRelativeTime writes d as signed seconds with millisecond precision, such as +12.345s
+// appendRelativeTime menulis d sebagai detik bertanda dengan presisi milidetik, seperti +12.345s
+func appendRelativeTime(b []byte, d time.Duration) []byte {
+	if d < 0 {
+		b = append(b, '-')
+		d = -d
+	} else {
+		b = append(b, '+')
+	}
+	ms := d.Milliseconds()
+	b = strconv.AppendInt(b, ms/1000, 10)
+	b = append(b, '.', byte('0'+ms/100%10), byte('0'+ms/10%10), byte('0'+ms%10), 's')
+	return b
+}
+
+// padConsole appends text right-aligned to width columns
+// padConsole menambahkan teks rata kanan hingga width kolom
+func padConsole(text []byte, width int, b []byte) []byte {
+	for n := utf8.RuneCount(text); n < width; n++ {
+		b = append(b, ' ')
+	}
+	return append(b, text...)
+}
+
+// appendPadRight appends s left-aligned to width columns
+// appendPadRight menambahkan s rata kiri hingga width kolom
+func appendPadRight(b []byte, s string, width int) []byte {
+	b = append(b, s...)
+	for n := utf8.RuneCountInString(s); n < width; n++ {
+		b = append(b, ' ')
+	}
+	return b
+}
+
+// truncateConsoleLeft shortens s to width runes by dropping its start, keeping the file name and line visible
+// truncateConsoleLeft memendekkan s menjadi width rune dengan membuang bagian awalnya, agar nama file dan baris tetap terlihat
+func truncateConsoleLeft(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= width {
+		return s
+	}
+	runes := []rune(s)
+	return "…" + string(runes[n-width+1:])
+}
+
+// appendConsoleIndent appends n spaces
+// appendConsoleIndent menambahkan n spasi
+func appendConsoleIndent(b []byte, n int) []byte {
+	for i := 0; i < n; i++ {
+		b = append(b, ' ')
+	}
+	return b
+}
+
+// appendConsoleFieldValue writes a field value, pretty-printing composite values over indented lines
+// appendConsoleFieldValue menulis nilai field, mencetak rapi nilai komposit dalam baris yang diindentasi
+func appendConsoleFieldValue(b []byte, fp *FieldPair, indent int) []byte {
+	switch {
+	case fp.IsString:
+		return appendConsoleString(b, bToString(fp.StringValue[:fp.StringValueLen]))
+	case fp.IsInt:
+		return strconv.AppendInt(b, fp.IntValue, 10)
+	case fp.IsFloat64:
+		return strconv.AppendFloat(b, fp.Float64Value, 'g', -1, 64)
+	case fp.IsBool:
+		return strconv.AppendBool(b, fp.BoolValue)
+	}
+	switch v := fp.Value.(type) {
+	case nil:
+		return append(b, "nil"...)
+	case string:
+		return appendConsoleString(b, v)
+	case error:
+		return append(b, v.Error()...)
+	case time.Time:
+		return v.AppendFormat(b, time.RFC3339Nano)
+	case time.Duration:
+		return append(b, v.String()...)
+	case fmt.Stringer:
+		return append(b, v.String()...)
+	case bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return fmt.Append(b, v)
+	}
+	data, err := json.MarshalIndent(fp.Value, "", "  ")
+	if err != nil {
+		return fmt.Appendf(b, "%+v", fp.Value)
+	}
+	// Continuation lines are indented under the key
+	// Baris lanjutan diindentasi di bawah key
+	for i, line := range strings.Split(string(data), "\n") {
+		if i > 0 {
+			b = append(b, '\n')
+			b = appendConsoleIndent(b, indent)
+		}
+		b = append(b, line...)
+	}
+	return b
+}
+
+// appendConsoleString writes s as is, or quoted when it is empty or contains spaces or control characters
+// appendConsoleString menulis s apa adanya, atau dikutip jika kosong atau mengandung spasi atau karakter kontrol
+func appendConsoleString(b []byte, s string) []byte {
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool { return r <= ' ' || r == '"' || r == 0x7f }) {
+		return strconv.AppendQuote(b, s)
+	}
+	return append(b, s...)
+}
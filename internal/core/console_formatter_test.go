@@ -0,0 +1,153 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsoleFormatterFormat(t *testing.T) {
+	formatter := &ConsoleFormatter{Colors: ConsoleColorNever, Start: testEntryTime, Width: 80}
+	entry := newTestEntry(ERROR, "the upload of the quarterly report failed after several retries",
+		"bucket", "media files", "bytes", 2048, "user", map[string]interface{}{"id": 42, "roles": []string{"admin"}})
+	entry.Timestamp = testEntryTime.Add(1234 * time.Millisecond)
+	entry.Error = fmt.Errorf("upload: %w", errors.New("timeout"))
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "/home/ci/app/storage/disk.go")
+	entry.Caller.Line = 88
+	entry.TraceIDLen = copy(entry.TraceID[:], "abc123")
+	entry.StackTraceLen = copy(entry.StackTrace[:], "main.pay\n\t/app/cmd/pay.go:12\nmain.main\n\t/app/cmd/main.go:5\n")
+	entry.SetMetric("latency_ms", 12.5)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	indent := strings.Repeat(" ", 45)
+	want := "   +1.234s ✖ ERROR  storage/disk.go:88       the upload of the quarterly report\n" +
+		indent + "failed after several retries\n" +
+		indent + "trace_id = abc123\n" +
+		indent + "bucket = \"media files\"\n" +
+		indent + "bytes = 2048\n" +
+		indent + "user = {\n" +
+		indent + "  \"id\": 42,\n" +
+		indent + "  \"roles\": [\n" +
+		indent + "    \"admin\"\n" +
+		indent + "  ]\n" +
+		indent + "}\n" +
+		indent + "metrics = latency_ms=12.5\n" +
+		indent + "error = upload: timeout (*fmt.wrapError)\n" +
+		indent + "  caused by: *errors.errorString: timeout\n" +
+		indent + "stack:\n" +
+		indent + "  at main.pay (cmd/pay.go:12)\n" +
+		indent + "  at main.main (cmd/main.go:5)\n"
+	if string(output) != want {
+		t.Errorf("Unexpected console output\n got: %q\nwant: %q", output, want)
+	}
+}
+
+func TestConsoleFormatterColumnsAndWidth(t *testing.T) {
+	t.Setenv("COLUMNS", "50")
+	formatter := &ConsoleFormatter{Colors: ConsoleColorNever, Start: testEntryTime.Add(time.Second), CallerWidth: -1}
+	entry := newTestEntry(INFO, "one two three four five six seven eight nine ten")
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "   -1.000s ℹ INFO   one two three four five six\n" +
+		strings.Repeat(" ", 20) + "seven eight nine ten\n"
+	if string(output) != want {
+		t.Errorf("Unexpected console output\n got: %q\nwant: %q", output, want)
+	}
+
+	// Long callers keep their tail
+	formatter = &ConsoleFormatter{Colors: ConsoleColorNever, CallerWidth: 12, Width: -1}
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "/app/internal/storage/disk.go")
+	entry.Caller.Line = 7
+	output, _ = formatter.Format(entry)
+	if !strings.Contains(string(output), " …e/disk.go:7 one two") {
+		t.Errorf("Expected a left-truncated caller, got %q", output)
+	}
+}
+
+func TestConsoleFormatterColorDetection(t *testing.T) {
+	entry := newTestEntry(ERROR, "upload failed")
+
+	// Buffers are not terminals
+	formatter := &ConsoleFormatter{Output: &bytes.Buffer{}}
+	output, _ := formatter.Format(entry)
+	if bytes.Contains(output, []byte("\033[")) {
+		t.Errorf("Expected no colors for a non-terminal output, got %q", output)
+	}
+
+	formatter = &ConsoleFormatter{Colors: ConsoleColorAlways}
+	output, _ = formatter.Format(entry)
+	if !bytes.Contains(output, []byte(levelColors[ERROR])) {
+		t.Errorf("Expected level colors with ConsoleColorAlways, got %q", output)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	formatter = &ConsoleFormatter{}
+	if colors, _ := formatter.resolve(); colors {
+		t.Error("Expected NO_COLOR to disable colors")
+	}
+}
+
+func TestConsoleFormatterDetectsLoggerOutput(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	// /dev/null is a character device, so it passes for a terminal
+	device, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	if err != nil {
+		t.Skipf("No %s: %v", os.DevNull, err)
+	}
+	defer device.Close()
+	file, err := os.CreateTemp(t.TempDir(), "console")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	formatter := NewConsoleFormatter()
+	NewLogger(LoggerConfig{Level: INFO, Formatter: formatter, Output: device})
+	if colors, _ := formatter.resolve(); !colors {
+		t.Error("Expected colors for a logger writing to a terminal")
+	}
+
+	formatter = NewConsoleFormatter()
+	NewLogger(LoggerConfig{Level: INFO, Formatter: formatter, Output: file})
+	if colors, _ := formatter.resolve(); colors {
+		t.Error("Expected no colors for a logger writing to a file")
+	}
+
+	// An explicit Output wins over the logger's
+	formatter = &ConsoleFormatter{Output: device}
+	NewLogger(LoggerConfig{Level: INFO, Formatter: formatter, Output: file})
+	if colors, _ := formatter.resolve(); !colors {
+		t.Error("Expected Output to override the logger's output")
+	}
+}
+
+func TestConsoleFormatterAppliesChangedOptions(t *testing.T) {
+	entry := newTestEntry(INFO, "one two three four five six seven eight nine ten")
+	formatter := &ConsoleFormatter{Colors: ConsoleColorNever, Start: testEntryTime, CallerWidth: -1, Width: -1}
+
+	output, _ := formatter.Format(entry)
+	if strings.Count(string(output), "\n") != 1 {
+		t.Errorf("Expected a single line without wrapping, got %q", output)
+	}
+
+	formatter.Width = 50
+	formatter.Colors = ConsoleColorAlways
+	output, _ = formatter.Format(entry)
+	if strings.Count(string(output), "\n") != 2 {
+		t.Errorf("Expected the new Width to wrap the message, got %q", output)
+	}
+	if !bytes.Contains(output, []byte(levelColors[INFO])) {
+		t.Errorf("Expected the new Colors mode to apply, got %q", output)
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// loggerContextKey is the typed key under which NewContext stores a logger
+// loggerContextKey adalah kunci bertipe tempat NewContext menyimpan logger
+const loggerContextKey contextKey = "crystal_logger"
+
+// contextKeyBinding maps a context key to the entry field its value is written to
+// contextKeyBinding memetakan kunci konteks ke field entri tempat nilainya ditulis
+type contextKeyBinding struct {
+	key   interface{} // Key passed to context.Value - Kunci yang diteruskan ke context.Value
+	field string      // Field name in the log entry - Nama field dalam entri log
+}
+
+// Registered context keys are kept as an immutable snapshot so logEntry can read them without locking
+// Kunci konteks yang terdaftar disimpan sebagai snapshot immutable agar logEntry dapat membacanya tanpa penguncian
+var (
+	contextKeysMu sync.Mutex
+	contextKeys   atomic.Pointer[[]contextKeyBinding]
+
+	defaultLoggerOnce sync.Once
+	defaultLogger     *Logger
+)
+
+// RegisterContextKey declares an application context key whose value is added to every context-aware entry under field
+// RegisterContextKey mendeklarasikan kunci konteks aplikasi yang nilainya ditambahkan ke setiap entri berbasis konteks sebagai field
+//
+// Registering the same key again replaces its field name. Keys should be unexported typed values, as with context.WithValue.
+// Mendaftarkan kunci yang sama lagi akan mengganti nama field-nya. Kunci sebaiknya berupa nilai bertipe yang tidak diekspor, seperti pada context.WithValue.
+func RegisterContextKey(key interface{}, field string) {
+	if key == nil || field == "" {
+		return
+	}
+	// Mirror context.WithValue, which rejects keys that cannot be compared
+	// Ikuti context.WithValue, yang menolak kunci yang tidak dapat dibandingkan
+	if !reflect.TypeOf(key).Comparable() {
+		panic("crystal: context key is not comparable")
+	}
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+	var current []contextKeyBinding
+	if p := contextKeys.Load(); p != nil {
+		current = *p
+	}
+	next := make([]contextKeyBinding, 0, len(current)+1)
+	for _, binding := range current {
+		if binding.key != key {
+			next = append(next, binding)
+		}
+	}
+	next = append(next, contextKeyBinding{key: key, field: field})
+	contextKeys.Store(&next)
+}
+
+// UnregisterContextKey removes a key previously declared with RegisterContextKey
+// UnregisterContextKey menghapus kunci yang sebelumnya dideklarasikan dengan RegisterContextKey
+func UnregisterContextKey(key interface{}) {
+	contextKeysMu.Lock()
+	defer contextKeysMu.Unlock()
+	p := contextKeys.Load()
+	if p == nil {
+		return
+	}
+	next := make([]contextKeyBinding, 0, len(*p))
+	for _, binding := range *p {
+		if binding.key != key {
+			next = append(next, binding)
+		}
+	}
+	contextKeys.Store(&next)
+}
+
+// registeredContextKeys returns the current snapshot of registered context keys
+// registeredContextKeys mengembalikan snapshot kunci konteks terdaftar saat ini
+func registeredContextKeys() []contextKeyBinding {
+	if p := contextKeys.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// NewContext returns a copy of ctx carrying the logger, typically a child created with With in request middleware
+// NewContext mengembalikan salinan ctx yang membawa logger, biasanya logger turunan yang dibuat dengan With di middleware permintaan
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, logger)
+}
+
+// FromContext returns the logger stored by NewContext, or a shared default logger when none is present
+// FromContext mengembalikan logger yang disimpan oleh NewContext, atau logger default bersama jika tidak ada
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerContextKey).(*Logger); ok && logger != nil {
+			return logger
+		}
+	}
+	defaultLoggerOnce.Do(func() {
+		defaultLogger = NewDefaultLogger()
+	})
+	return defaultLogger
+}
@@ -0,0 +1,373 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CSVExtraFieldsColumn is the column that packs fields not named by any other column into a JSON object
+// CSVExtraFieldsColumn adalah kolom yang mengemas field yang tidak disebut oleh kolom lain ke dalam objek JSON
+const CSVExtraFieldsColumn = "extra_fields"
+
+// csvFieldColumnPrefix names a user field explicitly, for fields that share a name with a built-in column
+// csvFieldColumnPrefix menyebut field pengguna secara eksplisit, untuk field yang bernama sama dengan kolom bawaan
+const csvFieldColumnPrefix = "fields."
+
+// DefaultCSVColumns is the column schema used by NewCSVFormatter
+// DefaultCSVColumns adalah skema kolom yang digunakan oleh NewCSVFormatter
+var DefaultCSVColumns = []string{
+	"timestamp", "level", "message", "caller", "trace_id", "span_id", "request_id",
+	"duration", "error", "error.type", CSVExtraFieldsColumn,
+}
+
+// csvBuiltinColumns lists the column names filled from entry metadata rather than user fields
+// csvBuiltinColumns mendaftar nama kolom yang diisi dari metadata entri, bukan dari field pengguna
+var csvBuiltinColumns = map[string]bool{
+	"timestamp": true, "level": true, "message": true, "pid": true, "caller": true, "file": true, "line": true,
+	"goroutine_id": true, "trace_id": true, "span_id": true, "user_id": true, "session_id": true, "request_id": true,
+	"duration": true, "hostname": true, "application": true, "version": true, "environment": true, "tags": true,
+	"custom_metrics": true, "error": true, "error.type": true, "error.chain": true, "stack_trace": true,
+	CSVExtraFieldsColumn: true,
+}
+
+// NewCSVFormatter creates a CSVFormatter with the DefaultCSVColumns schema and headers enabled
+// NewCSVFormatter membuat CSVFormatter dengan skema DefaultCSVColumns dan header diaktifkan
+func NewCSVFormatter() *CSVFormatter {
+	return &CSVFormatter{
+		TimestampFormat: time.RFC3339Nano,
+		IncludeHeaders:  true,
+		Columns:         append([]string(nil), DefaultCSVColumns...),
+	}
+}
+
+// csvUserColumn returns the user field key read by a column, or false for built-in columns
+// csvUserColumn mengembalikan key field pengguna yang dibaca oleh kolom, atau false untuk kolom bawaan
+func csvUserColumn(column string) (string, bool) {
+	if key, ok := strings.CutPrefix(column, csvFieldColumnPrefix); ok {
+		return key, true
+	}
+	return column, !csvBuiltinColumns[column]
+}
+
+// writeCSVRecord encodes one record using the configured delimiter
+// writeCSVRecord mengkodekan satu record menggunakan delimiter yang dikonfigurasi
+func (f *CSVFormatter) writeCSVRecord(record []string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	if f.Delimiter != 0 {
+		writer.Comma = f.Delimiter
+	}
+	if err := writer.Write(record); err != nil {
+		return nil, err
+	}
+	writer.Flush()
+	return buf.Bytes(), writer.Error()
+}
+
+// Header returns the header row for Columns
+// Header mengembalikan baris header untuk Columns
+func (f *CSVFormatter) Header() ([]byte, error) {
+	if len(f.Columns) == 0 {
+		return nil, errors.New("csv: header requires Columns")
+	}
+	return f.writeCSVRecord(f.Columns)
+}
+
+// WriteHeader writes the header row to w when IncludeHeaders is set
+// WriteHeader menulis baris header ke w jika IncludeHeaders diaktifkan
+//
+// Its signature matches RotationConfig.OnRotate so every rotated file starts with a header.
+// Signaturnya sesuai dengan RotationConfig.OnRotate sehingga setiap file hasil rotasi diawali header.
+func (f *CSVFormatter) WriteHeader(w io.Writer) error {
+	if !f.IncludeHeaders {
+		return nil
+	}
+	header, err := f.Header()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(header)
+	return err
+}
+
+// formatColumns formats an entry as one value per column, leaving missing values empty
+// formatColumns memformat entri sebagai satu nilai per kolom, membiarkan nilai yang tidak ada kosong
+func (f *CSVFormatter) formatColumns(logEntry LogEntryInterface) ([]byte, error) {
+	fields := entryFieldPairs(logEntry)
+	record := make([]string, len(f.Columns))
+	for i, column := range f.Columns {
+		if key, ok := csvUserColumn(column); ok {
+			record[i] = f.userColumnValue(logEntry, fields, key)
+			continue
+		}
+		record[i] = f.builtinColumnValue(logEntry, fields, column)
+	}
+	return f.writeCSVRecord(record)
+}
+
+// builtinColumnValue returns the value of a metadata column
+// builtinColumnValue mengembalikan nilai dari kolom metadata
+func (f *CSVFormatter) builtinColumnValue(logEntry LogEntryInterface, fields []FieldPair, column string) string {
+	switch column {
+	case "timestamp":
+		format := f.TimestampFormat
+		if format == "" {
+			format = time.RFC3339Nano
+		}
+		return logEntry.GetTimestamp().Format(format)
+	case "level":
+		return logEntry.GetLevel().String()
+	case "message":
+		return logEntry.GetMessage()
+	case "pid":
+		return strconv.Itoa(logEntry.GetPID())
+	case "caller":
+		if file := logEntry.GetCallerFile(); file != "" {
+			return file + ":" + strconv.Itoa(logEntry.GetCallerLine())
+		}
+	case "file":
+		return logEntry.GetCallerFile()
+	case "line":
+		if logEntry.GetCallerFile() != "" {
+			return strconv.Itoa(logEntry.GetCallerLine())
+		}
+	case "goroutine_id":
+		return logEntry.GetGoroutineID()
+	case "trace_id":
+		return logEntry.GetTraceID()
+	case "span_id":
+		return logEntry.GetSpanID()
+	case "user_id":
+		return logEntry.GetUserID()
+	case "session_id":
+		return logEntry.GetSessionID()
+	case "request_id":
+		return logEntry.GetRequestID()
+	case "duration":
+		if d := logEntry.GetDuration(); d > 0 {
+			return d.String()
+		}
+	case "hostname":
+		return logEntry.GetHostname()
+	case "application":
+		return logEntry.GetApplication()
+	case "version":
+		return logEntry.GetVersion()
+	case "environment":
+		return logEntry.GetEnvironment()
+	case "tags":
+		return strings.Join(entryTags(logEntry), ",")
+	case "custom_metrics":
+		return f.metricsColumnValue(entryMetricPairs(logEntry))
+	case "error":
+		if err := logEntry.GetError(); err != nil {
+			return err.Error()
+		}
+	case "error.type":
+		if err := logEntry.GetError(); err != nil {
+			return errorTypeName(err)
+		}
+	case "error.chain":
+		if err := logEntry.GetError(); err != nil {
+			chain := errorChain(err)
+			links := make([]string, len(chain))
+			for i, wrapped := range chain {
+				links[i] = errorLink(wrapped)
+			}
+			return strings.Join(links, "; ")
+		}
+	case "stack_trace":
+		return logEntry.GetStackTrace()
+	case CSVExtraFieldsColumn:
+		return f.extraFieldsValue(fields)
+	}
+	return ""
+}
+
+// userColumnValue returns a user field, or a custom metric of the same name, as text
+// userColumnValue mengembalikan field pengguna, atau metrik kustom dengan nama yang sama, sebagai teks
+func (f *CSVFormatter) userColumnValue(logEntry LogEntryInterface, fields []FieldPair, key string) string {
+	// Search backwards so the latest value wins, as in the other formatters
+	// Cari dari belakang agar nilai terakhir yang menang, seperti pada formatter lainnya
+	for i := len(fields) - 1; i >= 0; i-- {
+		if bToString(fields[i].Key[:fields[i].KeyLen]) == key {
+			return string(appendCSVFieldValue(nil, &fields[i]))
+		}
+	}
+	metrics := entryMetricPairs(logEntry)
+	for i := len(metrics) - 1; i >= 0; i-- {
+		if bToString(metrics[i].Key[:metrics[i].KeyLen]) == key {
+			return strconv.FormatFloat(metrics[i].Value, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// extraFieldsValue packs the fields that no column names into a JSON object
+// extraFieldsValue mengemas field yang tidak disebut oleh kolom mana pun ke dalam objek JSON
+func (f *CSVFormatter) extraFieldsValue(fields []FieldPair) string {
+	var b []byte
+	for i := range fields {
+		key := bToString(fields[i].Key[:fields[i].KeyLen])
+		if fieldOverridden(fields, i) || f.namesField(key) {
+			continue
+		}
+		if b == nil {
+			b = append(b, '{')
+		} else {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, key, false)
+		b = append(b, ':')
+		b = appendJSONFieldValue(b, &fields[i], false)
+	}
+	if b == nil {
+		return ""
+	}
+	return string(append(b, '}'))
+}
+
+// metricsColumnValue packs the metrics that no column names into a JSON object
+// metricsColumnValue mengemas metrik yang tidak disebut oleh kolom mana pun ke dalam objek JSON
+func (f *CSVFormatter) metricsColumnValue(metrics []MetricPair) string {
+	var b []byte
+	for i := range metrics {
+		key := bToString(metrics[i].Key[:metrics[i].KeyLen])
+		if f.namesField(key) {
+			continue
+		}
+		if b == nil {
+			b = append(b, '{')
+		} else {
+			b = append(b, ',')
+		}
+		b = appendJSONString(b, key, false)
+		b = append(b, ':')
+		b = appendJSONFloat(b, metrics[i].Value, 64)
+	}
+	if b == nil {
+		return ""
+	}
+	return string(append(b, '}'))
+}
+
+// namesField reports whether a column reads the user field or metric key
+// namesField melaporkan apakah sebuah kolom membaca field pengguna atau metrik dengan key tersebut
+func (f *CSVFormatter) namesField(key string) bool {
+	for _, column := range f.Columns {
+		if name, ok := csvUserColumn(column); ok && name == key {
+			return true
+		}
+	}
+	return false
+}
+
+// appendCSVFieldValue appends a field value as plain text, using JSON for composite values
+// appendCSVFieldValue menambahkan nilai field sebagai teks biasa, menggunakan JSON untuk nilai komposit
+func appendCSVFieldValue(b []byte, fp *FieldPair) []byte {
+	switch {
+	case fp.IsString:
+		return append(b, fp.StringValue[:fp.StringValueLen]...)
+	case fp.IsInt:
+		return strconv.AppendInt(b, fp.IntValue, 10)
+	case fp.IsFloat64:
+		return strconv.AppendFloat(b, fp.Float64Value, 'f', -1, 64)
+	case fp.IsBool:
+		return strconv.AppendBool(b, fp.BoolValue)
+	}
+	switch v := fp.Value.(type) {
+	case nil:
+		return b
+	case string:
+		return append(b, v...)
+	case []byte:
+		return append(b, v...)
+	case error:
+		return append(b, v.Error()...)
+	}
+	start := len(b)
+	b = appendJSONValue(b, fp.Value, false)
+	// Quoted JSON strings such as time.Time are written without their quotes
+	// String JSON yang dikutip seperti time.Time ditulis tanpa tanda kutipnya
+	if len(b)-start >= 2 && b[start] == '"' {
+		if s, err := strconv.Unquote(string(b[start:])); err == nil {
+			return append(b[:start], s...)
+		}
+	}
+	return b
+}
+
+// CSVHeaderWriter writes the CSV header in front of the first record written to an output
+// CSVHeaderWriter menulis header CSV di depan record pertama yang ditulis ke sebuah output
+//
+// Outputs that already hold data, such as a log file reopened in append mode, get no second header.
+// Output yang sudah berisi data, seperti file log yang dibuka kembali dalam mode append, tidak mendapat header kedua.
+type CSVHeaderWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	f       *CSVFormatter
+	written bool
+}
+
+// HeaderWriter wraps w so the header is written once before the first record
+// HeaderWriter membungkus w agar header ditulis sekali sebelum record pertama
+func (f *CSVFormatter) HeaderWriter(w io.Writer) *CSVHeaderWriter {
+	return &CSVHeaderWriter{w: w, f: f, written: !f.IncludeHeaders || csvOutputHasData(w)}
+}
+
+// csvOutputHasData reports whether the output is a file or rotating writer that already holds data
+// csvOutputHasData melaporkan apakah output adalah file atau writer berotasi yang sudah berisi data
+func csvOutputHasData(w io.Writer) bool {
+	switch out := w.(type) {
+	case interface{ Size() int64 }:
+		return out.Size() > 0
+	case interface{ Stat() (os.FileInfo, error) }:
+		info, err := out.Stat()
+		return err == nil && info.Mode().IsRegular() && info.Size() > 0
+	}
+	return false
+}
+
+// Write writes p, preceded by the header on the first call
+// Write menulis p, didahului header pada panggilan pertama
+func (h *CSVHeaderWriter) Write(p []byte) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.written {
+		if err := h.f.WriteHeader(h.w); err != nil {
+			return 0, err
+		}
+		h.written = true
+	}
+	return h.w.Write(p)
+}
+
+// Sync syncs the wrapped output when it supports syncing
+// Sync melakukan sync pada output yang dibungkus jika didukung
+func (h *CSVHeaderWriter) Sync() error {
+	if s, ok := h.w.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close closes the wrapped output when it is closable
+// Close menutup output yang dibungkus jika dapat ditutup
+func (h *CSVHeaderWriter) Close() error {
+	// Standard streams stay open, as with an unwrapped logger output
+	// Stream standar tetap terbuka, seperti pada output logger yang tidak dibungkus
+	if h.w == io.Writer(os.Stdout) || h.w == io.Writer(os.Stderr) {
+		return nil
+	}
+	if c, ok := h.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
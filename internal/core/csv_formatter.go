@@ -1,8 +1,6 @@
 package core
 
 import (
-	"bytes"
-	"encoding/csv"
 	"fmt"
 	"strconv"
 	"strings"
@@ -21,6 +19,7 @@ type CSVFormatter struct {
 	EnableStackTrace  bool   // EnableStackTrace controls whether stack traces are captured for errors
 	EnableDuration    bool   // EnableDuration controls whether duration measurements are included
 	Delimiter         rune   // Delimiter specifies the character used to separate fields in CSV output
+	Columns           []string // Columns fixes the output schema to these columns in order, see CSVExtraFieldsColumn; empty keeps the variable layout
 }
 
 // Unsafe string/byte conversions for zero allocation using unsafe package to avoid memory copying
@@ -42,6 +41,11 @@ func (f *CSVFormatter) Format(entry interface{}) ([]byte, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid entry type")
 	}
+	// A fixed schema writes every column for every entry so records stay aligned with the header
+	// Skema tetap menulis setiap kolom untuk setiap entri agar record tetap sejajar dengan header
+	if len(f.Columns) > 0 {
+		return f.formatColumns(logEntry)
+	}
 
 	// Create CSV record with pre-allocated capacity for performance
 	// Buat record CSV dengan kapasitas yang telah dialokasikan sebelumnya untuk kinerja
@@ -190,18 +194,20 @@ func (f *CSVFormatter) Format(entry interface{}) ([]byte, error) {
 	
 	// Add error information if available
 	// Tambahkan informasi kesalahan jika tersedia
-	if logEntry.GetError() != nil {
-		record = append(record, logEntry.GetError().Error())
+	if err := logEntry.GetError(); err != nil {
+		record = append(record, err.Error(), errorTypeName(err))
+		// Keep the wrapped chain in one column so records stay aligned
+		// Simpan rantai error terbungkus dalam satu kolom agar record tetap sejajar
+		if chain := errorChain(err); len(chain) > 0 {
+			links := make([]string, len(chain))
+			for i, wrapped := range chain {
+				links[i] = errorLink(wrapped)
+			}
+			record = append(record, strings.Join(links, "; "))
+		}
 	}
 	
 	// Write record to CSV writer
 	// Tulis record ke writer CSV
-	buf := &bytes.Buffer{}
-	writer := csv.NewWriter(buf)
-	if err := writer.Write(record); err != nil {
-		return nil, err
-	}
-	writer.Flush()
-	
-	return buf.Bytes(), writer.Error()
+	return f.writeCSVRecord(record)
 }
\ No newline at end of file
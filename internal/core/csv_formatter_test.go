@@ -0,0 +1,144 @@
+package core
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"crystal/internal/rotation"
+)
+
+func TestCSVFormatterColumns(t *testing.T) {
+	formatter := &CSVFormatter{
+		TimestampFormat: time.RFC3339,
+		Columns: []string{
+			"timestamp", "level", "message", "trace_id", "span_id", "fields.user_id", "bytes",
+			"latency_ms", "missing", "error", CSVExtraFieldsColumn,
+		},
+	}
+	entry := newTestEntry(ERROR, "upload failed", "user_id", "u-42", "bytes", 2048, "bucket", "media", "retried", true)
+	entry.Error = errors.New("timeout")
+	entry.TraceIDLen = copy(entry.TraceID[:], "abc123")
+	entry.SetMetric("latency_ms", 12.5)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `2024-05-01T12:00:00Z,ERROR,upload failed,abc123,,u-42,2048,12.5,,timeout,"{""bucket"":""media"",""retried"":true}"` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected CSV\n got: %s\nwant: %s", output, want)
+	}
+
+	// Every record has one value per column, whatever fields are present
+	output, err = formatter.Format(newTestEntry(INFO, "plain"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	record, err := csv.NewReader(bytes.NewReader(output)).Read()
+	if err != nil {
+		t.Fatalf("Unexpected CSV error: %v", err)
+	}
+	if len(record) != len(formatter.Columns) {
+		t.Errorf("Expected %d columns, got %d: %q", len(formatter.Columns), len(record), record)
+	}
+}
+
+func TestCSVFormatterHeaderWriter(t *testing.T) {
+	formatter := NewCSVFormatter()
+	formatter.Columns = []string{"level", "message"}
+	formatter.Delimiter = ';'
+
+	var out bytes.Buffer
+	w := formatter.HeaderWriter(&out)
+	for i := 0; i < 2; i++ {
+		output, err := formatter.Format(newTestEntry(ERROR, "upload failed"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := w.Write(output); err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+	}
+	if want := "level;message\nERROR;upload failed\nERROR;upload failed\n"; out.String() != want {
+		t.Errorf("Unexpected output\n got: %q\nwant: %q", out.String(), want)
+	}
+
+	// A file that already holds records does not get a second header
+	path := filepath.Join(t.TempDir(), "app.csv")
+	if err := os.WriteFile(path, []byte("level;message\nINFO;old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w = formatter.HeaderWriter(file)
+	if _, err := w.Write([]byte("INFO;new\n")); err != nil {
+		t.Fatalf("Unexpected write error: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if want := "level;message\nINFO;old\nINFO;new\n"; string(data) != want {
+		t.Errorf("Unexpected file content\n got: %q\nwant: %q", data, want)
+	}
+}
+
+func TestCSVFormatterHeaderAfterRotation(t *testing.T) {
+	formatter := NewCSVFormatter()
+	formatter.Columns = []string{"level", "message"}
+
+	dir := t.TempDir()
+	writer, err := rotation.NewRotatingFileWriter(filepath.Join(dir, "app.csv"), &rotation.RotationConfig{
+		MaxSize:  40,
+		OnRotate: formatter.WriteHeader,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	w := formatter.HeaderWriter(writer)
+	for i := 0; i < 4; i++ {
+		output, err := formatter.Format(newTestEntry(ERROR, "upload failed"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := w.Write(output); err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) < 2 {
+		t.Fatalf("Expected the file to rotate, got %v", files)
+	}
+	records := 0
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+		if lines[0] != "level,message" {
+			t.Errorf("Expected %s to start with the header, got %q", filepath.Base(file), data)
+		}
+		for _, line := range lines[1:] {
+			if line == "level,message" {
+				t.Errorf("Unexpected repeated header in %s: %q", filepath.Base(file), data)
+			}
+		}
+		records += len(lines) - 1
+	}
+	if records != 4 {
+		t.Errorf("Expected 4 records across files, got %d", records)
+	}
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ECSVersion is the Elastic Common Schema version written in ecs.version
+// ECSVersion adalah versi Elastic Common Schema yang ditulis dalam ecs.version
+const ECSVersion = "8.11.0"
+
+// ecsLabelsKey is the ECS object holding custom key/value pairs
+// ecsLabelsKey adalah objek ECS yang menyimpan pasangan key/value kustom
+const ecsLabelsKey = "labels"
+
+// ECSFormatter writes log entries as Elastic Common Schema 8.x JSON documents ready for Elasticsearch
+// ECSFormatter menulis entri log sebagai dokumen JSON Elastic Common Schema 8.x yang siap untuk Elasticsearch
+type ECSFormatter struct {
+	DisableHTMLEscape bool // DisableHTMLEscape controls whether HTML characters are escaped in JSON output
+	// FieldsAtTopLevel writes user fields and custom metrics as top-level ECS fields instead of under labels, where
+	// every value is written as a string because labels are keyword-only; use it to keep numeric metrics numeric
+	FieldsAtTopLevel bool
+	// FieldCollision decides what happens when a top-level user field has the same key as an ECS field; prefixed fields go under labels.
+	// A user field that is a parent or child path of an ECS field, such as "error" next to "error.message", always goes under labels
+	FieldCollision FieldCollisionPolicy
+}
+
+// NewECSFormatter creates a new ECSFormatter that puts user fields under labels
+// NewECSFormatter membuat ECSFormatter baru yang menempatkan field pengguna di bawah labels
+func NewECSFormatter() *ECSFormatter {
+	return &ECSFormatter{}
+}
+
+// Format formats a log entry as a single-line ECS JSON document
+// Format memformat entri log sebagai dokumen JSON ECS satu baris
+func (f *ECSFormatter) Format(entry interface{}) ([]byte, error) {
+	// Cast entry to LogEntryInterface
+	logEntry, ok := entry.(LogEntryInterface)
+	if !ok {
+		return nil, fmt.Errorf("invalid entry type")
+	}
+	// ECS keys are dotted paths, so members are collected and expanded into nested objects
+	// Key ECS adalah path bertitik, sehingga anggota dikumpulkan dan diperluas menjadi objek bersarang
+	layout := JSONFormatter{ExpandDottedKeys: true, FieldCollision: f.FieldCollision}
+	scratch := getBufferFromPool()
+	defer putBufferToPool(scratch)
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	// Labels are keyword-only in ECS, so every value under labels is written as a string
+	// Label hanya berupa keyword di ECS, sehingga setiap nilai di bawah labels ditulis sebagai string
+	e := jsonEnvelope{f: &layout, b: scratch.buf[:0], html: !f.DisableHTMLEscape, collect: true, keywordPrefix: ecsLabelsKey + "."}
+	f.appendMembers(&e, logEntry)
+	b := e.finish(buf.buf[:0], ecsLabelsKey)
+	b = append(b, '\n')
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, len(b))
+	copy(result, b)
+	return result, nil
+}
+
+// appendMembers maps the entry onto ECS fields
+// appendMembers memetakan entri ke field ECS
+func (f *ECSFormatter) appendMembers(e *jsonEnvelope, logEntry LogEntryInterface) {
+	e.beginKey("@timestamp")
+	e.b = append(e.b, '"')
+	e.b = logEntry.GetTimestamp().UTC().AppendFormat(e.b, time.RFC3339Nano)
+	e.b = append(e.b, '"')
+	e.end()
+	// ECS loggers use lowercase level names
+	// Logger ECS menggunakan nama tingkat huruf kecil
+	e.beginKey("log.level")
+	e.b = append(e.b, '"')
+	for _, c := range []byte(logEntry.GetLevel().String()) {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		e.b = append(e.b, c)
+	}
+	e.b = append(e.b, '"')
+	e.end()
+	e.stringMember("message", logEntry.GetMessage())
+	e.stringMember("ecs.version", ECSVersion)
+	if file := logEntry.GetCallerFile(); file != "" {
+		e.stringMember("log.origin.file.name", file)
+		e.beginKey("log.origin.file.line")
+		e.b = strconv.AppendInt(e.b, int64(logEntry.GetCallerLine()), 10)
+		e.end()
+	}
+	if pid := logEntry.GetPID(); pid != 0 {
+		e.beginKey("process.pid")
+		e.b = strconv.AppendInt(e.b, int64(pid), 10)
+		e.end()
+	}
+	e.stringMemberIfSet("host.hostname", logEntry.GetHostname())
+	e.stringMemberIfSet("service.name", logEntry.GetApplication())
+	e.stringMemberIfSet("service.version", logEntry.GetVersion())
+	e.stringMemberIfSet("service.environment", logEntry.GetEnvironment())
+	e.stringMemberIfSet("trace.id", logEntry.GetTraceID())
+	e.stringMemberIfSet("span.id", logEntry.GetSpanID())
+	e.stringMemberIfSet("user.id", logEntry.GetUserID())
+	e.stringMemberIfSet("http.request.id", logEntry.GetRequestID())
+	// ECS has no session field, so the session ID is kept as a label
+	// ECS tidak memiliki field sesi, sehingga ID sesi disimpan sebagai label
+	e.stringMemberIfSet("labels.session_id", logEntry.GetSessionID())
+	if err := logEntry.GetError(); err != nil {
+		e.stringMember("error.message", err.Error())
+		e.stringMember("error.type", errorTypeName(err))
+	}
+	// The stored stack already uses the Go panic layout that Kibana displays as-is
+	// Stack yang disimpan sudah menggunakan tata letak panic Go yang ditampilkan Kibana apa adanya
+	e.stringMemberIfSet("error.stack_trace", logEntry.GetStackTrace())
+	if d := logEntry.GetDuration(); d > 0 {
+		e.beginKey("event.duration")
+		e.b = strconv.AppendInt(e.b, int64(d), 10)
+		e.end()
+	}
+	if tags := entryTags(logEntry); len(tags) > 0 {
+		e.beginKey("tags")
+		e.b = append(e.b, '[')
+		for i, tag := range tags {
+			if i > 0 {
+				e.b = append(e.b, ',')
+			}
+			e.b = appendJSONString(e.b, tag, e.html)
+		}
+		e.b = append(e.b, ']')
+		e.end()
+	}
+	fields := entryFieldPairs(logEntry)
+	for i := range fields {
+		if fieldOverridden(fields, i) {
+			continue
+		}
+		e.beginUser(f.userKey(bToString(fields[i].Key[:fields[i].KeyLen])))
+		e.b = appendJSONFieldValue(e.b, &fields[i], e.html)
+		e.end()
+	}
+	metrics := entryMetricPairs(logEntry)
+	for i := range metrics {
+		e.beginUser(f.userKey(bToString(metrics[i].Key[:metrics[i].KeyLen])))
+		e.b = appendJSONFloat(e.b, metrics[i].Value, 64)
+		e.end()
+	}
+}
+
+// userKey returns the document key for a user field, flattening it into a single label name unless fields go to the top level
+// userKey mengembalikan key dokumen untuk field pengguna, meratakannya menjadi satu nama label kecuali field ditempatkan di tingkat atas
+func (f *ECSFormatter) userKey(key string) string {
+	if f.FieldsAtTopLevel {
+		return key
+	}
+	// Label names must not contain dots, otherwise Elasticsearch would turn them into objects
+	// Nama label tidak boleh mengandung titik, jika tidak Elasticsearch akan mengubahnya menjadi objek
+	return ecsLabelsKey + "." + strings.Map(func(r rune) rune {
+		if r == '.' || r == '*' || r == '"' {
+			return '_'
+		}
+		return r
+	}, key)
+}
@@ -0,0 +1,144 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestECSFormatterLabels(t *testing.T) {
+	entry := newTestEntry(ERROR, "request failed", "event.category", "web", "status", 504, "message", "shadowed")
+	entry.Timestamp = testEntryTime.In(time.FixedZone("WIB", 7*3600))
+	entry.PID = 42
+	entry.Duration = 1500 * time.Microsecond
+	entry.Error = fmt.Errorf("query: %w", errors.New("timeout"))
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "server.go")
+	entry.Caller.Line = 88
+	entry.HostnameLen = copy(entry.Hostname[:], "web-1")
+	entry.ApplicationLen = copy(entry.Application[:], "checkout")
+	entry.VersionLen = copy(entry.Version[:], "1.2.3")
+	entry.EnvironmentLen = copy(entry.Environment[:], "production")
+	entry.TraceIDLen = copy(entry.TraceID[:], "4bf92f3577b34da6a3ce929d0e0e4736")
+	entry.SpanIDLen = copy(entry.SpanID[:], "00f067aa0ba902b7")
+	entry.UserIDLen = copy(entry.UserID[:], "u-1")
+	entry.SetTag("payments")
+
+	output, err := NewECSFormatter().Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `{"@timestamp":"2024-05-01T12:00:00Z","log":{"level":"error","origin":{"file":{"name":"server.go","line":88}}},` +
+		`"message":"request failed","ecs":{"version":"` + ECSVersion + `"},"process":{"pid":42},"host":{"hostname":"web-1"},` +
+		`"service":{"name":"checkout","version":"1.2.3","environment":"production"},` +
+		`"trace":{"id":"4bf92f3577b34da6a3ce929d0e0e4736"},"span":{"id":"00f067aa0ba902b7"},"user":{"id":"u-1"},` +
+		`"error":{"message":"query: timeout","type":"*fmt.wrapError"},"event":{"duration":1500000},"tags":["payments"],` +
+		`"labels":{"event_category":"web","status":"504","message":"shadowed"}}` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected ECS document\n got: %s\nwant: %s", output, want)
+	}
+}
+
+func TestECSFormatterTopLevelFields(t *testing.T) {
+	formatter := &ECSFormatter{FieldsAtTopLevel: true}
+	entry := newTestEntry(ERROR, "request failed", "event.category", "web", "status", 504, "message", "shadowed")
+	entry.Duration = 1500 * time.Microsecond
+	entry.StackTraceLen = copy(entry.StackTrace[:], "main.handler\n\t/app/server.go:88\n")
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var doc struct {
+		Message string `json:"message"`
+		Error   struct {
+			StackTrace string `json:"stack_trace"`
+		} `json:"error"`
+		Event struct {
+			Category string `json:"category"`
+			Duration int64  `json:"duration"`
+		} `json:"event"`
+		Status int               `json:"status"`
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", output, err)
+	}
+	if doc.Message != "request failed" || doc.Labels["message"] != "shadowed" {
+		t.Errorf("Expected colliding user field under labels, got %s", output)
+	}
+	if doc.Event.Category != "web" || doc.Event.Duration != 1500000 || doc.Status != 504 {
+		t.Errorf("Expected user fields merged at the top level, got %s", output)
+	}
+	if doc.Error.StackTrace != "main.handler\n\t/app/server.go:88\n" {
+		t.Errorf("Expected error.stack_trace, got %s", output)
+	}
+}
+
+func TestECSFormatterLabelsAreKeywords(t *testing.T) {
+	entry := newTestEntry(INFO, "lookup", "status", 504, "cached", true, "ids", []int{1, 2})
+	entry.SetMetric("latency_ms", 12.5)
+
+	output, err := NewECSFormatter().Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var doc struct {
+		Labels map[string]string `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &doc); err != nil {
+		t.Fatalf("Expected only string labels, got %s: %v", output, err)
+	}
+	want := map[string]string{"status": "504", "cached": "true", "latency_ms": "12.5", "ids": "[1,2]"}
+	for k, v := range want {
+		if doc.Labels[k] != v {
+			t.Errorf("labels.%s = %q, want %q", k, doc.Labels[k], v)
+		}
+	}
+
+	// A numeric field moved under labels by a collision is a keyword too
+	entry = newTestEntry(INFO, "lookup", "process.pid", 7)
+	entry.PID = 42
+	output, _ = (&ECSFormatter{FieldsAtTopLevel: true}).Format(entry)
+	var top struct {
+		Labels struct {
+			Process struct {
+				PID string `json:"pid"`
+			} `json:"process"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(output, &top); err != nil || top.Labels.Process.PID != "7" {
+		t.Errorf("Expected the colliding numeric field as a string label, got %s: %v", output, err)
+	}
+}
+
+func TestECSFormatterPrefixCollisions(t *testing.T) {
+	entry := newTestEntry(ERROR, "request failed", "error", "user error", "host", "db-1")
+	entry.Error = errors.New("timeout")
+	entry.HostnameLen = copy(entry.Hostname[:], "web-1")
+
+	output, err := (&ECSFormatter{FieldsAtTopLevel: true}).Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var doc struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+		Host struct {
+			Hostname string `json:"hostname"`
+		} `json:"host"`
+		Labels map[string]interface{} `json:"labels"`
+	}
+	// Unmarshalling fails when a path holds both a string and an object
+	if err := json.Unmarshal(output, &doc); err != nil {
+		t.Fatalf("Expected no mapping conflicts, got %s: %v", output, err)
+	}
+	if doc.Error.Message != "timeout" || doc.Host.Hostname != "web-1" {
+		t.Errorf("Expected the built-in error and host objects, got %s", output)
+	}
+	if doc.Labels["error"] != "user error" || doc.Labels["host"] != "db-1" {
+		t.Errorf("Expected the colliding user fields under labels, got %s", output)
+	}
+}
@@ -54,6 +54,7 @@ var (
 	fieldKeySessionID = "session_id"
 	fieldKeyRequestID = "request_id"
 	fieldKeyDuration  = "duration"
+	fieldKeyOperation = "operation"
 	fieldKeyError     = "error"
 	fieldKeyHostname  = "hostname"
 	fieldKeyApp       = "application"
@@ -632,6 +633,14 @@ const (
 	// RequestIDKey is used to store request identification in context
 	// RequestIDKey digunakan untuk menyimpan identifikasi permintaan dalam konteks
 	RequestIDKey contextKey = "request_id"
+	
+	// SourceIPKey is used to store the client IP address recorded by audit events in context
+	// SourceIPKey digunakan untuk menyimpan alamat IP klien yang dicatat oleh event audit dalam konteks
+	SourceIPKey contextKey = "source_ip"
+	
+	// TraceSampledKey is used to store the W3C trace sampled flag in context, logged as the trace_sampled field
+	// TraceSampledKey digunakan untuk menyimpan flag sampled trace W3C dalam konteks, dicatat sebagai field trace_sampled
+	TraceSampledKey contextKey = "trace_sampled"
 )
 
 // WithTraceID adds a trace ID to the context with zero allocation by using pre-defined context key
@@ -646,6 +655,12 @@ func WithSpanID(ctx context.Context, spanID string) context.Context {
 	return context.WithValue(ctx, SpanIDKey, spanID)
 }
 
+// WithTraceSampled records whether the current trace is sampled, as propagated in the traceparent flags
+// WithTraceSampled mencatat apakah trace saat ini di-sample, seperti yang dipropagasikan dalam flag traceparent
+func WithTraceSampled(ctx context.Context, sampled bool) context.Context {
+	return context.WithValue(ctx, TraceSampledKey, sampled)
+}
+
 // WithUserID adds a user ID to the context with zero allocation by using pre-defined context key
 // WithUserID menambahkan ID pengguna ke konteks dengan zero allocation dengan menggunakan kunci konteks yang telah ditentukan sebelumnya
 func WithUserID(ctx context.Context, userID string) context.Context {
@@ -709,6 +724,21 @@ func GetRequestID(ctx context.Context) string {
 	return ""
 }
 
+// WithSourceIP adds the client IP address to the context for audit events
+// WithSourceIP menambahkan alamat IP klien ke konteks untuk event audit
+func WithSourceIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, SourceIPKey, ip)
+}
+
+// GetSourceIP retrieves the client IP address from context
+// GetSourceIP mengambil alamat IP klien dari konteks
+func GetSourceIP(ctx context.Context) string {
+	if ip, ok := ctx.Value(SourceIPKey).(string); ok {
+		return ip
+	}
+	return ""
+}
+
 // bufferPool provides reusable buffers for formatting operations to minimize memory allocations
 // bufferPool menyediakan buffer yang dapat digunakan kembali untuk operasi formatting guna meminimalkan alokasi memori
 var bufferPool = sync.Pool{
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"reflect"
+)
+
+// maxErrorChain limits how many wrapped errors are reported so cyclic or very deep chains stay bounded
+// maxErrorChain membatasi jumlah error terbungkus yang dilaporkan agar rantai siklik atau sangat dalam tetap terbatas
+const maxErrorChain = 32
+
+// callersError is implemented by errors that record the program counters where they were created
+// callersError diimplementasikan oleh error yang mencatat program counter tempat error tersebut dibuat
+type callersError interface {
+	Callers() []uintptr
+}
+
+// errorChain returns the errors wrapped by err in depth-first order, following both Unwrap() error and Unwrap() []error
+// errorChain mengembalikan error yang dibungkus oleh err dalam urutan depth-first, mengikuti Unwrap() error dan Unwrap() []error
+func errorChain(err error) []error {
+	var chain []error
+	var walk func(error)
+	walk = func(e error) {
+		switch u := e.(type) {
+		case interface{ Unwrap() error }:
+			if next := u.Unwrap(); next != nil && len(chain) < maxErrorChain {
+				chain = append(chain, next)
+				walk(next)
+			}
+		case interface{ Unwrap() []error }:
+			for _, next := range u.Unwrap() {
+				if next == nil || len(chain) >= maxErrorChain {
+					continue
+				}
+				chain = append(chain, next)
+				walk(next)
+			}
+		}
+	}
+	walk(err)
+	return chain
+}
+
+// errorTypeName returns the concrete type of err, such as *fs.PathError
+// errorTypeName mengembalikan tipe konkret dari err, seperti *fs.PathError
+func errorTypeName(err error) string {
+	if scanned, ok := err.(*scannedError); ok {
+		return scanned.typeName
+	}
+	return reflect.TypeOf(err).String()
+}
+
+// errorStack returns the program counters recorded by the innermost error in the chain that exposes a stack
+// errorStack mengembalikan program counter yang dicatat oleh error terdalam dalam rantai yang menyediakan stack
+//
+// Errors may implement Callers() []uintptr, or StackTrace() returning a slice of uintptr-based frames as github.com/pkg/errors does.
+// Error dapat mengimplementasikan Callers() []uintptr, atau StackTrace() yang mengembalikan slice frame berbasis uintptr seperti github.com/pkg/errors.
+func errorStack(err error) []uintptr {
+	pcs := stackOf(err)
+	for _, e := range errorChain(err) {
+		if inner := stackOf(e); inner != nil {
+			pcs = inner
+		}
+	}
+	return pcs
+}
+
+// stackOf extracts the program counters exposed by a single error without following its chain
+// stackOf mengekstrak program counter yang disediakan oleh satu error tanpa mengikuti rantainya
+func stackOf(err error) []uintptr {
+	if c, ok := err.(callersError); ok {
+		return c.Callers()
+	}
+	// A constant method name keeps the linker's dead code elimination effective
+	// Nama metode konstan menjaga eliminasi kode mati oleh linker tetap efektif
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil
+	}
+	frames := m.Call(nil)[0]
+	if frames.Len() == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, frames.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(frames.Index(i).Uint())
+	}
+	return pcs
+}
+
+// errorLink formats a wrapped error as "type: message" for text-based formatters
+// errorLink memformat error terbungkus sebagai "type: message" untuk formatter berbasis teks
+func errorLink(err error) string {
+	return errorTypeName(err) + ": " + err.Error()
+}
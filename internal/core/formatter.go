@@ -1,9 +1,82 @@
 package core
 
+import (
+	"io"
+
+	"crystal/internal/redact"
+)
+
 // Formatter interface for log formatting with zero-allocation design to minimize garbage collection pressure
 // Interface Formatter untuk formatting log dengan desain zero-allocation untuk meminimalkan tekanan garbage collection
 type Formatter interface {
 	// Format converts a LogEntry into a byte representation with zero allocation where possible
 	// Format mengkonversi LogEntry menjadi representasi byte dengan zero allocation jika memungkinkan
 	Format(entry interface{}) ([]byte, error)
-}
\ No newline at end of file
+}
+
+// outputAwareFormatter is implemented by formatters that adapt to the logger's output, such as ConsoleFormatter
+// outputAwareFormatter diimplementasikan oleh formatter yang menyesuaikan diri dengan output logger, seperti ConsoleFormatter
+type outputAwareFormatter interface {
+	setOutput(w io.Writer)
+}
+
+// redactorAwareFormatter is implemented by formatters with a MaskSensitiveData option, which defers to the logger's Redactor
+// redactorAwareFormatter diimplementasikan oleh formatter dengan opsi MaskSensitiveData, yang tunduk pada Redactor logger
+type redactorAwareFormatter interface {
+	setRedactor(r *redact.Redactor)
+}
+
+// entryFieldPairs returns the entry's fields without boxing them when the entry is a *LogEntry
+// entryFieldPairs mengembalikan field entri tanpa boxing jika entri adalah *LogEntry
+func entryFieldPairs(entry LogEntryInterface) []FieldPair {
+	if e, ok := entry.(*LogEntry); ok {
+		return e.Fields[:e.FieldsCount]
+	}
+	fields := entry.GetFields()
+	pairs := make([]FieldPair, 0, len(fields))
+	for _, field := range fields {
+		if fp, ok := field.(FieldPair); ok {
+			pairs = append(pairs, fp)
+		}
+	}
+	return pairs
+}
+
+// entryTags returns the entry's tags without copying them when the entry is a *LogEntry
+// entryTags mengembalikan tag entri tanpa menyalinnya jika entri adalah *LogEntry
+func entryTags(entry LogEntryInterface) []string {
+	if e, ok := entry.(*LogEntry); ok {
+		return e.Tags[:e.TagsCount]
+	}
+	return entry.GetTags()
+}
+
+// entryMetricPairs returns the entry's custom metrics without boxing them when the entry is a *LogEntry
+// entryMetricPairs mengembalikan metrik kustom entri tanpa boxing jika entri adalah *LogEntry
+func entryMetricPairs(entry LogEntryInterface) []MetricPair {
+	if e, ok := entry.(*LogEntry); ok {
+		return e.CustomMetrics[:e.MetricsCount]
+	}
+	metrics := entry.GetMetrics()
+	pairs := make([]MetricPair, 0, len(metrics))
+	for _, metric := range metrics {
+		if mp, ok := metric.(MetricPair); ok {
+			pairs = append(pairs, mp)
+		}
+	}
+	return pairs
+}
+
+// masksKey reports whether a formatter's MaskSensitiveData option masks the value of key. When the logger has a
+// Redactor, fields reach the formatter already redacted by its rules, so the formatter masks nothing more and every
+// formatter writes the same values. Otherwise keys matching the default redaction rules are masked.
+// masksKey melaporkan apakah opsi MaskSensitiveData formatter menyamarkan nilai key. Jika logger memiliki Redactor,
+// field sampai ke formatter sudah diredaksi oleh aturannya, sehingga formatter tidak menyamarkan apa pun lagi dan setiap
+// formatter menulis nilai yang sama. Jika tidak, key yang cocok dengan aturan redaksi default disamarkan.
+func masksKey(mask, redacted bool, key string) bool {
+	if !mask || redacted {
+		return false
+	}
+	_, ok := redact.Default().Match(key)
+	return ok
+}
@@ -0,0 +1,30 @@
+package core
+
+import "time"
+
+// testEntryTime is the timestamp of entries built by newTestEntry
+var testEntryTime = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestEntry builds an entry for formatter tests, logged at testEntryTime. fields alternates keys and values;
+// strings, ints, float64s and bools go through the typed setters and other values through SetField. Tests set
+// the caller, trace IDs, error and other header values they assert on themselves.
+func newTestEntry(level Level, message string, fields ...interface{}) *LogEntry {
+	entry := &LogEntry{Timestamp: testEntryTime, Level: level}
+	entry.MessageLen = copy(entry.Message[:], message)
+	for i := 0; i+1 < len(fields); i += 2 {
+		key := fields[i].(string)
+		switch value := fields[i+1].(type) {
+		case string:
+			entry.SetStringField(key, value)
+		case int:
+			entry.SetIntField(key, value)
+		case float64:
+			entry.SetFloat64Field(key, value)
+		case bool:
+			entry.SetBoolField(key, value)
+		default:
+			entry.SetField(key, value)
+		}
+	}
+	return entry
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// GELFVersion is the GELF specification version written in every message
+// GELFVersion adalah versi spesifikasi GELF yang ditulis di setiap pesan
+const GELFVersion = "1.1"
+
+// GELFCompression selects how GELF payloads are compressed before they are written
+// GELFCompression memilih cara payload GELF dikompresi sebelum ditulis
+type GELFCompression uint8
+
+const (
+	// GELFCompressionNone writes plain JSON
+	// GELFCompressionNone menulis JSON biasa
+	GELFCompressionNone GELFCompression = iota
+	// GELFCompressionGzip writes gzip-compressed JSON, accepted by Graylog UDP inputs
+	// GELFCompressionGzip menulis JSON terkompresi gzip, diterima oleh input UDP Graylog
+	GELFCompressionGzip
+	// GELFCompressionZlib writes zlib-compressed JSON, accepted by Graylog UDP inputs
+	// GELFCompressionZlib menulis JSON terkompresi zlib, diterima oleh input UDP Graylog
+	GELFCompressionZlib
+)
+
+// Compressor pools, since each writer holds several hundred kilobytes of state
+// Pool kompresor, karena setiap writer menyimpan state beberapa ratus kilobyte
+var (
+	gelfGzipPool = sync.Pool{New: func() interface{} { return gzip.NewWriter(io.Discard) }}
+	gelfZlibPool = sync.Pool{New: func() interface{} { return zlib.NewWriter(io.Discard) }}
+)
+
+// GELFFormatter writes log entries as GELF 1.1 messages for Graylog
+// GELFFormatter menulis entri log sebagai pesan GELF 1.1 untuk Graylog
+//
+// Pair it with outputs.NewGELFUDPOutput, which splits large payloads into GELF chunks.
+// Pasangkan dengan outputs.NewGELFUDPOutput, yang memecah payload besar menjadi chunk GELF.
+type GELFFormatter struct {
+	Host             string                   // Host overrides the entry hostname in the host field
+	SeverityMap      map[Level]SyslogSeverity // SeverityMap overrides the default level to syslog severity table, shared with SyslogFormatter
+	EnableStackTrace bool                     // EnableStackTrace writes full_message with the captured stack trace
+	Compression      GELFCompression          // Compression compresses each payload; only UDP inputs accept compressed messages
+	NullDelimiter    bool                     // NullDelimiter ends each uncompressed message with a null byte, as GELF TCP inputs require
+}
+
+// NewGELFFormatter creates a new uncompressed GELFFormatter that includes stack traces
+// NewGELFFormatter membuat GELFFormatter baru tanpa kompresi yang menyertakan stack trace
+func NewGELFFormatter() *GELFFormatter {
+	return &GELFFormatter{EnableStackTrace: true}
+}
+
+// Format formats a log entry as one GELF payload
+// Format memformat entri log sebagai satu payload GELF
+func (f *GELFFormatter) Format(entry interface{}) ([]byte, error) {
+	// Cast entry to LogEntryInterface
+	logEntry, ok := entry.(LogEntryInterface)
+	if !ok {
+		return nil, fmt.Errorf("invalid entry type")
+	}
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	b := f.appendMessage(buf.buf[:0], logEntry)
+	switch f.Compression {
+	case GELFCompressionGzip:
+		w := gelfGzipPool.Get().(*gzip.Writer)
+		defer gelfGzipPool.Put(w)
+		return gelfCompress(w, b)
+	case GELFCompressionZlib:
+		w := gelfZlibPool.Get().(*zlib.Writer)
+		defer gelfZlibPool.Put(w)
+		return gelfCompress(w, b)
+	}
+	if f.NullDelimiter {
+		b = append(b, 0)
+	}
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, len(b))
+	copy(result, b)
+	return result, nil
+}
+
+// gelfCompressor is implemented by both gzip.Writer and zlib.Writer
+// gelfCompressor diimplementasikan oleh gzip.Writer dan zlib.Writer
+type gelfCompressor interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+// gelfCompress compresses payload with a pooled writer
+// gelfCompress mengompresi payload dengan writer dari pool
+func gelfCompress(w gelfCompressor, payload []byte) ([]byte, error) {
+	var out bytes.Buffer
+	w.Reset(&out)
+	if _, err := w.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// appendMessage encodes the entry as a GELF JSON object
+// appendMessage mengkodekan entri sebagai objek JSON GELF
+func (f *GELFFormatter) appendMessage(b []byte, logEntry LogEntryInterface) []byte {
+	b = append(b, `{"version":"`+GELFVersion+`","host":`...)
+	host := f.Host
+	if host == "" {
+		host = logEntry.GetHostname()
+	}
+	if host == "" {
+		host = "unknown"
+	}
+	b = appendJSONString(b, host, false)
+	// short_message is required and must not be empty
+	// short_message wajib ada dan tidak boleh kosong
+	message := logEntry.GetMessage()
+	if message == "" {
+		message = "-"
+	}
+	b = append(b, `,"short_message":`...)
+	b = appendJSONString(b, message, false)
+	if stack := logEntry.GetStackTrace(); f.EnableStackTrace && stack != "" {
+		b = append(b, `,"full_message":`...)
+		start := len(b)
+		b = append(b, message...)
+		if err := logEntry.GetError(); err != nil {
+			b = append(b, "\n\n"...)
+			b = append(b, err.Error()...)
+		}
+		b = append(b, "\n\n"...)
+		b = append(b, stack...)
+		b = quoteJSONTail(b, start)
+	}
+	// Timestamp is seconds since the epoch with millisecond decimals
+	// Timestamp adalah detik sejak epoch dengan desimal milidetik
+	ts := logEntry.GetTimestamp()
+	if ts.IsZero() {
+		// A zero time would be written as a large negative number of seconds
+		// Waktu nol akan ditulis sebagai jumlah detik negatif yang besar
+		ts = time.Now()
+	}
+	b = append(b, `,"timestamp":`...)
+	b = strconv.AppendInt(b, ts.Unix(), 10)
+	ms := ts.Nanosecond() / 1e6
+	b = append(b, '.', byte('0'+ms/100), byte('0'+ms/10%10), byte('0'+ms%10))
+	b = append(b, `,"level":`...)
+	b = strconv.AppendInt(b, int64(syslogSeverity(f.SeverityMap, logEntry.GetLevel())), 10)
+
+	fields := entryFieldPairs(logEntry)
+	b = appendGELFStringIfSet(b, fields, "file", logEntry.GetCallerFile())
+	if line := logEntry.GetCallerLine(); line > 0 && logEntry.GetCallerFile() != "" && !gelfUserField(fields, "line") {
+		b = append(b, `,"_line":`...)
+		b = strconv.AppendInt(b, int64(line), 10)
+	}
+	if pid := logEntry.GetPID(); pid > 0 && !gelfUserField(fields, "pid") {
+		b = append(b, `,"_pid":`...)
+		b = strconv.AppendInt(b, int64(pid), 10)
+	}
+	b = appendGELFStringIfSet(b, fields, "goroutine_id", logEntry.GetGoroutineID())
+	b = appendGELFStringIfSet(b, fields, "trace_id", logEntry.GetTraceID())
+	b = appendGELFStringIfSet(b, fields, "span_id", logEntry.GetSpanID())
+	b = appendGELFStringIfSet(b, fields, "user_id", logEntry.GetUserID())
+	b = appendGELFStringIfSet(b, fields, "session_id", logEntry.GetSessionID())
+	b = appendGELFStringIfSet(b, fields, "request_id", logEntry.GetRequestID())
+	b = appendGELFStringIfSet(b, fields, "application", logEntry.GetApplication())
+	b = appendGELFStringIfSet(b, fields, "version", logEntry.GetVersion())
+	b = appendGELFStringIfSet(b, fields, "environment", logEntry.GetEnvironment())
+	if d := logEntry.GetDuration(); d > 0 && !gelfUserField(fields, "duration_ms") {
+		b = append(b, `,"_duration_ms":`...)
+		b = appendJSONFloat(b, float64(d)/1e6, 64)
+	}
+	if err := logEntry.GetError(); err != nil {
+		b = appendGELFStringIfSet(b, fields, "error", err.Error())
+		b = appendGELFStringIfSet(b, fields, "error_type", errorTypeName(err))
+	}
+	if tags := entryTags(logEntry); len(tags) > 0 && !gelfUserField(fields, "tags") {
+		b = append(b, `,"_tags":`...)
+		start := len(b)
+		for i, tag := range tags {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, tag...)
+		}
+		b = quoteJSONTail(b, start)
+	}
+	for i := range fields {
+		key := bToString(fields[i].Key[:fields[i].KeyLen])
+		if fieldOverridden(fields, i) || key == "" || (!fields[i].IsString && !fields[i].IsInt &&
+			!fields[i].IsFloat64 && !fields[i].IsBool && fields[i].Value == nil) {
+			continue
+		}
+		b = appendGELFKey(b, gelfFieldKey(key))
+		b = appendGELFFieldValue(b, &fields[i])
+	}
+	metrics := entryMetricPairs(logEntry)
+	for i := range metrics {
+		key := bToString(metrics[i].Key[:metrics[i].KeyLen])
+		if gelfUserField(fields, key) {
+			continue
+		}
+		b = appendGELFKey(b, gelfFieldKey(key))
+		b = appendJSONFloat(b, metrics[i].Value, 64)
+	}
+	return append(b, '}')
+}
+
+// gelfFieldKey renames "id" to "id_", because "_id" is reserved by Graylog and rejected by the spec
+// gelfFieldKey mengganti nama "id" menjadi "id_", karena "_id" dicadangkan oleh Graylog dan ditolak oleh spesifikasi
+func gelfFieldKey(key string) string {
+	if key == "id" {
+		return "id_"
+	}
+	return key
+}
+
+// gelfUserField reports whether a user field uses key, in which case it replaces the built-in additional field
+// gelfUserField melaporkan apakah field pengguna menggunakan key, sehingga menggantikan field tambahan bawaan
+func gelfUserField(fields []FieldPair, key string) bool {
+	for i := range fields {
+		if bToString(fields[i].Key[:fields[i].KeyLen]) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// appendGELFKey appends `,"_key":`, replacing characters outside [A-Za-z0-9_.-] with '_'
+// appendGELFKey menambahkan `,"_key":`, mengganti karakter di luar [A-Za-z0-9_.-] dengan '_'
+func appendGELFKey(b []byte, key string) []byte {
+	b = append(b, ',', '"', '_')
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '.' || c == '-') {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return append(b, '"', ':')
+}
+
+// appendGELFStringIfSet appends a built-in additional string field unless it is empty or a user field replaces it
+// appendGELFStringIfSet menambahkan field tambahan string bawaan kecuali kosong atau digantikan field pengguna
+func appendGELFStringIfSet(b []byte, fields []FieldPair, key, value string) []byte {
+	if value == "" || gelfUserField(fields, key) {
+		return b
+	}
+	b = appendGELFKey(b, key)
+	return appendJSONString(b, value, false)
+}
+
+// appendGELFFieldValue appends a field value as a JSON string or number, the only types GELF additional fields allow
+// appendGELFFieldValue menambahkan nilai field sebagai string atau angka JSON, satu-satunya tipe yang diizinkan untuk field tambahan GELF
+func appendGELFFieldValue(b []byte, fp *FieldPair) []byte {
+	start := len(b)
+	b = appendJSONFieldValue(b, fp, false)
+	switch c := b[start]; {
+	case c == '"', c == '-', c >= '0' && c <= '9':
+		return b
+	}
+	// Booleans, arrays and objects are sent as their JSON text
+	// Boolean, array, dan objek dikirim sebagai teks JSON-nya
+	return quoteJSONTail(b, start)
+}
+
+// quoteJSONTail turns the raw text written from start onwards into a JSON string
+// quoteJSONTail mengubah teks mentah yang ditulis mulai dari start menjadi string JSON
+func quoteJSONTail(b []byte, start int) []byte {
+	return appendJSONString(b[:start], string(b[start:]), false)
+}
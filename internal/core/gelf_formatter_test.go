@@ -0,0 +1,151 @@
+package core
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"crystal/internal/outputs"
+)
+
+func TestGELFFormatterFormat(t *testing.T) {
+	entry := newTestEntry(ERROR, "payment failed", "order", "o-1", "amount", 1250, "retried", true,
+		"items", []string{"a", "b"}, "id", "reserved", "bad key", "x")
+	entry.Timestamp = testEntryTime.Add(250 * time.Millisecond)
+	entry.PID = 99
+	entry.Error = errors.New("connection reset")
+	entry.HostnameLen = copy(entry.Hostname[:], "web-1")
+	entry.TraceIDLen = copy(entry.TraceID[:], "abc123")
+	entry.StackTraceLen = copy(entry.StackTrace[:], "main.pay\n\t/app/pay.go:12\n")
+
+	output, err := NewGELFFormatter().Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `{"version":"1.1","host":"web-1","short_message":"payment failed",` +
+		`"full_message":"payment failed\n\nconnection reset\n\nmain.pay\n\t/app/pay.go:12\n",` +
+		`"timestamp":1714564800.250,"level":3,"_pid":99,"_trace_id":"abc123","_error":"connection reset",` +
+		`"_error_type":"*errors.errorString","_order":"o-1","_amount":1250,"_retried":"true","_items":"[\"a\",\"b\"]","_id_":"reserved","_bad_key":"x"}`
+	if string(output) != want {
+		t.Errorf("Unexpected GELF\n got: %s\nwant: %s", output, want)
+	}
+	if !json.Valid(output) {
+		t.Errorf("Expected valid JSON, got %s", output)
+	}
+}
+
+func TestGELFFormatterZeroTimestampAndIDMetric(t *testing.T) {
+	entry := &LogEntry{Level: INFO}
+	entry.SetMetric("id", 7)
+	before := time.Now().Unix()
+	output, err := NewGELFFormatter().Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var message struct {
+		Timestamp float64 `json:"timestamp"`
+		ID        float64 `json:"_id_"`
+	}
+	if err := json.Unmarshal(output, &message); err != nil {
+		t.Fatalf("Unexpected JSON error: %v in %s", err, output)
+	}
+	if int64(message.Timestamp) < before {
+		t.Errorf("Expected a zero timestamp to be replaced by the current time, got %s", output)
+	}
+	if message.ID != 7 {
+		t.Errorf("Expected the id metric to be sent as _id_, got %s", output)
+	}
+}
+
+func TestGELFFormatterCompression(t *testing.T) {
+	for _, compression := range []GELFCompression{GELFCompressionGzip, GELFCompressionZlib} {
+		formatter := &GELFFormatter{Compression: compression}
+		output, err := formatter.Format(newTestEntry(ERROR, "payment failed", "order", "o-1"))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		var r io.Reader
+		if compression == GELFCompressionGzip {
+			r, err = gzip.NewReader(bytes.NewReader(output))
+		} else {
+			r, err = zlib.NewReader(bytes.NewReader(output))
+		}
+		if err != nil {
+			t.Fatalf("Compression %d: unexpected error: %v", compression, err)
+		}
+		plain, err := io.ReadAll(r)
+		if err != nil || !json.Valid(plain) || !strings.Contains(string(plain), `"short_message":"payment failed"`) {
+			t.Errorf("Compression %d: unexpected payload %q (%v)", compression, plain, err)
+		}
+	}
+}
+
+func TestGELFChunkedUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("UDP not available: %v", err)
+	}
+	defer conn.Close()
+
+	out, err := outputs.NewGELFUDPOutput(conn.LocalAddr().String(), 64)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer out.Close()
+
+	formatter := &GELFFormatter{Compression: GELFCompressionGzip}
+	entry := newTestEntry(ERROR, "payment failed", "blob", strings.Repeat("x7Qz", 100))
+	payload, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := out.Write(payload); err != nil {
+		t.Fatalf("Unexpected write error: %v", err)
+	}
+
+	// Reassemble the chunks by sequence number, as Graylog does
+	type chunk struct {
+		seq  byte
+		data []byte
+	}
+	var chunks []chunk
+	var id []byte
+	buf := make([]byte, 128)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for total := -1; total != len(chunks); {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("Failed to read chunk: %v", err)
+		}
+		if n > 64 || buf[0] != 0x1e || buf[1] != 0x0f {
+			t.Fatalf("Unexpected chunk header % x (size %d)", buf[:2], n)
+		}
+		if id == nil {
+			id = append([]byte(nil), buf[2:10]...)
+		} else if !bytes.Equal(id, buf[2:10]) {
+			t.Fatalf("Chunks carry different message IDs")
+		}
+		total = int(buf[11])
+		chunks = append(chunks, chunk{buf[10], append([]byte(nil), buf[12:n]...)})
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].seq < chunks[j].seq })
+	var joined []byte
+	for _, c := range chunks {
+		joined = append(joined, c.data...)
+	}
+	if !bytes.Equal(joined, payload) {
+		t.Errorf("Reassembled payload differs from the formatted message")
+	}
+
+	if _, err := outputs.GELFChunks(make([]byte, 129*52), 64); !errors.Is(err, outputs.ErrGELFTooManyChunks) {
+		t.Errorf("Expected ErrGELFTooManyChunks, got %v", err)
+	}
+}
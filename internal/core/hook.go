@@ -0,0 +1,122 @@
+package core
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Hook is invoked for every log entry whose level is listed in Levels, before the entry is formatted
+// Hook dipanggil untuk setiap entri log yang tingkatnya tercantum dalam Levels, sebelum entri diformat
+//
+// The entry is pooled and reused after logging completes, so Fire must not retain it.
+// Entri diambil dari pool dan digunakan kembali setelah logging selesai, sehingga Fire tidak boleh menyimpannya.
+type Hook interface {
+	// Levels returns the levels this hook fires for
+	// Levels mengembalikan tingkat yang memicu hook ini
+	Levels() []Level
+
+	// Fire processes the entry; a returned error is routed to the logger's ErrorHandler
+	// Fire memproses entri; kesalahan yang dikembalikan diteruskan ke ErrorHandler logger
+	Fire(entry *LogEntry) error
+}
+
+// hookTable maps each level to the hooks registered for it
+// hookTable memetakan setiap tingkat ke hook yang terdaftar untuknya
+type hookTable [8][]Hook
+
+// hookRegistry holds hooks shared by a logger and its children using copy-on-write so firing never takes a lock
+// hookRegistry menyimpan hook yang dibagi oleh logger dan turunannya menggunakan copy-on-write sehingga pemanggilan tidak pernah mengambil kunci
+type hookRegistry struct {
+	mu    sync.Mutex                // Serializes writers - Menserialisasi penulis
+	table atomic.Pointer[hookTable] // Current immutable snapshot - Snapshot immutable saat ini
+}
+
+// newHookRegistry creates an empty hook registry
+// newHookRegistry membuat registry hook kosong
+func newHookRegistry() *hookRegistry {
+	r := &hookRegistry{}
+	r.table.Store(&hookTable{})
+	return r
+}
+
+// forLevel returns the hooks registered for the given level without locking
+// forLevel mengembalikan hook yang terdaftar untuk tingkat yang diberikan tanpa penguncian
+func (r *hookRegistry) forLevel(level Level) []Hook {
+	if int(level) >= len(hookTable{}) {
+		return nil
+	}
+	return r.table.Load()[level]
+}
+
+// add registers a hook for each of its declared levels
+// add mendaftarkan hook untuk setiap tingkat yang dideklarasikannya
+func (r *hookRegistry) add(hook Hook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := r.table.Load()
+	next := *current
+	var seen [len(hookTable{})]bool
+	for _, level := range hook.Levels() {
+		// Listing a level twice must not fire the hook twice
+		// Mencantumkan tingkat dua kali tidak boleh memicu hook dua kali
+		if int(level) >= len(next) || seen[level] {
+			continue
+		}
+		seen[level] = true
+		// Copy the slice so readers holding the previous snapshot are unaffected
+		// Salin slice agar pembaca yang memegang snapshot sebelumnya tidak terpengaruh
+		hooks := make([]Hook, len(next[level]), len(next[level])+1)
+		copy(hooks, next[level])
+		next[level] = append(hooks, hook)
+	}
+	r.table.Store(&next)
+}
+
+// remove unregisters every occurrence of the hook and reports whether it was found
+// remove menghapus setiap kemunculan hook dan melaporkan apakah hook ditemukan
+func (r *hookRegistry) remove(hook Hook) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	current := r.table.Load()
+	var next hookTable
+	found := false
+	for level, hooks := range current {
+		for _, h := range hooks {
+			if h == hook {
+				found = true
+				continue
+			}
+			next[level] = append(next[level], h)
+		}
+	}
+	if found {
+		r.table.Store(&next)
+	}
+	return found
+}
+
+// AddHook registers a hook on the logger; child loggers created with With share the same hooks
+// AddHook mendaftarkan hook pada logger; logger turunan yang dibuat dengan With berbagi hook yang sama
+func (l *Logger) AddHook(hook Hook) {
+	if hook == nil {
+		return
+	}
+	l.hooks.add(hook)
+}
+
+// RemoveHook unregisters a previously added hook and reports whether it was registered
+// RemoveHook menghapus hook yang sebelumnya ditambahkan dan melaporkan apakah hook tersebut terdaftar
+//
+// Hooks are compared with ==, so register pointer types if the hook must be removable; hooks of types that cannot
+// be compared, such as structs holding a slice or map, are never found.
+// Hook dibandingkan dengan ==, jadi daftarkan tipe pointer jika hook harus dapat dihapus; hook dengan tipe yang tidak
+// dapat dibandingkan, seperti struct yang berisi slice atau map, tidak pernah ditemukan.
+func (l *Logger) RemoveHook(hook Hook) bool {
+	// Comparing an uncomparable dynamic type with == panics
+	// Membandingkan tipe dinamis yang tidak dapat dibandingkan dengan == menyebabkan panic
+	if hook == nil || !reflect.TypeOf(hook).Comparable() {
+		return false
+	}
+	return l.hooks.remove(hook)
+}
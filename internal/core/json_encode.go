@@ -0,0 +1,167 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// jsonHex holds the digits used for \u00XX escapes
+// jsonHex berisi digit yang digunakan untuk escape \u00XX
+const jsonHex = "0123456789abcdef"
+
+// appendJSONString appends s as a quoted JSON string, escaping it the same way encoding/json does
+// appendJSONString menambahkan s sebagai string JSON berkutip, meng-escape dengan cara yang sama seperti encoding/json
+func appendJSONString(b []byte, s string, escapeHTML bool) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!escapeHTML || (c != '<' && c != '>' && c != '&')) {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			case '\b':
+				b = append(b, '\\', 'b')
+			case '\f':
+				b = append(b, '\\', 'f')
+			default:
+				// Remaining control characters and HTML-sensitive bytes use \u00XX
+				// Karakter kontrol lainnya dan byte yang sensitif terhadap HTML menggunakan \u00XX
+				b = append(b, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// Replace invalid UTF-8 so the output is always valid JSON
+			// Ganti UTF-8 yang tidak valid agar output selalu berupa JSON yang valid
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			// Line and paragraph separators break JavaScript parsers that embed JSON
+			// Pemisah baris dan paragraf merusak parser JavaScript yang menyematkan JSON
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// appendJSONFloat appends f using the same notation rules as encoding/json, quoting NaN and infinities which JSON cannot represent
+// appendJSONFloat menambahkan f menggunakan aturan notasi yang sama dengan encoding/json, mengutip NaN dan tak hingga yang tidak dapat direpresentasikan JSON
+func appendJSONFloat(b []byte, f float64, bits int) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		b = append(b, '"')
+		b = strconv.AppendFloat(b, f, 'g', -1, bits)
+		return append(b, '"')
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	b = strconv.AppendFloat(b, f, format, -1, bits)
+	if format == 'e' {
+		// Shorten e-07 to e-7 like encoding/json
+		// Persingkat e-07 menjadi e-7 seperti encoding/json
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	return b
+}
+
+// appendJSONFieldValue appends a field value, using the typed zero-allocation slots before falling back to the interface value
+// appendJSONFieldValue menambahkan nilai field, menggunakan slot bertipe zero-allocation sebelum kembali ke nilai interface
+func appendJSONFieldValue(b []byte, fp *FieldPair, escapeHTML bool) []byte {
+	switch {
+	case fp.IsString:
+		return appendJSONString(b, bToString(fp.StringValue[:fp.StringValueLen]), escapeHTML)
+	case fp.IsInt:
+		return strconv.AppendInt(b, fp.IntValue, 10)
+	case fp.IsFloat64:
+		return appendJSONFloat(b, fp.Float64Value, 64)
+	case fp.IsBool:
+		return strconv.AppendBool(b, fp.BoolValue)
+	}
+	return appendJSONValue(b, fp.Value, escapeHTML)
+}
+
+// appendJSONValue appends common value types directly and uses encoding/json reflection only for everything else
+// appendJSONValue menambahkan tipe nilai umum secara langsung dan menggunakan refleksi encoding/json hanya untuk yang lainnya
+func appendJSONValue(b []byte, value interface{}, escapeHTML bool) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJSONString(b, v, escapeHTML)
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int8:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int16:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint8:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint16:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float32:
+		return appendJSONFloat(b, float64(v), 32)
+	case float64:
+		return appendJSONFloat(b, v, 64)
+	case time.Duration:
+		return strconv.AppendInt(b, int64(v), 10)
+	case time.Time:
+		b = append(b, '"')
+		b = v.AppendFormat(b, time.RFC3339Nano)
+		return append(b, '"')
+	case error:
+		// encoding/json would render most error structs as {}, so use the message instead
+		// encoding/json akan menampilkan sebagian besar struct error sebagai {}, jadi gunakan pesannya
+		return appendJSONString(b, v.Error(), escapeHTML)
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return appendJSONString(b, "!ERROR: "+err.Error(), escapeHTML)
+	}
+	return append(b, data...)
+}
@@ -1,9 +1,14 @@
 package core
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"time"
 	"unsafe"
+
+	"crystal/internal/redact"
 )
 
 // JSONFormatter provides JSON formatting for log entries with zero-allocation design
@@ -18,7 +23,20 @@ type JSONFormatter struct {
 	EnableStackTrace    bool   // EnableStackTrace controls whether stack traces are captured for errors
 	EnableDuration      bool   // EnableDuration controls whether duration measurements are included
 	DisableHTMLEscape   bool   // DisableHTMLEscape controls whether HTML characters are escaped in JSON output
-	MaskSensitiveData   bool   // MaskSensitiveData controls whether sensitive data is masked in JSON output
+	MaskSensitiveData   bool   // MaskSensitiveData masks keys matching the default rules when the logger has no Redactor
+
+	// FieldKeyMap renames built-in keys such as "timestamp", "level", "message" or "fields"; mapping to "" omits the key
+	FieldKeyMap map[string]string
+	// OmitKeys lists built-in keys, such as "level_name" or "pid", that are never written
+	OmitKeys []string
+	// FlattenFields writes user fields at the top level instead of under "fields"
+	FlattenFields bool
+	// FieldCollision decides what happens when a flattened user field has the same key as a built-in one
+	FieldCollision FieldCollisionPolicy
+	// ExpandDottedKeys turns keys such as "http.status" into nested objects
+	ExpandDottedKeys bool
+
+	redacted bool // The logger's Redactor has already redacted the fields
 }
 
 // NewJSONFormatter creates a new JSONFormatter with default settings
@@ -38,194 +56,256 @@ func NewJSONFormatter() *JSONFormatter {
 	}
 }
 
-// Format formats a log entry as JSON with minimal allocation using zero-allocation techniques where possible
-// Format memformat entri log sebagai JSON dengan alokasi minimal menggunakan teknik zero-allocation jika memungkinkan
+// Format formats a log entry as JSON by appending directly into a pooled buffer with keys in a stable order
+// Format memformat entri log sebagai JSON dengan menambahkan langsung ke buffer dari pool dengan urutan key yang stabil
 func (f *JSONFormatter) Format(entry interface{}) ([]byte, error) {
 	// Cast entry to LogEntryInterface
 	logEntry, ok := entry.(LogEntryInterface)
 	if !ok {
 		return nil, fmt.Errorf("invalid entry type")
 	}
-	
-	// Create a map to hold the log entry data
-	// Buat map untuk menyimpan data entri log
-	data := make(map[string]interface{}, 32)
-	// Add timestamp with formatting based on configuration
-	// Tambahkan timestamp dengan formatting berdasarkan konfigurasi
-	timestamp := logEntry.GetTimestamp()
-	if f.TimestampFormat != "" {
-		// Use custom timestamp format if specified
-		// Gunakan format timestamp kustom jika ditentukan
-		data["timestamp"] = timestamp.Format(f.TimestampFormat)
-	} else {
-		// Use default timestamp representation
-		// Gunakan representasi timestamp default
-		data["timestamp"] = timestamp
+	// Append into the pooled fixed array; only entries larger than it spill to the heap
+	// Tambahkan ke array tetap dari pool; hanya entri yang lebih besar yang meluap ke heap
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	b := f.appendEntry(buf.buf[:0], logEntry)
+	if f.PrettyPrint {
+		// Indentation is meant for humans, so the extra allocation is acceptable here
+		// Indentasi ditujukan untuk manusia, sehingga alokasi tambahan dapat diterima di sini
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, b, "", "  "); err != nil {
+			return nil, err
+		}
+		pretty.WriteByte('\n')
+		return pretty.Bytes(), nil
+	}
+	b = append(b, '\n')
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, len(b))
+	copy(result, b)
+	return result, nil
+}
+
+// appendEntry encodes the entry as a compact JSON object, applying FieldKeyMap, OmitKeys and the field layout options
+// appendEntry mengkodekan entri sebagai objek JSON ringkas, menerapkan FieldKeyMap, OmitKeys, dan opsi tata letak field
+func (f *JSONFormatter) appendEntry(b []byte, logEntry LogEntryInterface) []byte {
+	e := jsonEnvelope{f: f, html: !f.DisableHTMLEscape}
+	if f.FlattenFields || f.ExpandDottedKeys {
+		// Layout options need every member before writing, so values go to a scratch buffer first
+		// Opsi tata letak memerlukan semua anggota sebelum menulis, sehingga nilai ditulis ke buffer sementara terlebih dahulu
+		scratch := getBufferFromPool()
+		defer putBufferToPool(scratch)
+		e.collect = true
+		e.b = scratch.buf[:0]
+		f.appendMembers(&e, logEntry)
+		container, ok := f.outputKey(jsonKeyFields)
+		if !ok {
+			container = jsonKeyFields
+		}
+		return e.finish(b, container)
+	}
+	e.b = append(b, '{')
+	f.appendMembers(&e, logEntry)
+	return append(e.b, '}')
+}
+
+// appendMembers writes every top-level member of the entry in a stable order
+// appendMembers menulis setiap anggota tingkat atas dari entri dalam urutan yang stabil
+func (f *JSONFormatter) appendMembers(e *jsonEnvelope, logEntry LogEntryInterface) {
+	if e.begin(jsonKeyTimestamp) {
+		e.b = append(e.b, '"')
+		if f.TimestampFormat != "" {
+			e.b = logEntry.GetTimestamp().AppendFormat(e.b, f.TimestampFormat)
+		} else {
+			e.b = logEntry.GetTimestamp().AppendFormat(e.b, time.RFC3339Nano)
+		}
+		e.b = append(e.b, '"')
+		e.end()
 	}
 	// Add log level information
 	// Tambahkan informasi tingkat log
-	level := logEntry.GetLevel()
-	data["level"] = level.String()
-	data["level_name"] = level.String()
-	// Add main log message using zero-allocation byte to string conversion
-	// Tambahkan pesan log utama menggunakan konversi byte ke string zero-allocation
-	data["message"] = logEntry.GetMessage()
-	// Add process metadata
-	// Tambahkan metadata proses
-	data["pid"] = logEntry.GetPID()
+	level := logEntry.GetLevel().String()
+	e.stringMember(jsonKeyLevel, level)
+	e.stringMember(jsonKeyLevelName, level)
+	e.stringMember(jsonKeyMessage, logEntry.GetMessage())
+	if e.begin(jsonKeyPID) {
+		e.b = strconv.AppendInt(e.b, int64(logEntry.GetPID()), 10)
+		e.end()
+	}
 	// Add caller information if enabled and available
 	// Tambahkan informasi pemanggil jika diaktifkan dan tersedia
-	if f.ShowCaller && logEntry.GetCallerFile() != "" {
-		// Create nested caller object with caller details
-		// Buat objek pemanggil bersarang dengan detail pemanggil
-		caller := make(map[string]interface{}, 4)
-		caller["file"] = logEntry.GetCallerFile()
-		caller["line"] = logEntry.GetCallerLine()
-		// Note: We don't have function and package information in the interface
-		data["caller"] = caller
-	}
-	// Add goroutine ID if enabled and available using zero-allocation conversion
-	// Tambahkan ID goroutine jika diaktifkan dan tersedia menggunakan konversi zero-allocation
-	if f.ShowGoroutine && logEntry.GetGoroutineID() != "" {
-		data["goroutine_id"] = logEntry.GetGoroutineID()
+	if f.ShowCaller && logEntry.GetCallerFile() != "" && e.begin(jsonKeyCaller) {
+		e.b = append(e.b, `{"file":`...)
+		e.b = appendJSONString(e.b, logEntry.GetCallerFile(), e.html)
+		e.b = append(e.b, `,"line":`...)
+		e.b = strconv.AppendInt(e.b, int64(logEntry.GetCallerLine()), 10)
+		e.b = append(e.b, '}')
+		e.end()
+	}
+	if f.ShowGoroutine {
+		e.stringMemberIfSet(jsonKeyGoroutineID, logEntry.GetGoroutineID())
 	}
 	// Add distributed tracing information if enabled
 	// Tambahkan informasi tracing terdistribusi jika diaktifkan
 	if f.ShowTraceInfo {
-		if logEntry.GetTraceID() != "" {
-			data["trace_id"] = logEntry.GetTraceID()
-		}
-		if logEntry.GetSpanID() != "" {
-			data["span_id"] = logEntry.GetSpanID()
-		}
-		if logEntry.GetUserID() != "" {
-			data["user_id"] = logEntry.GetUserID()
-		}
-		if logEntry.GetSessionID() != "" {
-			data["session_id"] = logEntry.GetSessionID()
-		}
-		if logEntry.GetRequestID() != "" {
-			data["request_id"] = logEntry.GetRequestID()
-		}
+		e.stringMemberIfSet(jsonKeyTraceID, logEntry.GetTraceID())
+		e.stringMemberIfSet(jsonKeySpanID, logEntry.GetSpanID())
+		e.stringMemberIfSet(jsonKeyUserID, logEntry.GetUserID())
+		e.stringMemberIfSet(jsonKeySessionID, logEntry.GetSessionID())
+		e.stringMemberIfSet(jsonKeyRequestID, logEntry.GetRequestID())
 	}
-	// Add duration measurement if enabled and available
-	// Tambahkan pengukuran durasi jika diaktifkan dan tersedia
-	if f.EnableDuration {
-		duration := logEntry.GetDuration()
-		if duration > 0 {
-			data["duration"] = duration.String()
-		}
+	if f.EnableDuration && logEntry.GetDuration() > 0 && e.begin(jsonKeyDuration) {
+		e.b = append(e.b, '"')
+		e.b = append(e.b, logEntry.GetDuration().String()...)
+		e.b = append(e.b, '"')
+		e.end()
 	}
-	// Add stack trace if enabled and available using zero-allocation conversion
-	// Tambahkan stack trace jika diaktifkan dan tersedia menggunakan konversi zero-allocation
-	if f.EnableStackTrace && logEntry.GetStackTrace() != "" {
-		data["stack_trace"] = logEntry.GetStackTrace()
-	}
-	// Add hostname if available using zero-allocation conversion
-	// Tambahkan hostname jika tersedia menggunakan konversi zero-allocation
-	if logEntry.GetHostname() != "" {
-		data["hostname"] = logEntry.GetHostname()
-	}
-	// Add application name if available using zero-allocation conversion
-	// Tambahkan nama aplikasi jika tersedia menggunakan konversi zero-allocation
-	if logEntry.GetApplication() != "" {
-		data["application"] = logEntry.GetApplication()
-	}
-	// Add version information if available using zero-allocation conversion
-	// Tambahkan informasi versi jika tersedia menggunakan konversi zero-allocation
-	if logEntry.GetVersion() != "" {
-		data["version"] = logEntry.GetVersion()
-	}
-	// Add environment information if available using zero-allocation conversion
-	// Tambahkan informasi lingkungan jika tersedia menggunakan konversi zero-allocation
-	if logEntry.GetEnvironment() != "" {
-		data["environment"] = logEntry.GetEnvironment()
-	}
-	// Add structured fields if present
-	// Tambahkan field terstruktur jika ada
-	fields := logEntry.GetFields()
-	if len(fields) > 0 {
-		// Pre-allocate fields map with known size to avoid reallocations
-		// Pra-alokasi map field dengan ukuran yang diketahui untuk menghindari realokasi
-		fieldMap := make(map[string]interface{}, len(fields))
-		for _, field := range fields {
-			// Convert field key from bytes to string using zero-allocation technique
-			// Konversi key field dari byte ke string menggunakan teknik zero-allocation
-			if fp, ok := field.(FieldPair); ok {
-				key := jsonBToString(fp.Key[:fp.KeyLen])
-				fieldMap[key] = fp.Value
+	e.stringMemberIfSet(jsonKeyHostname, logEntry.GetHostname())
+	e.stringMemberIfSet(jsonKeyApplication, logEntry.GetApplication())
+	e.stringMemberIfSet(jsonKeyVersion, logEntry.GetVersion())
+	e.stringMemberIfSet(jsonKeyEnvironment, logEntry.GetEnvironment())
+	f.appendFields(e, entryFieldPairs(logEntry))
+	if tags := entryTags(logEntry); len(tags) > 0 && e.begin(jsonKeyTags) {
+		e.b = append(e.b, '[')
+		for i, tag := range tags {
+			if i > 0 {
+				e.b = append(e.b, ',')
 			}
+			e.b = appendJSONString(e.b, tag, e.html)
 		}
-		data["fields"] = fieldMap
-	}
-	// Add tags if present
-	// Tambahkan tag jika ada
-	tags := logEntry.GetTags()
-	if len(tags) > 0 {
-		data["tags"] = tags
-	}
-	// Add custom metrics if present
-	// Tambahkan metrik kustom jika ada
-	metrics := logEntry.GetMetrics()
-	if len(metrics) > 0 {
-		// Pre-allocate metrics map with known size to avoid reallocations
-		// Pra-alokasi map metrik dengan ukuran yang diketahui untuk menghindari realokasi
-		metricMap := make(map[string]float64, len(metrics))
-		for _, metric := range metrics {
-			// Convert metric key from bytes to string using zero-allocation technique
-			// Konversi key metrik dari byte ke string menggunakan teknik zero-allocation
-			if mp, ok := metric.(MetricPair); ok {
-				key := jsonBToString(mp.Key[:mp.KeyLen])
-				metricMap[key] = mp.Value
+		e.b = append(e.b, ']')
+		e.end()
+	}
+	if metrics := entryMetricPairs(logEntry); len(metrics) > 0 && e.begin(jsonKeyCustomMetrics) {
+		e.b = append(e.b, '{')
+		for i := range metrics {
+			if i > 0 {
+				e.b = append(e.b, ',')
 			}
+			e.b = appendJSONString(e.b, bToString(metrics[i].Key[:metrics[i].KeyLen]), e.html)
+			e.b = append(e.b, ':')
+			e.b = appendJSONFloat(e.b, metrics[i].Value, 64)
 		}
-		data["custom_metrics"] = metricMap
+		e.b = append(e.b, '}')
+		e.end()
 	}
-	// Add error information if present
-	// Tambahkan informasi kesalahan jika ada
+	// Add error information, reporting wrapped errors individually so the root cause is searchable
+	// Tambahkan informasi kesalahan, melaporkan error terbungkus satu per satu agar penyebab utama dapat dicari
 	if err := logEntry.GetError(); err != nil {
-		data["error"] = err.Error()
+		e.stringMember(jsonKeyError, err.Error())
+		e.stringMember(jsonKeyErrorType, errorTypeName(err))
+		if chain := errorChain(err); len(chain) > 0 && e.begin(jsonKeyErrorChain) {
+			e.b = append(e.b, '[')
+			for i, wrapped := range chain {
+				if i > 0 {
+					e.b = append(e.b, ',')
+				}
+				e.b = append(e.b, `{"type":`...)
+				e.b = appendJSONString(e.b, errorTypeName(wrapped), e.html)
+				e.b = append(e.b, `,"message":`...)
+				e.b = appendJSONString(e.b, wrapped.Error(), e.html)
+				e.b = append(e.b, '}')
+			}
+			e.b = append(e.b, ']')
+			e.end()
+		}
 	}
-	// Get a buffer from pool to avoid allocation and enable reuse
-	// Dapatkan buffer dari pool untuk menghindari alokasi dan memungkinkan penggunaan kembali
-	buf := getBufferFromPool()
-	defer putBufferToPool(buf)
-	// Create JSON encoder with buffer as output destination
-	// Buat encoder JSON dengan buffer sebagai tujuan output
-	encoder := json.NewEncoder(buf)
-	if f.DisableHTMLEscape {
-		// Disable HTML escaping for better performance when HTML content is not a concern
-		// Nonaktifkan escaping HTML untuk kinerja yang lebih baik ketika konten HTML bukan masalah
-		encoder.SetEscapeHTML(false)
-	}
-	var err error
-	// Format output based on pretty print setting
-	// Format output berdasarkan pengaturan pretty print
-	if f.PrettyPrint {
-		// Use indented formatting for human-readable output
-		// Gunakan formatting dengan indentasi untuk output yang dapat dibaca manusia
-		dataBytes, err := json.MarshalIndent(data, "", "  ")
-		if err != nil {
-			return nil, err
+	// Emit frames as objects so log pipelines can index them without parsing text
+	// Keluarkan frame sebagai objek agar pipeline log dapat mengindeksnya tanpa mengurai teks
+	if f.EnableStackTrace && logEntry.GetStackTrace() != "" && e.begin(jsonKeyStackTrace) {
+		e.b = append(e.b, '[')
+		first := true
+		forEachStackFrame(logEntry.GetStackTrace(), 0, func(function, file string, line int) bool {
+			if !first {
+				e.b = append(e.b, ',')
+			}
+			first = false
+			e.b = append(e.b, `{"function":`...)
+			e.b = appendJSONString(e.b, function, e.html)
+			e.b = append(e.b, `,"file":`...)
+			e.b = appendJSONString(e.b, file, e.html)
+			e.b = append(e.b, `,"line":`...)
+			e.b = strconv.AppendInt(e.b, int64(line), 10)
+			e.b = append(e.b, '}')
+			return true
+		})
+		e.b = append(e.b, ']')
+		e.end()
+	}
+}
+
+// appendFields writes user fields nested under the fields key, or as top-level members when flattening or expanding dotted keys
+// appendFields menulis field pengguna bersarang di bawah key fields, atau sebagai anggota tingkat atas saat meratakan atau memperluas key bertitik
+func (f *JSONFormatter) appendFields(e *jsonEnvelope, fields []FieldPair) {
+	if len(fields) == 0 {
+		return
+	}
+	container, ok := f.outputKey(jsonKeyFields)
+	if !ok && !f.FlattenFields {
+		return
+	}
+	if e.collect {
+		for i := range fields {
+			if fieldOverridden(fields, i) {
+				continue
+			}
+			key := bToString(fields[i].Key[:fields[i].KeyLen])
+			if !f.FlattenFields {
+				// Dotted expansion nests "container.key" back under the container
+				// Ekspansi bertitik menyarangkan "container.key" kembali di bawah container
+				key = container + "." + key
+			}
+			e.beginUser(key)
+			e.b = f.appendFieldValue(e.b, &fields[i], e.html)
+			e.end()
 		}
-		// Create a copy to avoid buffer reuse issues
-		// Buat salinan untuk menghindari masalah penggunaan kembali buffer
-		result := make([]byte, len(dataBytes))
-		copy(result, dataBytes)
-		return result, nil
-	} else {
-		// Use compact formatting for machine-readable output
-		// Gunakan formatting ringkas untuk output yang dapat dibaca mesin
-		err = encoder.Encode(data)
-		if err != nil {
-			return nil, err
+		return
+	}
+	e.beginKey(container)
+	e.b = append(e.b, '{')
+	first := true
+	for i := range fields {
+		if fieldOverridden(fields, i) {
+			continue
 		}
+		if !first {
+			e.b = append(e.b, ',')
+		}
+		first = false
+		e.b = appendJSONString(e.b, bToString(fields[i].Key[:fields[i].KeyLen]), e.html)
+		e.b = append(e.b, ':')
+		e.b = f.appendFieldValue(e.b, &fields[i], e.html)
 	}
-	// Create a copy to avoid buffer reuse issues and ensure memory safety
-	// Buat salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
-	result := make([]byte, buf.Len())
-	copy(result, buf.Bytes())
-	return result, nil
+	e.b = append(e.b, '}')
+	e.end()
+}
+
+// setRedactor records whether the logger redacts fields before they are formatted
+// setRedactor mencatat apakah logger meredaksi field sebelum diformat
+func (f *JSONFormatter) setRedactor(r *redact.Redactor) {
+	f.redacted = r != nil
+}
+
+// appendFieldValue appends a user field value, masked as "***" when MaskSensitiveData is set and the key is sensitive
+// appendFieldValue menambahkan nilai field pengguna, disamarkan sebagai "***" jika MaskSensitiveData diatur dan key bersifat sensitif
+func (f *JSONFormatter) appendFieldValue(b []byte, fp *FieldPair, html bool) []byte {
+	if masksKey(f.MaskSensitiveData, f.redacted, bToString(fp.Key[:fp.KeyLen])) {
+		return appendJSONString(b, redact.DefaultMask, html)
+	}
+	return appendJSONFieldValue(b, fp, html)
+}
+
+// fieldOverridden reports whether a later field reuses the key of fields[i], matching map semantics without allocating
+// fieldOverridden melaporkan apakah field berikutnya menggunakan ulang key dari fields[i], sesuai semantik map tanpa alokasi
+func fieldOverridden(fields []FieldPair, i int) bool {
+	key := fields[i].Key[:fields[i].KeyLen]
+	for j := i + 1; j < len(fields); j++ {
+		if bytes.Equal(fields[j].Key[:fields[j].KeyLen], key) {
+			return true
+		}
+	}
+	return false
 }
 
 // Unsafe string/byte conversions for zero allocation using unsafe package to avoid memory copying
@@ -1,6 +1,11 @@
 package core
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"strings"
 	"testing"
 	"time"
@@ -103,4 +108,169 @@ func TestJSONFormatterWithFields(t *testing.T) {
 	if !strings.Contains(outputStr, "ip_address") || !strings.Contains(outputStr, "192.168.1.1") {
 		t.Error("Expected ip_address field in JSON output")
 	}
-}
\ No newline at end of file
+}
+
+func TestJSONFormatterMatchesEncodingJSON(t *testing.T) {
+	strs := []string{
+		"plain",
+		"quote \" and backslash \\",
+		"control \x00\x01\b\f\n\r\t",
+		"<script>&</script>",
+		"unicode \u00e9\u4e16\U0001F600",
+		"separators \u2028\u2029",
+		"invalid \xff\xfe utf8",
+	}
+	for _, s := range strs {
+		want, _ := json.Marshal(s)
+		if got := appendJSONString(nil, s, true); !bytes.Equal(got, want) {
+			t.Errorf("appendJSONString(%q) = %s, want %s", s, got, want)
+		}
+	}
+	floats := []float64{0, 1, -1.5, 0.1, 1e-7, 123456789.125, 1e21, 1e20, -2.5e-10, math.MaxFloat64}
+	for _, f := range floats {
+		want, _ := json.Marshal(f)
+		if got := appendJSONFloat(nil, f, 64); !bytes.Equal(got, want) {
+			t.Errorf("appendJSONFloat(%v) = %s, want %s", f, got, want)
+		}
+	}
+}
+
+func TestJSONFormatterTypedFieldsAndOrder(t *testing.T) {
+	formatter := &JSONFormatter{TimestampFormat: time.RFC3339, DisableHTMLEscape: true}
+
+	entry := &LogEntry{
+		Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:     WARN,
+		PID:       7,
+	}
+	entry.MessageLen = copy(entry.Message[:], "quota <exceeded>")
+	entry.SetStringField("user", "alice")
+	entry.SetIntField("count", 3)
+	entry.SetFloat64Field("ratio", 0.75)
+	entry.SetBoolField("retry", true)
+	entry.SetField("tags", []string{"a", "b"})
+	entry.SetField("nothing", nil)
+	entry.SetStringField("user", "bob")
+	entry.SetTag("billing")
+	entry.SetMetric("latency_ms", 12.5)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `{"timestamp":"2024-05-01T12:00:00Z","level":"WARN","level_name":"WARN","message":"quota <exceeded>","pid":7,` +
+		`"fields":{"count":3,"ratio":0.75,"retry":true,"tags":["a","b"],"nothing":null,"user":"bob"},` +
+		`"tags":["billing"],"custom_metrics":{"latency_ms":12.5}}` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected JSON\n got: %s\nwant: %s", output, want)
+	}
+	if !json.Valid(output) {
+		t.Errorf("Expected valid JSON, got %s", output)
+	}
+}
+
+// layoutTestFields are the fields of the "login" entry the key layout tests format
+var layoutTestFields = []interface{}{"event.category", "authentication", "event.outcome", "success", "message", "from user", "port", 8080}
+
+func TestJSONFormatterFieldKeyMapAndOmitKeys(t *testing.T) {
+	formatter := &JSONFormatter{
+		TimestampFormat: time.RFC3339,
+		FieldKeyMap: map[string]string{
+			"timestamp": "@timestamp",
+			"level":     "log.level",
+			"message":   "msg",
+			"Fields":    "attributes",
+			"pid":       "",
+		},
+		OmitKeys: []string{"level_name"},
+	}
+
+	output, err := formatter.Format(newTestEntry(INFO, "login", layoutTestFields...))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `{"@timestamp":"2024-05-01T12:00:00Z","log.level":"INFO","msg":"login",` +
+		`"attributes":{"event.category":"authentication","event.outcome":"success","message":"from user","port":8080}}` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected JSON\n got: %s\nwant: %s", output, want)
+	}
+}
+
+func TestJSONFormatterFlattenFields(t *testing.T) {
+	tests := []struct {
+		policy FieldCollisionPolicy
+		want   string
+	}{
+		{FieldCollisionPrefix, `{"level":"INFO","message":"login","event.category":"authentication","event.outcome":"success","fields.message":"from user","port":8080}`},
+		{FieldCollisionOverwrite, `{"level":"INFO","event.category":"authentication","event.outcome":"success","message":"from user","port":8080}`},
+		{FieldCollisionSkip, `{"level":"INFO","message":"login","event.category":"authentication","event.outcome":"success","port":8080}`},
+	}
+
+	for _, tt := range tests {
+		formatter := &JSONFormatter{
+			OmitKeys:       []string{"timestamp", "level_name", "pid"},
+			FlattenFields:  true,
+			FieldCollision: tt.policy,
+		}
+		output, err := formatter.Format(newTestEntry(INFO, "login", layoutTestFields...))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := strings.TrimSuffix(string(output), "\n"); got != tt.want {
+			t.Errorf("Policy %d: unexpected JSON\n got: %s\nwant: %s", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestJSONFormatterExpandDottedKeys(t *testing.T) {
+	formatter := &JSONFormatter{
+		FieldKeyMap:      map[string]string{"level": "log.level", "timestamp": "", "pid": ""},
+		OmitKeys:         []string{"level_name"},
+		FlattenFields:    true,
+		ExpandDottedKeys: true,
+	}
+	entry := newTestEntry(INFO, "login", layoutTestFields...)
+	entry.Error = fmt.Errorf("wrapped: %w", errors.New("denied"))
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// error.type stays flat because "error" already holds the message
+	want := `{"log":{"level":"INFO"},"message":"login","event":{"category":"authentication","outcome":"success"},` +
+		`"fields":{"message":"from user"},"port":8080,"error":"wrapped: denied","error.type":"*fmt.wrapError",` +
+		`"error.chain":[{"type":"*errors.errorString","message":"denied"}]}` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected JSON\n got: %s\nwant: %s", output, want)
+	}
+
+	// Without flattening, dotted user keys nest inside the fields container
+	formatter.FlattenFields = false
+	output, err = formatter.Format(newTestEntry(INFO, "login", layoutTestFields...))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want = `{"log":{"level":"INFO"},"message":"login",` +
+		`"fields":{"event":{"category":"authentication","outcome":"success"},"message":"from user","port":8080}}` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected JSON\n got: %s\nwant: %s", output, want)
+	}
+}
+
+func BenchmarkJSONFormatterFormat(b *testing.B) {
+	formatter := NewJSONFormatter()
+	entry := &LogEntry{Timestamp: time.Now(), Level: INFO, PID: 1234}
+	entry.MessageLen = copy(entry.Message[:], "user action")
+	entry.SetStringField("user_id", "12345")
+	entry.SetIntField("status", 200)
+	entry.SetBoolField("success", true)
+	entry.SetFloat64Field("rate", 0.95)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := formatter.Format(entry); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
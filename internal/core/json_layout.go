@@ -0,0 +1,250 @@
+package core
+
+import "strings"
+
+// Built-in JSON keys, usable in JSONFormatter.FieldKeyMap and JSONFormatter.OmitKeys
+// Key JSON bawaan, dapat digunakan dalam JSONFormatter.FieldKeyMap dan JSONFormatter.OmitKeys
+const (
+	jsonKeyTimestamp     = "timestamp"
+	jsonKeyLevel         = "level"
+	jsonKeyLevelName     = "level_name"
+	jsonKeyMessage       = "message"
+	jsonKeyPID           = "pid"
+	jsonKeyCaller        = "caller"
+	jsonKeyGoroutineID   = "goroutine_id"
+	jsonKeyTraceID       = "trace_id"
+	jsonKeySpanID        = "span_id"
+	jsonKeyUserID        = "user_id"
+	jsonKeySessionID     = "session_id"
+	jsonKeyRequestID     = "request_id"
+	jsonKeyDuration      = "duration"
+	jsonKeyHostname      = "hostname"
+	jsonKeyApplication   = "application"
+	jsonKeyVersion       = "version"
+	jsonKeyEnvironment   = "environment"
+	jsonKeyFields        = "fields"
+	jsonKeyTags          = "tags"
+	jsonKeyCustomMetrics = "custom_metrics"
+	jsonKeyError         = "error"
+	jsonKeyErrorType     = "error.type"
+	jsonKeyErrorChain    = "error.chain"
+	jsonKeyStackTrace    = "stack_trace"
+)
+
+// jsonKeyFieldsAlias is the capitalized spelling of the fields container accepted in FieldKeyMap
+// jsonKeyFieldsAlias adalah ejaan berhuruf kapital dari container fields yang diterima dalam FieldKeyMap
+const jsonKeyFieldsAlias = "Fields"
+
+// FieldCollisionPolicy decides what happens when a flattened user field has the same key as a built-in key
+// FieldCollisionPolicy menentukan apa yang terjadi jika field pengguna yang diratakan memiliki key yang sama dengan key bawaan
+type FieldCollisionPolicy int
+
+const (
+	// FieldCollisionPrefix keeps both, writing the user field under "fields.<key>" (or the remapped container name)
+	// FieldCollisionPrefix mempertahankan keduanya, menulis field pengguna di bawah "fields.<key>" (atau nama container yang dipetakan ulang)
+	FieldCollisionPrefix FieldCollisionPolicy = iota
+	// FieldCollisionOverwrite lets the user field replace the built-in value
+	// FieldCollisionOverwrite membiarkan field pengguna menggantikan nilai bawaan
+	FieldCollisionOverwrite
+	// FieldCollisionSkip drops the user field and keeps the built-in value
+	// FieldCollisionSkip membuang field pengguna dan mempertahankan nilai bawaan
+	FieldCollisionSkip
+)
+
+// outputKey returns the key written for a built-in key, or false when it is omitted
+// outputKey mengembalikan key yang ditulis untuk key bawaan, atau false jika dihilangkan
+func (f *JSONFormatter) outputKey(builtin string) (string, bool) {
+	for _, omitted := range f.OmitKeys {
+		if omitted == builtin {
+			return "", false
+		}
+	}
+	if key, ok := f.FieldKeyMap[builtin]; ok {
+		return key, key != ""
+	}
+	if builtin == jsonKeyFields {
+		if key, ok := f.FieldKeyMap[jsonKeyFieldsAlias]; ok {
+			return key, key != ""
+		}
+	}
+	return builtin, true
+}
+
+// jsonMember records one top-level key and the location of its encoded value in the scratch buffer
+// jsonMember mencatat satu key tingkat atas dan lokasi nilai yang telah dikodekan dalam buffer sementara
+type jsonMember struct {
+	key     string // Output key, possibly dotted - Key output, mungkin bertitik
+	start   int    // Value start offset - Offset awal nilai
+	end     int    // Value end offset - Offset akhir nilai
+	user    bool   // Member comes from a user field - Anggota berasal dari field pengguna
+	dropped bool   // Member lost a key collision - Anggota kalah dalam tabrakan key
+	done    bool   // Member has been written - Anggota sudah ditulis
+}
+
+// jsonEnvelope writes the top-level members of an entry, streaming them directly or collecting them for the layout options
+// jsonEnvelope menulis anggota tingkat atas dari entri, mengalirkannya langsung atau mengumpulkannya untuk opsi tata letak
+type jsonEnvelope struct {
+	f       *JSONFormatter
+	b       []byte // Output in streaming mode, value scratch in collect mode - Output dalam mode streaming, tempat nilai dalam mode collect
+	html    bool
+	collect bool
+	count   int
+	members []jsonMember
+	// keywordPrefix makes members under it JSON strings, as ECS labels require - Membuat anggota di bawahnya menjadi string JSON, seperti yang diwajibkan label ECS
+	keywordPrefix string
+}
+
+// begin starts a built-in member and reports whether it should be written
+// begin memulai anggota bawaan dan melaporkan apakah anggota tersebut harus ditulis
+func (e *jsonEnvelope) begin(builtin string) bool {
+	key, ok := e.f.outputKey(builtin)
+	if !ok {
+		return false
+	}
+	e.beginKey(key)
+	return true
+}
+
+// beginKey starts a built-in member with an already resolved key
+// beginKey memulai anggota bawaan dengan key yang sudah ditentukan
+func (e *jsonEnvelope) beginKey(key string) {
+	if e.collect {
+		e.members = append(e.members, jsonMember{key: key, start: len(e.b)})
+		return
+	}
+	if e.count > 0 {
+		e.b = append(e.b, ',')
+	}
+	e.count++
+	e.b = appendJSONString(e.b, key, e.html)
+	e.b = append(e.b, ':')
+}
+
+// beginUser starts a top-level member for a user field; only used in collect mode
+// beginUser memulai anggota tingkat atas untuk field pengguna; hanya digunakan dalam mode collect
+func (e *jsonEnvelope) beginUser(key string) {
+	e.members = append(e.members, jsonMember{key: key, start: len(e.b), user: true})
+}
+
+// end closes the member started last
+// end menutup anggota yang terakhir dimulai
+func (e *jsonEnvelope) end() {
+	if e.collect {
+		e.members[len(e.members)-1].end = len(e.b)
+	}
+}
+
+// stringMember writes a built-in string member
+// stringMember menulis anggota string bawaan
+func (e *jsonEnvelope) stringMember(builtin, value string) {
+	if e.begin(builtin) {
+		e.b = appendJSONString(e.b, value, e.html)
+		e.end()
+	}
+}
+
+// stringMemberIfSet writes a built-in string member only when value is non-empty
+// stringMemberIfSet menulis anggota string bawaan hanya jika nilai tidak kosong
+func (e *jsonEnvelope) stringMemberIfSet(builtin, value string) {
+	if value != "" {
+		e.stringMember(builtin, value)
+	}
+}
+
+// finish resolves key collisions between user fields and built-in keys, prefixing losing user keys with container, and writes the collected members to out
+// finish menyelesaikan tabrakan key antara field pengguna dan key bawaan, memberi prefix container pada key pengguna yang kalah, lalu menulis anggota yang terkumpul ke out
+//
+// A user key that is a dotted prefix of a built-in key, such as "error" next to "error.message", or the reverse, would map one
+// path to both a value and an object, so it is always moved under container whatever the policy.
+// Key pengguna yang merupakan prefix bertitik dari key bawaan, seperti "error" di samping "error.message", atau sebaliknya, akan
+// memetakan satu path ke nilai sekaligus objek, sehingga selalu dipindahkan ke bawah container apa pun kebijakannya.
+func (e *jsonEnvelope) finish(out []byte, container string) []byte {
+	for i := range e.members {
+		user := &e.members[i]
+		if !user.user {
+			continue
+		}
+	collisions:
+		for j := range e.members {
+			builtin := &e.members[j]
+			if builtin.user || builtin.dropped {
+				continue
+			}
+			if builtin.key != user.key {
+				if dottedPrefix(builtin.key, user.key) || dottedPrefix(user.key, builtin.key) {
+					user.key = container + "." + user.key
+					break collisions
+				}
+				continue
+			}
+			switch e.f.FieldCollision {
+			case FieldCollisionOverwrite:
+				builtin.dropped = true
+			case FieldCollisionSkip:
+				user.dropped = true
+			default:
+				user.key = container + "." + user.key
+			}
+			break collisions
+		}
+	}
+	out = append(out, '{')
+	out = e.appendObject(out, "", false)
+	return append(out, '}')
+}
+
+// appendObject writes every pending member whose key starts with prefix, nesting dotted keys when ExpandDottedKeys is set
+// appendObject menulis setiap anggota tertunda yang key-nya diawali prefix, menyarangkan key bertitik jika ExpandDottedKeys diatur
+//
+// A dotted key stays flat when a plain member already uses its first segment, so no value is ever lost.
+// Key bertitik tetap datar jika anggota biasa sudah menggunakan segmen pertamanya, sehingga tidak ada nilai yang hilang.
+func (e *jsonEnvelope) appendObject(out []byte, prefix string, comma bool) []byte {
+	for i := range e.members {
+		m := &e.members[i]
+		if m.done || m.dropped || !strings.HasPrefix(m.key, prefix) {
+			continue
+		}
+		rest := m.key[len(prefix):]
+		if comma {
+			out = append(out, ',')
+		}
+		comma = true
+		head, tail, dotted := strings.Cut(rest, ".")
+		if e.f.ExpandDottedKeys && dotted && head != "" && tail != "" && !e.hasLeaf(prefix+head) {
+			out = appendJSONString(out, head, e.html)
+			out = append(out, ':', '{')
+			out = e.appendObject(out, prefix+head+".", false)
+			out = append(out, '}')
+			continue
+		}
+		m.done = true
+		out = appendJSONString(out, rest, e.html)
+		out = append(out, ':')
+		raw := e.b[m.start:m.end]
+		if e.keywordPrefix != "" && strings.HasPrefix(m.key, e.keywordPrefix) && len(raw) > 0 && raw[0] != '"' {
+			// Numbers, booleans and composite values are kept as their JSON text
+			// Angka, boolean, dan nilai komposit disimpan sebagai teks JSON-nya
+			out = appendJSONString(out, bToString(raw), e.html)
+			continue
+		}
+		out = append(out, raw...)
+	}
+	return out
+}
+
+// dottedPrefix reports whether key continues prefix with a dot, as "error.message" does "error"
+// dottedPrefix melaporkan apakah key melanjutkan prefix dengan titik, seperti "error.message" terhadap "error"
+func dottedPrefix(key, prefix string) bool {
+	return len(key) > len(prefix) && key[len(prefix)] == '.' && strings.HasPrefix(key, prefix)
+}
+
+// hasLeaf reports whether a written or pending member uses exactly key
+// hasLeaf melaporkan apakah ada anggota yang sudah ditulis atau tertunda yang menggunakan tepat key tersebut
+func (e *jsonEnvelope) hasLeaf(key string) bool {
+	for i := range e.members {
+		if m := &e.members[i]; !m.dropped && m.key == key {
+			return true
+		}
+	}
+	return false
+}
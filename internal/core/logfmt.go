@@ -0,0 +1,272 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// LogfmtPair is one key/value pair of a logfmt line
+// LogfmtPair adalah satu pasangan key/value dari baris logfmt
+type LogfmtPair struct {
+	Key   string
+	Value string
+}
+
+// appendLogfmtKey appends a key, replacing bytes logfmt does not allow in keys with '_'
+// appendLogfmtKey menambahkan key, mengganti byte yang tidak diizinkan logfmt dalam key dengan '_'
+func appendLogfmtKey(b []byte, key string) []byte {
+	for i := 0; i < len(key); {
+		c := key[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+				c = '_'
+			}
+			b = append(b, c)
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(key[i:])
+		if r == utf8.RuneError {
+			b = append(b, '_')
+		} else {
+			b = append(b, key[i:i+size]...)
+		}
+		i += size
+	}
+	return b
+}
+
+// logfmtNeedsQuote reports whether a value must be quoted to be read back unchanged
+// logfmtNeedsQuote melaporkan apakah nilai harus dikutip agar dapat dibaca kembali tanpa perubahan
+func logfmtNeedsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// appendLogfmtString appends a value, quoting and escaping it only when needed
+// appendLogfmtString menambahkan nilai, mengutip dan meng-escape hanya jika diperlukan
+func appendLogfmtString(b []byte, s string) []byte {
+	if !logfmtNeedsQuote(s) {
+		return append(b, s...)
+	}
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= ' ' && c != '"' && c != '\\' && c != 0x7f {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			// Invalid UTF-8 is replaced so every line stays valid text
+			// UTF-8 yang tidak valid diganti agar setiap baris tetap berupa teks yang valid
+			b = append(b, s[start:i]...)
+			b = append(b, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+// appendLogfmtFieldValue appends a field value, using the typed zero-allocation slots before the interface value
+// appendLogfmtFieldValue menambahkan nilai field, menggunakan slot bertipe zero-allocation sebelum nilai interface
+func appendLogfmtFieldValue(b []byte, fp *FieldPair) []byte {
+	switch {
+	case fp.IsString:
+		return appendLogfmtString(b, bToString(fp.StringValue[:fp.StringValueLen]))
+	case fp.IsInt:
+		return strconv.AppendInt(b, fp.IntValue, 10)
+	case fp.IsFloat64:
+		return strconv.AppendFloat(b, fp.Float64Value, 'g', -1, 64)
+	case fp.IsBool:
+		return strconv.AppendBool(b, fp.BoolValue)
+	}
+	return appendLogfmtValue(b, fp.Value)
+}
+
+// appendLogfmtValue appends common value types directly and formats everything else with fmt
+// appendLogfmtValue menambahkan tipe nilai umum secara langsung dan memformat sisanya dengan fmt
+func appendLogfmtValue(b []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendLogfmtString(b, v)
+	case []byte:
+		return appendLogfmtString(b, bToString(v))
+	case bool:
+		return strconv.AppendBool(b, v)
+	case int:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int32:
+		return strconv.AppendInt(b, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(b, v, 10)
+	case uint:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint32:
+		return strconv.AppendUint(b, uint64(v), 10)
+	case uint64:
+		return strconv.AppendUint(b, v, 10)
+	case float32:
+		return strconv.AppendFloat(b, float64(v), 'g', -1, 32)
+	case float64:
+		return strconv.AppendFloat(b, v, 'g', -1, 64)
+	case time.Duration:
+		return append(b, v.String()...)
+	case time.Time:
+		return v.AppendFormat(b, time.RFC3339Nano)
+	case error:
+		return appendLogfmtString(b, v.Error())
+	case fmt.Stringer:
+		return appendLogfmtString(b, v.String())
+	}
+	return appendLogfmtString(b, fmt.Sprint(value))
+}
+
+// ParseLogfmt splits a logfmt line into its key/value pairs in order, unquoting quoted values
+// ParseLogfmt memecah baris logfmt menjadi pasangan key/value sesuai urutan, menghapus kutip dari nilai yang dikutip
+//
+// A key without '=' yields an empty value; a trailing newline is ignored.
+// Key tanpa '=' menghasilkan nilai kosong; baris baru di akhir diabaikan.
+func ParseLogfmt(line []byte) ([]LogfmtPair, error) {
+	var pairs []LogfmtPair
+	i := 0
+	for {
+		for i < len(line) && (line[i] == ' ' || line[i] == '\n' || line[i] == '\r' || line[i] == '\t') {
+			i++
+		}
+		if i >= len(line) {
+			return pairs, nil
+		}
+		start := i
+		for i < len(line) && line[i] > ' ' && line[i] != '=' && line[i] != '"' {
+			i++
+		}
+		if i == start {
+			return nil, fmt.Errorf("logfmt: unexpected %q at offset %d", line[i], i)
+		}
+		pair := LogfmtPair{Key: string(line[start:i])}
+		if i < len(line) && line[i] == '"' {
+			return nil, fmt.Errorf("logfmt: unexpected '\"' in key at offset %d", i)
+		}
+		if i < len(line) && line[i] == '=' {
+			i++
+			if i < len(line) && line[i] == '"' {
+				value, n, err := unquoteLogfmt(line[i:])
+				if err != nil {
+					return nil, fmt.Errorf("logfmt: value of %q at offset %d: %w", pair.Key, i, err)
+				}
+				pair.Value = value
+				i += n
+				if i < len(line) && line[i] > ' ' {
+					return nil, fmt.Errorf("logfmt: unexpected %q after quoted value of %q at offset %d", line[i], pair.Key, i)
+				}
+			} else {
+				start = i
+				for i < len(line) && line[i] > ' ' && line[i] != '"' {
+					i++
+				}
+				if i < len(line) && line[i] == '"' {
+					return nil, fmt.Errorf("logfmt: unexpected '\"' in value of %q at offset %d", pair.Key, i)
+				}
+				pair.Value = string(line[start:i])
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+}
+
+// errLogfmtUnterminated is returned for a quoted value without a closing quote
+// errLogfmtUnterminated dikembalikan untuk nilai berkutip tanpa kutip penutup
+var errLogfmtUnterminated = errors.New("unterminated quoted value")
+
+// unquoteLogfmt decodes the quoted value at the start of b and returns it with the number of bytes consumed
+// unquoteLogfmt mendekode nilai berkutip di awal b dan mengembalikannya bersama jumlah byte yang dipakai
+func unquoteLogfmt(b []byte) (string, int, error) {
+	out := make([]byte, 0, len(b))
+	for i := 1; i < len(b); i++ {
+		c := b[i]
+		switch c {
+		case '"':
+			return string(out), i + 1, nil
+		case '\\':
+			i++
+			if i >= len(b) {
+				return "", 0, errLogfmtUnterminated
+			}
+			switch b[i] {
+			case '"', '\\', '/':
+				out = append(out, b[i])
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case 'u':
+				if i+4 >= len(b) {
+					return "", 0, errLogfmtUnterminated
+				}
+				r, err := strconv.ParseUint(string(b[i+1:i+5]), 16, 32)
+				if err != nil {
+					return "", 0, fmt.Errorf("invalid escape \\u%s", b[i+1:i+5])
+				}
+				out = utf8.AppendRune(out, rune(r))
+				i += 4
+			default:
+				return "", 0, fmt.Errorf("invalid escape \\%c", b[i])
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return "", 0, errLogfmtUnterminated
+}
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"crystal/internal/redact"
+)
+
+// LogfmtFormatter writes log entries as strict logfmt lines that Loki, hl and lnav can parse
+// LogfmtFormatter menulis entri log sebagai baris logfmt ketat yang dapat diurai oleh Loki, hl, dan lnav
+//
+// Keys are always written in the same order: ts, level, msg, caller, goroutine, pid, trace fields, duration,
+// service metadata, user fields in insertion order, tags, custom metrics, error and stack.
+// Key selalu ditulis dalam urutan yang sama: ts, level, msg, caller, goroutine, pid, field tracing, duration,
+// metadata layanan, field pengguna sesuai urutan penambahan, tags, metrik kustom, error, dan stack.
+type LogfmtFormatter struct {
+	TimestampFormat   string // TimestampFormat specifies the format string for the ts key, RFC3339Nano when empty
+	ShowCaller        bool   // ShowCaller controls whether caller=file:line is included
+	ShowGoroutine     bool   // ShowGoroutine controls whether the goroutine ID is included
+	ShowPID           bool   // ShowPID controls whether the process ID is included
+	ShowTraceInfo     bool   // ShowTraceInfo controls whether distributed tracing information is included
+	ShowHostname      bool   // ShowHostname controls whether the hostname is included
+	ShowApplication   bool   // ShowApplication controls whether application, version and environment are included
+	EnableStackTrace  bool   // EnableStackTrace controls whether captured stack traces are included
+	EnableDuration    bool   // EnableDuration controls whether duration measurements are included
+	MaskSensitiveData bool   // MaskSensitiveData masks keys such as password or token when the logger has no Redactor, like TextFormatter
+	MaskString        string // MaskString specifies the string used to mask sensitive data
+
+	redacted bool // The logger's Redactor has already redacted the fields
+}
+
+// NewLogfmtFormatter creates a new LogfmtFormatter with default settings
+// NewLogfmtFormatter membuat LogfmtFormatter baru dengan pengaturan default
+func NewLogfmtFormatter() *LogfmtFormatter {
+	return &LogfmtFormatter{
+		TimestampFormat: time.RFC3339Nano,
+		ShowCaller:      true,
+		ShowTraceInfo:   true,
+		EnableDuration:  true,
+		MaskString:      "***",
+	}
+}
+
+// setRedactor records whether the logger redacts fields before they are formatted
+// setRedactor mencatat apakah logger meredaksi field sebelum diformat
+func (f *LogfmtFormatter) setRedactor(r *redact.Redactor) {
+	f.redacted = r != nil
+}
+
+// Format formats a log entry as a single logfmt line
+// Format memformat entri log sebagai satu baris logfmt
+func (f *LogfmtFormatter) Format(entry interface{}) ([]byte, error) {
+	// Cast entry to LogEntryInterface
+	logEntry, ok := entry.(LogEntryInterface)
+	if !ok {
+		return nil, fmt.Errorf("invalid entry type")
+	}
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	b := f.appendEntry(buf.buf[:0], logEntry)
+	b = append(b, '\n')
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, len(b))
+	copy(result, b)
+	return result, nil
+}
+
+// appendEntry encodes the entry as space-separated key=value pairs
+// appendEntry mengkodekan entri sebagai pasangan key=value yang dipisahkan spasi
+func (f *LogfmtFormatter) appendEntry(b []byte, logEntry LogEntryInterface) []byte {
+	b = append(b, "ts="...)
+	format := f.TimestampFormat
+	if format == "" {
+		format = time.RFC3339Nano
+	}
+	start := len(b)
+	b = logEntry.GetTimestamp().AppendFormat(b, format)
+	b = quoteLogfmtTail(b, start)
+	// Level names are lowercase, which is what Loki and hl expect
+	// Nama tingkat menggunakan huruf kecil, sesuai yang diharapkan Loki dan hl
+	b = append(b, " level="...)
+	for _, c := range []byte(logEntry.GetLevel().String()) {
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		b = append(b, c)
+	}
+	b = append(b, " msg="...)
+	b = appendLogfmtString(b, logEntry.GetMessage())
+	if f.ShowCaller && logEntry.GetCallerFile() != "" {
+		b = append(b, " caller="...)
+		start := len(b)
+		b = append(b, logEntry.GetCallerFile()...)
+		b = append(b, ':')
+		b = strconv.AppendInt(b, int64(logEntry.GetCallerLine()), 10)
+		b = quoteLogfmtTail(b, start)
+	}
+	if f.ShowGoroutine {
+		b = f.appendStringIfSet(b, "goroutine", logEntry.GetGoroutineID())
+	}
+	if f.ShowPID {
+		b = append(b, " pid="...)
+		b = strconv.AppendInt(b, int64(logEntry.GetPID()), 10)
+	}
+	if f.ShowTraceInfo {
+		b = f.appendStringIfSet(b, "trace_id", logEntry.GetTraceID())
+		b = f.appendStringIfSet(b, "span_id", logEntry.GetSpanID())
+		b = f.appendStringIfSet(b, "user_id", logEntry.GetUserID())
+		b = f.appendStringIfSet(b, "session_id", logEntry.GetSessionID())
+		b = f.appendStringIfSet(b, "request_id", logEntry.GetRequestID())
+	}
+	if f.EnableDuration && logEntry.GetDuration() > 0 {
+		b = append(b, " duration="...)
+		b = append(b, logEntry.GetDuration().String()...)
+	}
+	if f.ShowHostname {
+		b = f.appendStringIfSet(b, "hostname", logEntry.GetHostname())
+	}
+	if f.ShowApplication {
+		b = f.appendStringIfSet(b, "application", logEntry.GetApplication())
+		b = f.appendStringIfSet(b, "version", logEntry.GetVersion())
+		b = f.appendStringIfSet(b, "environment", logEntry.GetEnvironment())
+	}
+	fields := entryFieldPairs(logEntry)
+	for i := range fields {
+		if fieldOverridden(fields, i) {
+			continue
+		}
+		key := bToString(fields[i].Key[:fields[i].KeyLen])
+		if key == "" {
+			continue
+		}
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, key)
+		b = append(b, '=')
+		if masksKey(f.MaskSensitiveData, f.redacted, key) {
+			b = appendLogfmtString(b, f.MaskString)
+			continue
+		}
+		b = appendLogfmtFieldValue(b, &fields[i])
+	}
+	if tags := entryTags(logEntry); len(tags) > 0 {
+		// Tags are joined into one value so the key appears only once
+		// Tag digabung menjadi satu nilai agar key hanya muncul sekali
+		b = append(b, " tags="...)
+		start := len(b)
+		for i, tag := range tags {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = append(b, tag...)
+		}
+		b = quoteLogfmtTail(b, start)
+	}
+	metrics := entryMetricPairs(logEntry)
+	for i := range metrics {
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, bToString(metrics[i].Key[:metrics[i].KeyLen]))
+		b = append(b, '=')
+		b = strconv.AppendFloat(b, metrics[i].Value, 'g', -1, 64)
+	}
+	if err := logEntry.GetError(); err != nil {
+		b = append(b, " error="...)
+		b = appendLogfmtString(b, err.Error())
+		b = append(b, " error.type="...)
+		b = appendLogfmtString(b, errorTypeName(err))
+	}
+	if f.EnableStackTrace {
+		b = f.appendStringIfSet(b, "stack", logEntry.GetStackTrace())
+	}
+	return b
+}
+
+// quoteLogfmtTail quotes the value written from start onwards when it needs quoting, so the common case does not allocate
+// quoteLogfmtTail mengutip nilai yang ditulis mulai dari start jika perlu dikutip, sehingga kasus umum tidak melakukan alokasi
+func quoteLogfmtTail(b []byte, start int) []byte {
+	if !logfmtNeedsQuote(bToString(b[start:])) {
+		return b
+	}
+	return appendLogfmtString(b[:start], string(b[start:]))
+}
+
+// appendStringIfSet appends a built-in key with a string value only when the value is non-empty
+// appendStringIfSet menambahkan key bawaan dengan nilai string hanya jika nilai tidak kosong
+func (f *LogfmtFormatter) appendStringIfSet(b []byte, key, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = append(b, ' ')
+	b = append(b, key...)
+	b = append(b, '=')
+	return appendLogfmtString(b, value)
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtFormatterFormat(t *testing.T) {
+	formatter := NewLogfmtFormatter()
+	entry := &LogEntry{
+		Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Level:     WARN,
+		Duration:  250 * time.Millisecond,
+		Error:     errors.New("upstream closed"),
+	}
+	entry.MessageLen = copy(entry.Message[:], "slow request")
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "server.go")
+	entry.Caller.Line = 42
+	entry.TraceIDLen = copy(entry.TraceID[:], "4bf92f3577b34da6a3ce929d0e0e4736")
+	entry.SetStringField("path", "/api/v1")
+	entry.SetIntField("status", 504)
+	entry.SetBoolField("retry", false)
+	entry.SetTag("edge")
+	entry.SetTag("eu")
+	entry.SetMetric("latency_ms", 250.5)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := `ts=2024-05-01T12:00:00Z level=warn msg="slow request" caller=server.go:42 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 ` +
+		`duration=250ms path=/api/v1 status=504 retry=false tags=edge,eu latency_ms=250.5 error="upstream closed" error.type=*errors.errorString` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected logfmt\n got: %s\nwant: %s", output, want)
+	}
+}
+
+func TestLogfmtFormatterRoundTrip(t *testing.T) {
+	formatter := &LogfmtFormatter{TimestampFormat: "2006-01-02 15:04:05", EnableStackTrace: true}
+	values := []string{
+		"",
+		"plain",
+		"two words",
+		`say "hi"`,
+		`C:\temp`,
+		"a=b",
+		"line1\nline2\ttabbed\r",
+		"bell\a",
+		"héllo wörld",
+		"x=\"y\" z",
+	}
+	for _, value := range values {
+		entry := &LogEntry{Timestamp: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Level: INFO}
+		entry.MessageLen = copy(entry.Message[:], value)
+		entry.SetStringField("value", value)
+		entry.SetField("bad key=", value)
+		entry.StackTraceLen = copy(entry.StackTrace[:], "main.main\n\t/app/main.go:10\n")
+
+		output, err := formatter.Format(entry)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.Count(string(output), "\n") != 1 {
+			t.Errorf("Expected a single line for %q, got %q", value, output)
+		}
+		pairs, err := ParseLogfmt(output)
+		if err != nil {
+			t.Fatalf("ParseLogfmt(%q) failed: %v", output, err)
+		}
+		want := []LogfmtPair{
+			{"ts", "2024-05-01 12:00:00"},
+			{"level", "info"},
+			{"msg", value},
+			{"value", value},
+			{"bad_key_", value},
+			{"stack", "main.main\n\t/app/main.go:10\n"},
+		}
+		if len(pairs) != len(want) {
+			t.Fatalf("Expected %d pairs for %q, got %+v", len(want), output, pairs)
+		}
+		for i := range want {
+			if pairs[i] != want[i] {
+				t.Errorf("Pair %d of %q = %+v; expected %+v", i, output, pairs[i], want[i])
+			}
+		}
+	}
+}
+
+func TestLogfmtFormatterMasking(t *testing.T) {
+	formatter := &LogfmtFormatter{MaskSensitiveData: true, MaskString: "[masked]"}
+	entry := &LogEntry{Timestamp: time.Now(), Level: INFO}
+	entry.MessageLen = copy(entry.Message[:], "login")
+	entry.SetStringField("user", "alice")
+	entry.SetStringField("Password", "hunter2")
+	entry.SetIntField("api_key", 12345)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if strings.Contains(string(output), "hunter2") || strings.Contains(string(output), "12345") {
+		t.Errorf("Expected sensitive values to be masked, got %s", output)
+	}
+	if !strings.Contains(string(output), "user=alice Password=[masked] api_key=[masked]") {
+		t.Errorf("Unexpected masked output %s", output)
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	pairs, err := ParseLogfmt([]byte(`a=1 flag b="x \"y\"\u0041" c= d="" ` + "\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []LogfmtPair{{"a", "1"}, {"flag", ""}, {"b", `x "y"A`}, {"c", ""}, {"d", ""}}
+	if len(pairs) != len(want) {
+		t.Fatalf("Expected %+v, got %+v", want, pairs)
+	}
+	for i := range want {
+		if pairs[i] != want[i] {
+			t.Errorf("Pair %d = %+v; expected %+v", i, pairs[i], want[i])
+		}
+	}
+
+	for _, invalid := range []string{`a="unterminated`, `a="x"b`, `a=b"c`, `=1`, `k"=1`, `a="\q"`} {
+		if _, err := ParseLogfmt([]byte(invalid)); err == nil {
+			t.Errorf("ParseLogfmt(%q) expected error, got none", invalid)
+		}
+	}
+}
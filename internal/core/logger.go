@@ -3,20 +3,25 @@ package core
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"crystal/internal/outputs"
 	"crystal/internal/rotation"
 	"crystal/internal/metrics"
 	"crystal/internal/interfaces"
+	"crystal/internal/redact"
 )
 
 const (
@@ -53,6 +58,9 @@ type LoggerConfig struct {
 	SamplingRate     int           // Sampling rate (1 in N entries) - Tingkat sampling (1 dari N entri)
 	AsyncLogging     bool          // Enable asynchronous logging - Aktifkan logging asinkron
 	ContextExtractor func(context.Context) map[string]string // Function to extract context values - Fungsi untuk mengekstrak nilai konteks
+	Redactor         *redact.Redactor // Redacts sensitive fields once before hooks and formatters, nil disables - Meredaksi field sensitif sekali sebelum hook dan formatter, nil menonaktifkan
+	ValueScanner     *redact.Scanner  // Masks PII found in messages, string and error field values and error messages, other values are not scanned, nil disables - Menyamarkan PII yang ditemukan dalam pesan, nilai field string dan error, serta pesan error, nilai lain tidak dipindai, nil menonaktifkan
+	AuditOutput      io.Writer     // Dedicated destination for audit events, nil means Output unbuffered - Tujuan khusus untuk event audit, nil berarti Output tanpa buffer
 	
 	// Feature flags to enable or disable specific functionality for customization
 	// Flag fitur untuk mengaktifkan atau menonaktifkan fungsionalitas tertentu untuk kustomisasi
@@ -62,6 +70,11 @@ type LoggerConfig struct {
 	ShowEnvironment  bool          // Include environment information - Sertakan informasi lingkungan
 	EnableColors     bool          // Enable colored output - Aktifkan output berwarna
 	EnableStackTrace bool          // Enable stack trace capture - Aktifkan pengambilan stack trace
+	EnableGoroutineID bool         // Record the calling goroutine's ID for ShowGoroutine - Catat ID goroutine pemanggil untuk ShowGoroutine
+	StackTraceLevel  Level         // Minimum level that captures a stack trace, TRACE means ERROR - Tingkat minimum yang mengambil stack trace, TRACE berarti ERROR
+	StackTraceDepth  int           // Maximum captured frames, 0 means MAX_STACK_DEPTH - Frame maksimum yang diambil, 0 berarti MAX_STACK_DEPTH
+	SlowOperationThreshold time.Duration // Timed operations slower than this are escalated, 0 disables - Operasi terukur yang lebih lambat dari ini dinaikkan tingkatnya, 0 menonaktifkan
+	SlowOperationLevel     Level         // Level for slow timed operations, TRACE means WARN - Tingkat untuk operasi terukur yang lambat, TRACE berarti WARN
 	
 	// Metrics and monitoring configuration for observability and performance tracking
 	// Konfigurasi metrik dan monitoring untuk observabilitas dan pelacakan kinerja
@@ -87,9 +100,9 @@ type Logger struct {
 	out                io.Writer                 // Primary output destination for log entries
 	errOut             io.Writer                 // Separate output destination for error-level entries
 	mu                 sync.Mutex                // Mutex for thread-safe operations
-	hooks              []func(*LogEntry)         // Hooks to execute for each log entry
+	hooks              *hookRegistry             // Hooks to execute for matching log entries, shared with child loggers
 	exitFunc           func(int)                 // Function to call on Fatal logs (default: os.Exit)
-	fields             map[string]interface{}    // Global fields to include in all log entries
+	boundFields        []FieldPair               // Pre-encoded fields bound via With/WithFields, copied into every entry
 	sampler            *SamplingLogger           // Logger for sampling functionality
 	buffer             *outputs.BufferedWriter   // Buffered writer for high-performance I/O
 	rotation           *rotation.RotatingFileWriter       // Rotating file writer for log rotation
@@ -100,9 +113,11 @@ type Logger struct {
 	onPanic            func(*LogEntry)           // Handler for Panic log entries
 	stats              *LoggerStats              // Statistics collector for logger performance
 	asyncLogger        *AsyncLogger              // Asynchronous logger for non-blocking operations
+	closeOnce          *sync.Once                // Ensures Close runs once across the logger and its children
+	audit              *auditSink                // Dedicated audit writer shared with child loggers
 	// Zero-allocation optimizations to maximize performance and minimize garbage collection
 	// Optimasi zero-allocation untuk memaksimalkan kinerja dan meminimalkan garbage collection
-	levelMask          uint64           // Bitmask for fast level checking without allocations - Bitmask untuk pemeriksaan tingkat cepat tanpa alokasi
+	levelMask          *uint64          // Bitmask for fast level checking without allocations, shared with child loggers - Bitmask untuk pemeriksaan tingkat cepat tanpa alokasi, dibagi dengan logger turunan
 	hostnameBytes      []byte           // Pre-converted hostname to avoid string conversions - Hostname yang telah dikonversi sebelumnya untuk menghindari konversi string
 	applicationBytes   []byte           // Pre-converted application name to avoid string conversions - Nama aplikasi yang telah dikonversi sebelumnya untuk menghindari konversi string
 	versionBytes       []byte           // Pre-converted version to avoid string conversions - Versi yang telah dikonversi sebelumnya untuk menghindari konversi string
@@ -146,7 +161,10 @@ func NewLogger(config LoggerConfig) *Logger {
 		out:       config.Output,             // Set primary output destination
 		errOut:    config.ErrorOutput,        // Set error output destination
 		exitFunc:  config.ExitFunc,           // Set exit function for fatal logs
-		fields:    make(map[string]interface{}), // Initialize global fields map
+		levelMask: new(uint64),               // Allocate level mask shared with child loggers
+		hooks:     newHookRegistry(),         // Initialize hook registry shared with child loggers
+		closeOnce: &sync.Once{},              // Initialize shutdown guard shared with child loggers
+		audit:     &auditSink{out: config.AuditOutput}, // Initialize audit sink shared with child loggers
 		contextExtractor: config.ContextExtractor, // Set context extractor function
 		metrics:   config.MetricsCollector,   // Set metrics collector
 		errorHandler: config.ErrorHandler,    // Set error handler function
@@ -159,13 +177,34 @@ func NewLogger(config LoggerConfig) *Logger {
 	if l.exitFunc == nil {
 		l.exitFunc = os.Exit
 	}
+	// Tell the formatter where entries go, so terminal detection checks the real destination
+	// Beri tahu formatter ke mana entri ditulis, agar deteksi terminal memeriksa tujuan sebenarnya
+	if aware, ok := config.Formatter.(outputAwareFormatter); ok && config.Output != nil {
+		aware.setOutput(config.Output)
+	}
+	// Formatter masking defers to the Redactor, so one rule set decides what every formatter hides
+	// Penyamaran formatter tunduk pada Redactor, sehingga satu set aturan menentukan apa yang disembunyikan setiap formatter
+	if aware, ok := config.Formatter.(redactorAwareFormatter); ok {
+		aware.setRedactor(config.Redactor)
+	}
+	// Audit events go straight to the primary output, never through the buffer, when no dedicated writer is set
+	// Event audit langsung menuju output utama, tidak pernah melalui buffer, jika writer khusus tidak diatur
+	if l.audit.out == nil {
+		l.audit.out = config.Output
+	}
+	if l.audit.out == nil {
+		l.audit.out = os.Stderr
+	}
 	// Pre-compute level mask for fast checking without allocations
 	// Hitung mask tingkat sebelumnya untuk pemeriksaan cepat tanpa alokasi
-	l.levelMask = uint64(0)
-	for i := config.Level; i < 8; i++ {
-		// Use pre-computed bitmasks for efficient level checking
-		// Gunakan bitmask yang telah dihitung sebelumnya untuk pemeriksaan tingkat yang efisien
-		l.levelMask |= levelMasks[i]
+	atomic.StoreUint64(l.levelMask, computeLevelMask(config.Level))
+	// Unset levels fall back to useful defaults: stacks from ERROR up and slow operations at WARN
+	// Tingkat yang tidak diatur kembali ke default yang berguna: stack mulai dari ERROR dan operasi lambat pada WARN
+	if l.config.StackTraceLevel == TRACE {
+		l.config.StackTraceLevel = ERROR
+	}
+	if l.config.SlowOperationLevel == TRACE {
+		l.config.SlowOperationLevel = WARN
 	}
 	// Pre-convert static strings to bytes to avoid repeated conversions and allocations
 	// Konversi string statis ke byte sebelumnya untuk menghindari konversi dan alokasi berulang
@@ -179,7 +218,11 @@ func NewLogger(config LoggerConfig) *Logger {
 	if config.BufferSize > 0 {
 		// Create buffered writer for efficient batched writes
 		// Buat penulis buffer untuk penulisan batch yang efisien
-		l.buffer = outputs.NewBufferedWriter(config.Output, config.BufferSize, config.FlushInterval)
+		flushInterval := config.FlushInterval
+		if flushInterval <= 0 {
+			flushInterval = DEFAULT_FLUSH_INTERVAL
+		}
+		l.buffer = outputs.NewBufferedWriter(config.Output, config.BufferSize, flushInterval)
 		l.out = l.buffer
 	}
 	// Setup sampling for reduced log volume if configured
@@ -202,25 +245,307 @@ func NewLogger(config LoggerConfig) *Logger {
 // SetLevel sets the minimum log level with optimized bitmask for zero-allocation level checking
 // SetLevel menetapkan tingkat log minimum dengan bitmask yang dioptimalkan untuk pemeriksaan tingkat zero-allocation
 func (l *Logger) SetLevel(level Level) {
-	// Acquire lock for thread-safe operation if locking is not disabled
-	// Dapatkan kunci untuk operasi thread-safe jika penguncian tidak dinonaktifkan
-	if !l.config.DisableLocking {
-		l.mu.Lock()
-		defer l.mu.Unlock()
-	}
-	// Pre-compute level mask for fast checking without allocations
-	// Hitung mask tingkat sebelumnya untuk pemeriksaan cepat tanpa alokasi
-	l.levelMask = uint64(0)
+	// Store atomically so the parent and all child loggers observe the new level without locking
+	// Simpan secara atomik agar induk dan semua logger turunan melihat tingkat baru tanpa penguncian
+	atomic.StoreUint64(l.levelMask, computeLevelMask(level))
+}
+
+// computeLevelMask builds the bitmask of enabled levels starting at the given minimum level
+// computeLevelMask membangun bitmask tingkat yang aktif mulai dari tingkat minimum yang diberikan
+func computeLevelMask(level Level) uint64 {
+	mask := uint64(0)
 	for i := level; i < 8; i++ {
 		// Use pre-computed bitmasks for efficient level checking
 		// Gunakan bitmask yang telah dihitung sebelumnya untuk pemeriksaan tingkat yang efisien
-		l.levelMask |= levelMasks[i]
+		mask |= levelMasks[i]
 	}
+	return mask
 }
 
 // Fast path level check
 func (l *Logger) shouldLog(level Level) bool {
-	return (l.levelMask & (1 << level)) != 0
+	return (atomic.LoadUint64(l.levelMask) & (1 << level)) != 0
+}
+
+// captureCallSite records the stack trace, preferring one carried by the entry's error, and goroutine ID of the calling goroutine when enabled and not yet captured
+// captureCallSite mencatat stack trace, mengutamakan yang dibawa oleh error entri, dan ID goroutine dari goroutine pemanggil jika diaktifkan dan belum diambil
+func (l *Logger) captureCallSite(entry *LogEntry) {
+	// An error carrying its own stack explains the failure better than the logging call site, at any level
+	// Error yang membawa stack sendiri menjelaskan kegagalan lebih baik daripada lokasi pemanggilan log, pada tingkat apa pun
+	if l.config.EnableStackTrace && entry.Error != nil && entry.StackTraceLen == 0 {
+		if pcs := errorStack(entry.Error); pcs != nil {
+			writeStackFrames(entry, pcs, l.config.StackTraceDepth)
+		}
+	}
+	if l.wantsStackTrace(entry) {
+		captureStack(entry, l.config.StackTraceDepth)
+	}
+	if l.config.EnableGoroutineID && entry.GoroutineIDLen == 0 {
+		captureGoroutineID(entry)
+	}
+}
+
+// wantsStackTrace reports whether a stack trace should be captured for an entry that does not have one yet
+// wantsStackTrace melaporkan apakah stack trace perlu diambil untuk entri yang belum memilikinya
+func (l *Logger) wantsStackTrace(entry *LogEntry) bool {
+	return l.config.EnableStackTrace && entry.Level >= l.config.StackTraceLevel && entry.StackTraceLen == 0
+}
+
+// Sync drains the async queue, flushes the buffered writer and fsyncs file outputs
+// Sync mengosongkan antrian async, mem-flush writer yang di-buffer, dan melakukan fsync pada output file
+func (l *Logger) Sync() error {
+	if l.asyncLogger != nil {
+		l.asyncLogger.wait()
+	}
+	return l.syncOutputs()
+}
+
+// Close syncs the logger, stops its async workers and buffer goroutine, and closes owned writers
+// Close melakukan sync pada logger, menghentikan worker async dan goroutine buffer, dan menutup writer yang dimiliki
+//
+// Resources are shared with child loggers, so Close should be called once on the root logger.
+// Sumber daya dibagi dengan logger turunan, jadi Close sebaiknya dipanggil sekali pada logger root.
+func (l *Logger) Close() error {
+	var err error
+	l.closeOnce.Do(func() {
+		err = l.Sync()
+		// Stop goroutines before closing the writers they write to
+		// Hentikan goroutine sebelum menutup writer tempat mereka menulis
+		if l.asyncLogger != nil {
+			l.asyncLogger.Close()
+		}
+		if l.buffer != nil {
+			if stopErr := l.buffer.Stop(); stopErr != nil && err == nil {
+				err = stopErr
+			}
+		}
+		// Standard streams are never owned by the logger and stay open
+		// Stream standar tidak pernah dimiliki oleh logger dan tetap terbuka
+		for _, w := range l.ownedWriters() {
+			if closer, ok := w.(io.Closer); ok && w != io.Writer(os.Stdout) && w != io.Writer(os.Stderr) {
+				if closeErr := closer.Close(); closeErr != nil && err == nil {
+					err = closeErr
+				}
+			}
+		}
+	})
+	return err
+}
+
+// syncOutputs flushes the buffered writer and fsyncs every underlying output without touching the async queue
+// syncOutputs mem-flush writer yang di-buffer dan melakukan fsync pada setiap output tanpa menyentuh antrian async
+func (l *Logger) syncOutputs() error {
+	var err error
+	if l.buffer != nil {
+		err = l.buffer.Flush()
+	}
+	for _, w := range l.ownedWriters() {
+		if syncErr := syncWriter(w); syncErr != nil && err == nil {
+			err = syncErr
+		}
+	}
+	return err
+}
+
+// ownedWriters returns the distinct underlying writers configured for the logger
+// ownedWriters mengembalikan writer dasar berbeda yang dikonfigurasi untuk logger
+func (l *Logger) ownedWriters() []io.Writer {
+	writers := make([]io.Writer, 0, 4)
+	for _, w := range []io.Writer{l.config.Output, l.config.ErrorOutput, l.config.AuditOutput} {
+		if w != nil && !containsWriter(writers, w) {
+			writers = append(writers, w)
+		}
+	}
+	if l.rotation != nil {
+		writers = append(writers, l.rotation)
+	}
+	return writers
+}
+
+// containsWriter reports whether w is already in writers
+// containsWriter melaporkan apakah w sudah ada dalam writers
+func containsWriter(writers []io.Writer, w io.Writer) bool {
+	// Guard the comparison so non-comparable writer types cannot panic
+	// Lindungi perbandingan agar tipe writer yang tidak dapat dibandingkan tidak menyebabkan panic
+	if !reflect.TypeOf(w).Comparable() {
+		return false
+	}
+	for _, existing := range writers {
+		if reflect.TypeOf(existing).Comparable() && existing == w {
+			return true
+		}
+	}
+	return false
+}
+
+// syncWriter fsyncs a writer when it supports it, ignoring errors from streams that cannot be synced such as pipes and terminals
+// syncWriter melakukan fsync pada writer jika didukung, mengabaikan kesalahan dari stream yang tidak dapat di-sync seperti pipe dan terminal
+func syncWriter(w io.Writer) error {
+	s, ok := w.(interface{ Sync() error })
+	if !ok {
+		return nil
+	}
+	if err := s.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) && !errors.Is(err, syscall.ENOTSUP) {
+		return err
+	}
+	return nil
+}
+
+// With returns a child logger that carries the given key-value pairs on every entry it writes
+// With mengembalikan logger turunan yang membawa pasangan key-value yang diberikan pada setiap entri yang ditulisnya
+func (l *Logger) With(keyvals ...interface{}) *Logger {
+	if len(keyvals) == 0 {
+		return l
+	}
+	// Start from a copy of the parent's bound fields so the parent stays immutable
+	// Mulai dari salinan field terikat induk agar induk tetap tidak berubah
+	bound := make([]FieldPair, len(l.boundFields), len(l.boundFields)+len(keyvals)/2)
+	copy(bound, l.boundFields)
+	// Must have even number of arguments (key-value pairs), mirroring addFieldsToEntry
+	// Harus memiliki jumlah argumen genap (pasangan key-value), sama seperti addFieldsToEntry
+	if len(keyvals)%2 != 0 {
+		bound = bindField(bound, fieldKeyError, "invalid field format - must be key-value pairs")
+		return l.derive(bound)
+	}
+	for i := 0; i < len(keyvals); i += 2 {
+		bound = bindField(bound, fieldKeyString(keyvals[i]), keyvals[i+1])
+	}
+	return l.derive(bound)
+}
+
+// WithFields returns a child logger that carries the given fields on every entry it writes, in sorted key order
+// WithFields mengembalikan logger turunan yang membawa field yang diberikan pada setiap entri yang ditulisnya, dalam urutan key yang terurut
+func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
+	if len(fields) == 0 {
+		return l
+	}
+	// Sort keys so the bound field order is deterministic across runs
+	// Urutkan key agar urutan field terikat deterministik di setiap eksekusi
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	bound := make([]FieldPair, len(l.boundFields), len(l.boundFields)+len(keys))
+	copy(bound, l.boundFields)
+	for _, key := range keys {
+		bound = bindField(bound, key, fields[key])
+	}
+	return l.derive(bound)
+}
+
+// derive creates a child logger sharing outputs, formatter, buffer and level with l but owning the given bound fields
+// derive membuat logger turunan yang berbagi output, formatter, buffer, dan tingkat dengan l tetapi memiliki field terikat sendiri
+func (l *Logger) derive(bound []FieldPair) *Logger {
+	return &Logger{
+		config:           l.config,
+		formatter:        l.formatter,
+		out:              l.out,
+		errOut:           l.errOut,
+		exitFunc:         l.exitFunc,
+		hooks:            l.hooks,
+		boundFields:      bound,
+		sampler:          l.sampler,
+		buffer:           l.buffer,
+		rotation:         l.rotation,
+		contextExtractor: l.contextExtractor,
+		metrics:          l.metrics,
+		errorHandler:     l.errorHandler,
+		onFatal:          l.onFatal,
+		onPanic:          l.onPanic,
+		stats:            l.stats,
+		asyncLogger:      l.asyncLogger,
+		closeOnce:        l.closeOnce,
+		audit:            l.audit,
+		levelMask:        l.levelMask,
+		hostnameBytes:    l.hostnameBytes,
+		applicationBytes: l.applicationBytes,
+		versionBytes:     l.versionBytes,
+		environmentBytes: l.environmentBytes,
+		pidStr:           l.pidStr,
+	}
+}
+
+// bindField encodes a single field once into a FieldPair, replacing an existing binding with the same key
+// bindField mengkodekan satu field sekali ke dalam FieldPair, menggantikan binding yang ada dengan key yang sama
+func bindField(bound []FieldPair, key string, value interface{}) []FieldPair {
+	var fp FieldPair
+	encodeFieldPair(&fp, key, value)
+	for i := range bound {
+		if bToString(bound[i].Key[:bound[i].KeyLen]) == bToString(fp.Key[:fp.KeyLen]) {
+			bound[i] = fp
+			return bound
+		}
+	}
+	return append(bound, fp)
+}
+
+// encodeFieldPair fills a FieldPair using the typed zero-allocation slots where the value type allows it
+// encodeFieldPair mengisi FieldPair menggunakan slot bertipe zero-allocation jika tipe nilai memungkinkan
+func encodeFieldPair(fp *FieldPair, key string, value interface{}) {
+	keyLen := len(key)
+	if keyLen > len(fp.Key) {
+		keyLen = len(fp.Key)
+	}
+	copy(fp.Key[:], key[:keyLen])
+	fp.KeyLen = keyLen
+	switch v := value.(type) {
+	case string:
+		valueLen := len(v)
+		if valueLen > len(fp.StringValue) {
+			valueLen = len(fp.StringValue)
+		}
+		copy(fp.StringValue[:], v[:valueLen])
+		fp.StringValueLen = valueLen
+		fp.IsString = true
+	case error:
+		// Bound errors are rendered by message since they are shared by every entry of the child logger
+		// Error terikat ditampilkan berdasarkan pesannya karena dibagi oleh setiap entri logger turunan
+		encodeFieldPair(fp, key, v.Error())
+	case int:
+		fp.IntValue = int64(v)
+		fp.IsInt = true
+	case int64:
+		fp.IntValue = v
+		fp.IsInt = true
+	case float64:
+		fp.Float64Value = v
+		fp.IsFloat64 = true
+	case bool:
+		fp.BoolValue = v
+		fp.IsBool = true
+	default:
+		fp.Value = v
+	}
+}
+
+// setCaller records a caller file and line in the entry's fixed buffers
+// setCaller mencatat file dan baris pemanggil dalam buffer tetap entri
+func setCaller(entry *LogEntry, file string, line int) {
+	// Copy file name to fixed buffer to avoid allocation
+	// Salin nama file ke buffer tetap untuk menghindari alokasi
+	fileLen := len(file)
+	if fileLen > len(entry.Caller.File) {
+		fileLen = len(entry.Caller.File)
+	}
+	copy(entry.Caller.File[:], file[:fileLen])
+	entry.Caller.FileLen = fileLen
+	entry.Caller.Line = line
+	// Extract function and package names from file path for better context
+	// Ekstrak nama fungsi dan paket dari path file untuk konteks yang lebih baik
+	if idx := lastIndexByte(file, '/'); idx != -1 {
+		packageName := file[:idx]
+		if pkgIdx := lastIndexByte(packageName, '/'); pkgIdx != -1 {
+			// Copy package name to fixed buffer to avoid allocation
+			// Salin nama paket ke buffer tetap untuk menghindari alokasi
+			pkgLen := len(packageName[pkgIdx+1:])
+			if pkgLen > len(entry.Caller.Package) {
+				pkgLen = len(entry.Caller.Package)
+			}
+			copy(entry.Caller.Package[:], packageName[pkgIdx+1:pkgIdx+1+pkgLen])
+			entry.Caller.PackageLen = pkgLen
+		}
+	}
 }
 
 // logEntry is the core logging method that takes a pre-populated LogEntry
@@ -273,52 +598,33 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 		copy(entry.Environment[:], l.environmentBytes)
 		entry.EnvironmentLen = len(l.environmentBytes)
 	}
-	// Extract caller information if enabled
-	if !l.config.DisableCallerInfo {
+	// Capture call-site state here for synchronous logging; async entries were captured on the calling goroutine
+	// Ambil status lokasi pemanggilan di sini untuk logging sinkron; entri async sudah diambil pada goroutine pemanggil
+	l.captureCallSite(entry)
+	// Extract caller information if enabled, unless the entry was built with its caller already recorded (as by Timer)
+	// Ekstrak informasi pemanggil jika diaktifkan, kecuali entri dibuat dengan pemanggil yang sudah dicatat (seperti oleh Timer)
+	if !l.config.DisableCallerInfo && entry.Caller.FileLen == 0 {
 		// Get caller info with configurable depth for flexibility
 		// Dapatkan info pemanggil dengan kedalaman yang dapat dikonfigurasi untuk fleksibilitas
 		_, file, line, ok := runtime.Caller(DEFAULT_CALLER_DEPTH)
 		if ok {
-			// Copy file name to fixed buffer to avoid allocation
-			// Salin nama file ke buffer tetap untuk menghindari alokasi
-			fileLen := len(file)
-			if fileLen > len(entry.Caller.File) {
-				fileLen = len(entry.Caller.File)
-			}
-			copy(entry.Caller.File[:], file[:fileLen])
-			entry.Caller.FileLen = fileLen
-			entry.Caller.Line = line
-			// Extract function and package names from file path for better context
-			// Ekstrak nama fungsi dan paket dari path file untuk konteks yang lebih baik
-			if idx := lastIndexByte(file, '/'); idx != -1 {
-				packageName := file[:idx]
-				if pkgIdx := lastIndexByte(packageName, '/'); pkgIdx != -1 {
-					// Copy package name to fixed buffer to avoid allocation
-					// Salin nama paket ke buffer tetap untuk menghindari alokasi
-					pkgLen := len(packageName[pkgIdx+1:])
-					if pkgLen > len(entry.Caller.Package) {
-						pkgLen = len(entry.Caller.Package)
-					}
-					copy(entry.Caller.Package[:], packageName[pkgIdx+1:pkgIdx+1+pkgLen])
-					entry.Caller.PackageLen = pkgLen
-				}
-			}
+			setCaller(entry, file, line)
 		}
 	}
-	// Add global fields if any
-	// Tambahkan field global jika ada
-	if len(l.fields) > 0 && !l.config.DisableFieldCopy {
-		// Acquire lock for thread-safe operation if locking is not disabled
-		// Dapatkan kunci untuk operasi thread-safe jika penguncian tidak dinonaktifkan
-		if !l.config.DisableLocking {
-			l.mu.Lock()
+	// Add bound fields ahead of call-site fields with a single block copy; they are immutable so no lock is needed
+	// Tambahkan field terikat sebelum field pemanggil dengan satu salinan blok; field ini immutable sehingga tidak perlu kunci
+	if len(l.boundFields) > 0 && !l.config.DisableFieldCopy {
+		bound := len(l.boundFields)
+		if bound > len(entry.Fields) {
+			bound = len(entry.Fields)
 		}
-		for key, value := range l.fields {
-			entry.SetField(key, value)
-		}
-		if !l.config.DisableLocking {
-			l.mu.Unlock()
+		callCount := entry.FieldsCount
+		if callCount > len(entry.Fields)-bound {
+			callCount = len(entry.Fields) - bound
 		}
+		copy(entry.Fields[bound:bound+callCount], entry.Fields[:callCount])
+		copy(entry.Fields[:bound], l.boundFields[:bound])
+		entry.FieldsCount = bound + callCount
 	}
 	// Add context fields if extractor is provided and context is not nil
 	// Tambahkan field konteks jika extractor disediakan dan konteks tidak nil
@@ -332,8 +638,9 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 	// Tambahkan nilai konteks jika konteks tidak nil
 	if ctx != nil {
 		// For zero allocation, we'll use a more direct approach
-		// Extract context values directly without creating intermediate maps
-		if traceID := ctx.Value("trace_id"); traceID != nil {
+		// Extract context values directly without creating intermediate maps, using the typed keys set by WithTraceID and friends
+		// Ekstrak nilai konteks secara langsung tanpa map perantara, menggunakan kunci bertipe yang diatur oleh WithTraceID dan sejenisnya
+		if traceID := ctx.Value(TraceIDKey); traceID != nil {
 			if traceIDStr, ok := traceID.(string); ok {
 				// Copy trace ID to fixed buffer to avoid allocation
 				// Salin ID trace ke buffer tetap untuk menghindari alokasi
@@ -345,7 +652,7 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 				entry.TraceIDLen = traceIDLen
 			}
 		}
-		if spanID := ctx.Value("span_id"); spanID != nil {
+		if spanID := ctx.Value(SpanIDKey); spanID != nil {
 			if spanIDStr, ok := spanID.(string); ok {
 				// Copy span ID to fixed buffer to avoid allocation
 				// Salin ID span ke buffer tetap untuk menghindari alokasi
@@ -357,7 +664,10 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 				entry.SpanIDLen = spanIDLen
 			}
 		}
-		if userID := ctx.Value("user_id"); userID != nil {
+		if sampled, ok := ctx.Value(TraceSampledKey).(bool); ok {
+			setTypedField(entry, string(TraceSampledKey), sampled)
+		}
+		if userID := ctx.Value(UserIDKey); userID != nil {
 			if userIDStr, ok := userID.(string); ok {
 				// Copy user ID to fixed buffer to avoid allocation
 				// Salin ID pengguna ke buffer tetap untuk menghindari alokasi
@@ -369,7 +679,7 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 				entry.UserIDLen = userIDLen
 			}
 		}
-		if sessionID := ctx.Value("session_id"); sessionID != nil {
+		if sessionID := ctx.Value(SessionIDKey); sessionID != nil {
 			if sessionIDStr, ok := sessionID.(string); ok {
 				// Copy session ID to fixed buffer to avoid allocation
 				// Salin ID sesi ke buffer tetap untuk menghindari alokasi
@@ -381,7 +691,7 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 				entry.SessionIDLen = sessionIDLen
 			}
 		}
-		if requestID := ctx.Value("request_id"); requestID != nil {
+		if requestID := ctx.Value(RequestIDKey); requestID != nil {
 			if requestIDStr, ok := requestID.(string); ok {
 				// Copy request ID to fixed buffer to avoid allocation
 				// Salin ID permintaan ke buffer tetap untuk menghindari alokasi
@@ -393,20 +703,27 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 				entry.RequestIDLen = requestIDLen
 			}
 		}
-	}
-	// Execute hooks if any
-	// Jalankan hook jika ada
-	if len(l.hooks) > 0 {
-		// Acquire lock for thread-safe operation if locking is not disabled
-		// Dapatkan kunci untuk operasi thread-safe jika penguncian tidak dinonaktifkan
-		if !l.config.DisableLocking {
-			l.mu.Lock()
-		}
-		for _, hook := range l.hooks {
-			hook(entry)
+		// Map application-registered context keys onto entry fields
+		// Petakan kunci konteks yang didaftarkan aplikasi ke field entri
+		for _, binding := range registeredContextKeys() {
+			if value := ctx.Value(binding.key); value != nil {
+				setTypedField(entry, binding.field, value)
+			}
 		}
-		if !l.config.DisableLocking {
-			l.mu.Unlock()
+	}
+	// Redact sensitive fields and values once so hooks and every formatter see the same output
+	// Redaksi field dan nilai sensitif sekali agar hook dan setiap formatter melihat output yang sama
+	if l.config.Redactor != nil {
+		redactEntry(l.config.Redactor, entry)
+	}
+	if l.config.ValueScanner != nil {
+		scanEntry(l.config.ValueScanner, entry)
+	}
+	// Execute hooks registered for this level from a lock-free snapshot so a slow hook cannot serialize the logger
+	// Jalankan hook yang terdaftar untuk tingkat ini dari snapshot tanpa kunci agar hook yang lambat tidak menserialisasi logger
+	for _, hook := range l.hooks.forLevel(entry.Level) {
+		if err := hook.Fire(entry); err != nil && l.errorHandler != nil {
+			l.errorHandler(fmt.Errorf("failed to fire hook: %w", err))
 		}
 	}
 	// Format and write the log entry
@@ -465,6 +782,13 @@ func (l *Logger) logEntry(entry *LogEntry, ctx context.Context) {
 	}
 	// Handle fatal and panic levels
 	// Tangani tingkat fatal dan panic
+	if entry.Level >= FATAL {
+		// Flush and fsync outputs so the entry reaches disk before exiting or panicking
+		// Flush dan fsync output agar entri mencapai disk sebelum keluar atau panic
+		if err := l.syncOutputs(); err != nil && l.errorHandler != nil {
+			l.errorHandler(fmt.Errorf("failed to sync log outputs: %w", err))
+		}
+	}
 	if entry.Level == FATAL {
 		// Execute fatal handler if provided
 		// Jalankan handler fatal jika disediakan
@@ -508,7 +832,7 @@ func (l *Logger) Trace(msg string, fields ...interface{}) {
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, nil)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = TRACE
@@ -530,7 +854,7 @@ func (l *Logger) Debug(msg string, fields ...interface{}) {
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, nil)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = DEBUG
@@ -552,7 +876,7 @@ func (l *Logger) Info(msg string, fields ...interface{}) {
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, nil)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = INFO
@@ -574,7 +898,7 @@ func (l *Logger) Notice(msg string, fields ...interface{}) {
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, nil)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = NOTICE
@@ -596,7 +920,7 @@ func (l *Logger) Warn(msg string, fields ...interface{}) {
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, nil)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = WARN
@@ -618,7 +942,7 @@ func (l *Logger) Error(msg string, fields ...interface{}) {
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, nil)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = ERROR
@@ -632,45 +956,33 @@ func (l *Logger) Error(msg string, fields ...interface{}) {
 // Fatal logs a message at FATAL level and exits the program with zero allocation
 // Fatal mencatat pesan pada tingkat FATAL dan keluar dari program dengan zero allocation
 func (l *Logger) Fatal(msg string, fields ...interface{}) {
+	// Drain queued entries first and write synchronously so nothing is lost before the process stops
+	// Kosongkan entri yang diantrekan terlebih dahulu dan tulis secara sinkron agar tidak ada yang hilang sebelum proses berhenti
 	if l.asyncLogger != nil {
-		// For async logging, we still need to create a map for now
-		// For future optimization, we could pass the fields directly
-		entry := getEntryFromPool()
-		entry.Level = FATAL
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
-	} else {
-		entry := getEntryFromPool()
-		entry.Level = FATAL
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.logEntry(entry, nil)
+		l.asyncLogger.wait()
 	}
+	entry := getEntryFromPool()
+	entry.Level = FATAL
+	copy(entry.Message[:], msg)
+	entry.MessageLen = len(msg)
+	addFieldsToEntry(entry, fields...)
+	l.logEntry(entry, nil)
 }
 
 // Panic logs a message at PANIC level and panics with zero allocation
 // Panic mencatat pesan pada tingkat PANIC dan panic dengan zero allocation
 func (l *Logger) Panic(msg string, fields ...interface{}) {
+	// Drain queued entries first and write synchronously so nothing is lost before the process stops
+	// Kosongkan entri yang diantrekan terlebih dahulu dan tulis secara sinkron agar tidak ada yang hilang sebelum proses berhenti
 	if l.asyncLogger != nil {
-		// For async logging, we still need to create a map for now
-		// For future optimization, we could pass the fields directly
-		entry := getEntryFromPool()
-		entry.Level = PANIC
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
-	} else {
-		entry := getEntryFromPool()
-		entry.Level = PANIC
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.logEntry(entry, nil)
+		l.asyncLogger.wait()
 	}
+	entry := getEntryFromPool()
+	entry.Level = PANIC
+	copy(entry.Message[:], msg)
+	entry.MessageLen = len(msg)
+	addFieldsToEntry(entry, fields...)
+	l.logEntry(entry, nil)
 }
 
 // TraceContext logs a message at TRACE level with context support and zero allocation
@@ -684,7 +996,7 @@ func (l *Logger) TraceContext(ctx context.Context, msg string, fields ...interfa
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = TRACE
@@ -706,7 +1018,7 @@ func (l *Logger) DebugContext(ctx context.Context, msg string, fields ...interfa
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = DEBUG
@@ -728,7 +1040,7 @@ func (l *Logger) InfoContext(ctx context.Context, msg string, fields ...interfac
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = INFO
@@ -750,7 +1062,7 @@ func (l *Logger) NoticeContext(ctx context.Context, msg string, fields ...interf
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = NOTICE
@@ -772,7 +1084,7 @@ func (l *Logger) WarnContext(ctx context.Context, msg string, fields ...interfac
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = WARN
@@ -794,7 +1106,7 @@ func (l *Logger) ErrorContext(ctx context.Context, msg string, fields ...interfa
 		copy(entry.Message[:], msg)
 		entry.MessageLen = len(msg)
 		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
 	} else {
 		entry := getEntryFromPool()
 		entry.Level = ERROR
@@ -805,48 +1117,68 @@ func (l *Logger) ErrorContext(ctx context.Context, msg string, fields ...interfa
 	}
 }
 
+// ErrorErr logs err at ERROR level, recording its type, wrapped chain and any stack it carries
+// ErrorErr mencatat err pada tingkat ERROR, merekam tipenya, rantai yang dibungkus, dan stack yang dibawanya
+func (l *Logger) ErrorErr(err error, msg string, fields ...interface{}) {
+	entry := getEntryFromPool()
+	entry.Level = ERROR
+	entry.Error = err
+	copy(entry.Message[:], msg)
+	entry.MessageLen = len(msg)
+	addFieldsToEntry(entry, fields...)
+	if l.asyncLogger != nil {
+		l.asyncLogger.logEntryFrom(l, entry, nil)
+	} else {
+		l.logEntry(entry, nil)
+	}
+}
+
+// ErrorErrContext logs err at ERROR level with context support, recording its type, wrapped chain and any stack it carries
+// ErrorErrContext mencatat err pada tingkat ERROR dengan dukungan konteks, merekam tipenya, rantai yang dibungkus, dan stack yang dibawanya
+func (l *Logger) ErrorErrContext(ctx context.Context, err error, msg string, fields ...interface{}) {
+	entry := getEntryFromPool()
+	entry.Level = ERROR
+	entry.Error = err
+	copy(entry.Message[:], msg)
+	entry.MessageLen = len(msg)
+	addFieldsToEntry(entry, fields...)
+	if l.asyncLogger != nil {
+		l.asyncLogger.logEntryFrom(l, entry, ctx)
+	} else {
+		l.logEntry(entry, ctx)
+	}
+}
+
 // FatalContext logs a message at FATAL level with context support, exits the program, and zero allocation
 // FatalContext mencatat pesan pada tingkat FATAL dengan dukungan konteks, keluar dari program, dan zero allocation
 func (l *Logger) FatalContext(ctx context.Context, msg string, fields ...interface{}) {
+	// Drain queued entries first and write synchronously so nothing is lost before the process stops
+	// Kosongkan entri yang diantrekan terlebih dahulu dan tulis secara sinkron agar tidak ada yang hilang sebelum proses berhenti
 	if l.asyncLogger != nil {
-		// For async logging, we still need to create a map for now
-		// For future optimization, we could pass the fields directly
-		entry := getEntryFromPool()
-		entry.Level = FATAL
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
-	} else {
-		entry := getEntryFromPool()
-		entry.Level = FATAL
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.logEntry(entry, ctx)
+		l.asyncLogger.wait()
 	}
+	entry := getEntryFromPool()
+	entry.Level = FATAL
+	copy(entry.Message[:], msg)
+	entry.MessageLen = len(msg)
+	addFieldsToEntry(entry, fields...)
+	l.logEntry(entry, ctx)
 }
 
 // PanicContext logs a message at PANIC level with context support, panics, and zero allocation
 // PanicContext mencatat pesan pada tingkat PANIC dengan dukungan konteks, panic, dan zero allocation
 func (l *Logger) PanicContext(ctx context.Context, msg string, fields ...interface{}) {
+	// Drain queued entries first and write synchronously so nothing is lost before the process stops
+	// Kosongkan entri yang diantrekan terlebih dahulu dan tulis secara sinkron agar tidak ada yang hilang sebelum proses berhenti
 	if l.asyncLogger != nil {
-		// For async logging, we still need to create a map for now
-		// For future optimization, we could pass the fields directly
-		entry := getEntryFromPool()
-		entry.Level = PANIC
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.asyncLogger.LogEntry(entry)
-	} else {
-		entry := getEntryFromPool()
-		entry.Level = PANIC
-		copy(entry.Message[:], msg)
-		entry.MessageLen = len(msg)
-		addFieldsToEntry(entry, fields...)
-		l.logEntry(entry, ctx)
+		l.asyncLogger.wait()
 	}
+	entry := getEntryFromPool()
+	entry.Level = PANIC
+	copy(entry.Message[:], msg)
+	entry.MessageLen = len(msg)
+	addFieldsToEntry(entry, fields...)
+	l.logEntry(entry, ctx)
 }
 
 // Helper function to convert variadic fields to map
@@ -866,36 +1198,55 @@ func addFieldsToEntry(entry *LogEntry, fields ...interface{}) {
 	for i := 0; i < len(fields); i += 2 {
 		// Convert key to string if it's not already
 		// Konversi key ke string jika belum
-		var key string
-		switch k := fields[i].(type) {
-		case string:
-			key = k
-		case fmt.Stringer:
-			key = k.String()
-		default:
-			// For zero allocation, we'll use a simple approach
-			// For more complex cases, we could use a buffer pool
-			key = fmt.Sprintf("%v", k)
-		}
+		key := fieldKeyString(fields[i])
 		
-		// Add field to entry using zero-allocation methods when possible
-		// Tambahkan field ke entry menggunakan metode zero-allocation jika memungkinkan
-		switch v := fields[i+1].(type) {
-		case string:
-			entry.SetStringField(key, v)
-		case int:
-			entry.SetIntField(key, v)
-		case int64:
-			entry.SetIntField(key, int(v))
-		case float64:
-			entry.SetFloat64Field(key, v)
-		case bool:
-			entry.SetBoolField(key, v)
-		default:
-			// For other types, use the generic SetField method
-			// Untuk tipe lain, gunakan metode SetField generik
-			entry.SetField(key, v)
+		setTypedField(entry, key, fields[i+1])
+	}
+}
+
+// setTypedField adds a field to entry using zero-allocation methods when possible
+// setTypedField menambahkan field ke entry menggunakan metode zero-allocation jika memungkinkan
+func setTypedField(entry *LogEntry, key string, value interface{}) {
+	switch v := value.(type) {
+	case string:
+		entry.SetStringField(key, v)
+	case error:
+		// The first error under a conventional key becomes the entry's error so formatters can report its chain;
+		// any other error keeps its key with the message as value
+		// Error pertama dengan kunci konvensional menjadi error entri agar formatter dapat melaporkan rantainya;
+		// error lainnya mempertahankan kuncinya dengan pesan sebagai nilai
+		if entry.Error == nil && (key == "error" || key == "err") {
+			entry.Error = v
+		} else {
+			entry.SetStringField(key, v.Error())
 		}
+	case int:
+		entry.SetIntField(key, v)
+	case int64:
+		entry.SetIntField(key, int(v))
+	case float64:
+		entry.SetFloat64Field(key, v)
+	case bool:
+		entry.SetBoolField(key, v)
+	default:
+		// For other types, use the generic SetField method
+		// Untuk tipe lain, gunakan metode SetField generik
+		entry.SetField(key, v)
+	}
+}
+
+// fieldKeyString converts a field key of any type to its string form
+// fieldKeyString mengkonversi key field dari tipe apa pun ke bentuk string
+func fieldKeyString(k interface{}) string {
+	switch k := k.(type) {
+	case string:
+		return k
+	case fmt.Stringer:
+		return k.String()
+	default:
+		// For zero allocation, we'll use a simple approach
+		// For more complex cases, we could use a buffer pool
+		return fmt.Sprintf("%v", k)
 	}
 }
 
@@ -1046,16 +1397,23 @@ type AsyncLogger struct {
 	once      sync.Once
 	closeOnce sync.Once
 	closed    chan struct{}
+	pendingMu sync.Mutex    // Guards the ticket counters below - Melindungi penghitung tiket di bawah
+	drained   *sync.Cond    // Signalled when completed advances - Diberi sinyal saat completed bertambah
+	submitted uint64        // Ticket of the last job submitted - Tiket pekerjaan terakhir yang dikirim
+	completed uint64        // Every job up to this ticket is finished - Setiap pekerjaan hingga tiket ini telah selesai
+	finished  map[uint64]struct{} // Tickets finished out of order above completed - Tiket yang selesai tidak berurutan di atas completed
 }
 
 // logJob represents a logging job for async processing
 // logJob merepresentasikan pekerjaan logging untuk pemrosesan async
 type logJob struct {
+	logger  *Logger   // Originating logger, which may be a child carrying bound fields
 	level   Level
 	msg     string
 	fields  map[string]interface{}
 	ctx     context.Context
 	entry   *LogEntry // For zero-allocation approach
+	ticket  uint64    // Submission order, used by wait
 }
 
 // NewAsyncLogger creates a new AsyncLogger
@@ -1072,7 +1430,9 @@ func NewAsyncLogger(logger *Logger, workers int, bufferSize int) *AsyncLogger {
 		jobs:    make(chan *logJob, bufferSize),
 		workers: workers,
 		closed:  make(chan struct{}),
+		finished: make(map[uint64]struct{}),
 	}
+	al.drained = sync.NewCond(&al.pendingMu)
 	
 	// Start worker goroutines
 	// Mulai goroutine worker
@@ -1094,26 +1454,14 @@ func (al *AsyncLogger) worker() {
 		case job := <-al.jobs:
 			// Process the log job synchronously
 			// Proses pekerjaan log secara sinkron
-			if job.entry != nil {
-				// Use the zero-allocation approach
-				al.logger.logEntry(job.entry, job.ctx)
-			} else {
-				// Use the legacy approach for backward compatibility
-				al.logger.logEntry(createLogEntry(job.level, job.msg, job.fields), job.ctx)
-			}
+			al.process(job)
 		case <-al.closed:
 			// Drain remaining jobs before closing
 			// Kosongkan pekerjaan yang tersisa sebelum menutup
 			for {
 				select {
 				case job := <-al.jobs:
-					if job.entry != nil {
-						// Use the zero-allocation approach
-						al.logger.logEntry(job.entry, job.ctx)
-					} else {
-						// Use the legacy approach for backward compatibility
-						al.logger.log(job.level, job.msg, job.fields, job.ctx)
-					}
+					al.process(job)
 				default:
 					return
 				}
@@ -1122,6 +1470,80 @@ func (al *AsyncLogger) worker() {
 	}
 }
 
+// process runs a single log job and marks it as completed for wait
+// process menjalankan satu pekerjaan log dan menandainya selesai untuk wait
+func (al *AsyncLogger) process(job *logJob) {
+	defer al.done(job)
+	if job.entry != nil {
+		// Use the zero-allocation approach
+		job.logger.logEntry(job.entry, job.ctx)
+	} else {
+		// Use the legacy approach for backward compatibility
+		al.logger.log(job.level, job.msg, job.fields, job.ctx)
+	}
+}
+
+// submit queues a job without blocking, running it synchronously once the logger is closed
+// submit mengantrekan pekerjaan tanpa blocking, menjalankannya secara sinkron setelah logger ditutup
+func (al *AsyncLogger) submit(job *logJob) bool {
+	al.pendingMu.Lock()
+	al.submitted++
+	job.ticket = al.submitted
+	al.pendingMu.Unlock()
+	select {
+	case <-al.closed:
+		// Workers are gone, write inline so the entry is not lost
+		// Worker sudah berhenti, tulis langsung agar entri tidak hilang
+		al.process(job)
+		return true
+	default:
+	}
+	select {
+	case al.jobs <- job:
+		// Job queued successfully
+		// Pekerjaan diantrekan dengan sukses
+		return true
+	default:
+		// Queue is full, drop the log to prevent blocking
+		// Antrian penuh, hapus log untuk mencegah blocking
+		al.done(job)
+		return false
+	}
+}
+
+// done marks a job as finished and wakes waiters when every job up to a later ticket is finished
+// done menandai pekerjaan sebagai selesai dan membangunkan penunggu saat setiap pekerjaan hingga tiket yang lebih baru selesai
+func (al *AsyncLogger) done(job *logJob) {
+	al.pendingMu.Lock()
+	defer al.pendingMu.Unlock()
+	// Workers finish jobs out of order, so completed only advances over a contiguous run of tickets
+	// Worker menyelesaikan pekerjaan tidak berurutan, sehingga completed hanya maju melewati rangkaian tiket yang berurutan
+	if job.ticket != al.completed+1 {
+		al.finished[job.ticket] = struct{}{}
+		return
+	}
+	al.completed++
+	for {
+		if _, ok := al.finished[al.completed+1]; !ok {
+			break
+		}
+		delete(al.finished, al.completed+1)
+		al.completed++
+	}
+	al.drained.Broadcast()
+}
+
+// wait blocks until every job submitted before the call has been written, ignoring jobs submitted meanwhile
+// wait memblokir hingga setiap pekerjaan yang dikirim sebelum pemanggilan telah ditulis, mengabaikan pekerjaan yang dikirim sementara itu
+func (al *AsyncLogger) wait() {
+	al.pendingMu.Lock()
+	target := al.submitted
+	for al.completed < target {
+		al.drained.Wait()
+	}
+	al.pendingMu.Unlock()
+}
+
 // Log adds a log job to the queue for async processing
 // Log menambahkan pekerjaan log ke antrian untuk pemrosesan async
 func (al *AsyncLogger) Log(level Level, msg string, fields map[string]interface{}, ctx context.Context) {
@@ -1132,13 +1554,7 @@ func (al *AsyncLogger) Log(level Level, msg string, fields map[string]interface{
 		ctx:    ctx,
 	}
 	
-	select {
-	case al.jobs <- job:
-		// Job queued successfully
-		// Pekerjaan diantrekan dengan sukses
-	default:
-		// Queue is full, drop the log to prevent blocking
-		// Antrian penuh, hapus log untuk mencegah blocking
+	if !al.submit(job) {
 		if al.logger.errorHandler != nil {
 			al.logger.errorHandler(fmt.Errorf("async log queue full, dropping log entry"))
 		}
@@ -1148,18 +1564,26 @@ func (al *AsyncLogger) Log(level Level, msg string, fields map[string]interface{
 // LogEntry adds a LogEntry job to the queue for async processing with zero allocation
 // LogEntry menambahkan pekerjaan LogEntry ke antrian untuk pemrosesan async dengan zero allocation
 func (al *AsyncLogger) LogEntry(entry *LogEntry) {
+	al.logEntryFrom(al.logger, entry, nil)
+}
+
+// logEntryFrom queues an entry and its context on behalf of the given logger so child loggers keep their bound fields
+// logEntryFrom mengantrekan entri dan konteksnya atas nama logger yang diberikan agar logger turunan mempertahankan field terikatnya
+func (al *AsyncLogger) logEntryFrom(l *Logger, entry *LogEntry, ctx context.Context) {
+	// Workers run on their own goroutines, so the caller's stack and goroutine ID must be taken before queueing
+	// Worker berjalan pada goroutine sendiri, sehingga stack dan ID goroutine pemanggil harus diambil sebelum diantrekan
+	if l.shouldLog(entry.Level) {
+		l.captureCallSite(entry)
+	}
 	job := &logJob{
-		entry: entry,
+		logger: l,
+		entry:  entry,
+		ctx:    ctx,
 	}
 	
-	select {
-	case al.jobs <- job:
-		// Job queued successfully
-		// Pekerjaan diantrekan dengan sukses
-	default:
-		// Queue is full, drop the log to prevent blocking
-		// Antrian penuh, hapus log untuk mencegah blocking
+	if !al.submit(job) {
 		// Return the entry to the pool since we're dropping it
+		// Kembalikan entri ke pool karena entri dijatuhkan
 		putEntryToPool(entry)
 		if al.logger.errorHandler != nil {
 			al.logger.errorHandler(fmt.Errorf("async log queue full, dropping log entry"))
@@ -1171,9 +1595,20 @@ func (al *AsyncLogger) LogEntry(entry *LogEntry) {
 // Close mematikan logger async dengan anggun
 func (al *AsyncLogger) Close() {
 	al.closeOnce.Do(func() {
+		// Only signal workers; they drain the jobs channel themselves, and closing it would hand them nil jobs
+		// Hanya beri sinyal ke worker; worker mengosongkan channel sendiri, dan menutupnya akan memberikan pekerjaan nil
 		close(al.closed)
-		close(al.jobs)
 		al.wg.Wait()
+		// Write anything that raced in after the workers exited
+		// Tulis apa pun yang masuk setelah worker berhenti
+		for {
+			select {
+			case job := <-al.jobs:
+				al.process(job)
+			default:
+				return
+			}
+		}
 	})
 }
 
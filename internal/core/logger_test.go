@@ -3,10 +3,17 @@ package core
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"crystal/internal/metrics"
 )
 
 // Mock writer for testing
@@ -108,7 +115,7 @@ func TestContextLogging(t *testing.T) {
 	}
 	logger := NewLogger(config)
 
-	ctx := context.WithValue(context.Background(), "request_id", "req-123")
+	ctx := WithRequestID(context.Background(), "req-123")
 	logger.InfoContext(ctx, "Processing request")
 	output := writer.String()
 	
@@ -154,4 +161,595 @@ func TestTimeField(t *testing.T) {
 	if !strings.Contains(output, now.Format("2006-01-02")) {
 		t.Error("Expected timestamp to be formatted correctly")
 	}
-}
\ No newline at end of file
+}
+
+func TestLoggerWith(t *testing.T) {
+	writer := &mockWriter{}
+	config := LoggerConfig{
+		Level:     INFO,
+		Output:    writer,
+		Formatter: &TextFormatter{},
+	}
+	logger := NewLogger(config)
+
+	child := logger.With("request_id", "req-42", "tenant", "acme")
+	grandchild := child.WithFields(map[string]interface{}{"route": "/users", "tenant": "globex"})
+
+	grandchild.Info("Handled", "status", 200)
+	output := writer.String()
+	if !strings.Contains(output, `{request_id="req-42" tenant="globex" route="/users" status=200}`) {
+		t.Errorf("Expected bound fields ahead of call-site fields, got %q", output)
+	}
+
+	writer.buf.Reset()
+	logger.Info("Parent")
+	if strings.Contains(writer.String(), "request_id") {
+		t.Error("Expected parent logger to be unaffected by child bindings")
+	}
+
+	writer.buf.Reset()
+	logger.SetLevel(ERROR)
+	child.Info("Filtered")
+	if writer.String() != "" {
+		t.Error("Expected child logger to share the parent's level")
+	}
+}
+
+// recordingHook captures messages for the levels it declares
+type recordingHook struct {
+	levels   []Level
+	messages []string
+	err      error
+}
+
+func (h *recordingHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *recordingHook) Fire(entry *LogEntry) error {
+	h.messages = append(h.messages, entry.GetMessage())
+	return h.err
+}
+
+func TestLoggerHooks(t *testing.T) {
+	var handled []error
+	writer := &mockWriter{}
+	config := LoggerConfig{
+		Level:        INFO,
+		Output:       writer,
+		Formatter:    &TextFormatter{},
+		ErrorHandler: func(err error) { handled = append(handled, err) },
+	}
+	logger := NewLogger(config)
+
+	hook := &recordingHook{levels: []Level{ERROR}, err: errors.New("alert channel down")}
+	logger.AddHook(hook)
+
+	logger.Info("Ignored by hook")
+	logger.With("component", "db").Error("Connection lost")
+	if len(hook.messages) != 1 || hook.messages[0] != "Connection lost" {
+		t.Errorf("Expected hook to fire only for ERROR entries, got %v", hook.messages)
+	}
+	if len(handled) != 1 || !strings.Contains(handled[0].Error(), "alert channel down") {
+		t.Errorf("Expected hook error to reach ErrorHandler, got %v", handled)
+	}
+
+	if !logger.RemoveHook(hook) {
+		t.Error("Expected RemoveHook to report the registered hook")
+	}
+	logger.Error("After removal")
+	if len(hook.messages) != 1 {
+		t.Error("Expected removed hook not to fire")
+	}
+}
+
+// sliceHook is a hook value whose dynamic type cannot be compared with ==
+type sliceHook struct{ levels []Level }
+
+func (h sliceHook) Levels() []Level { return h.levels }
+
+func (h sliceHook) Fire(entry *LogEntry) error { return nil }
+
+func TestLoggerHookEdgeCases(t *testing.T) {
+	logger := NewLogger(LoggerConfig{Level: INFO, Output: &mockWriter{}, Formatter: &TextFormatter{}})
+
+	hook := &recordingHook{levels: []Level{ERROR, ERROR, WARN}}
+	logger.AddHook(hook)
+	logger.Error("once")
+	if len(hook.messages) != 1 {
+		t.Errorf("Expected a level listed twice to fire once, got %v", hook.messages)
+	}
+
+	logger.AddHook(sliceHook{levels: []Level{ERROR}})
+	if logger.RemoveHook(sliceHook{levels: []Level{ERROR}}) {
+		t.Error("Expected an uncomparable hook not to be found")
+	}
+	if !logger.RemoveHook(hook) {
+		t.Error("Expected the pointer hook to be removed next to an uncomparable one")
+	}
+}
+
+func TestLoggerSyncAndClose(t *testing.T) {
+	writer := &fileLikeWriter{}
+	config := LoggerConfig{
+		Level:        INFO,
+		Output:       writer,
+		Formatter:    &TextFormatter{},
+		AsyncLogging: true,
+		BufferSize:   100,
+	}
+	logger := NewLogger(config)
+
+	for i := 0; i < 50; i++ {
+		logger.Info("queued", "n", i)
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatalf("Unexpected sync error: %v", err)
+	}
+	if got := strings.Count(writer.String(), "queued"); got != 50 {
+		t.Errorf("Expected all 50 queued entries after Sync, got %d", got)
+	}
+	if writer.syncs == 0 {
+		t.Error("Expected Sync to fsync the output")
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	if !writer.closed {
+		t.Error("Expected Close to close the owned output")
+	}
+}
+
+func TestLoggerSyncReturnsWhileOthersKeepLogging(t *testing.T) {
+	writer := &fileLikeWriter{}
+	logger := NewLogger(LoggerConfig{Level: INFO, Output: writer, Formatter: &TextFormatter{}, AsyncLogging: true, BufferSize: 100})
+	defer logger.Close()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					logger.Info("background")
+				}
+			}
+		}()
+	}
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	logger.Info("before sync")
+	synced := make(chan struct{})
+	go func() {
+		logger.Sync()
+		close(synced)
+	}()
+	select {
+	case <-synced:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sync waited for entries logged after it was called")
+	}
+	if !strings.Contains(writer.String(), "before sync") {
+		t.Error("Expected the entry logged before Sync to be written")
+	}
+}
+
+func TestLoggerFatalSyncsBeforeExit(t *testing.T) {
+	writer := &fileLikeWriter{}
+	exitCode := -1
+	config := LoggerConfig{
+		Level:        INFO,
+		Output:       writer,
+		Formatter:    &TextFormatter{},
+		AsyncLogging: true,
+		BufferSize:   100,
+		ExitFunc: func(code int) {
+			exitCode = code
+		},
+	}
+	logger := NewLogger(config)
+	defer logger.Close()
+
+	logger.Info("before fatal")
+	logger.Fatal("shutting down")
+	if exitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", exitCode)
+	}
+	output := writer.String()
+	if !strings.Contains(output, "before fatal") || !strings.Contains(output, "shutting down") {
+		t.Errorf("Expected queued and fatal entries before exit, got %q", output)
+	}
+	if writer.syncs == 0 {
+		t.Error("Expected FATAL to fsync the output before exiting")
+	}
+}
+
+// fileLikeWriter records Sync and Close calls in addition to the written bytes
+type fileLikeWriter struct {
+	mockWriter
+	mu     sync.Mutex
+	syncs  int
+	closed bool
+}
+
+func (w *fileLikeWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.mockWriter.Write(p)
+}
+
+func (w *fileLikeWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.mockWriter.String()
+}
+
+func (w *fileLikeWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.syncs++
+	return nil
+}
+
+func (w *fileLikeWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func TestLoggerStackTrace(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		writer := &fileLikeWriter{}
+		config := LoggerConfig{
+			Level:            INFO,
+			Output:           writer,
+			Formatter:        &JSONFormatter{EnableStackTrace: true},
+			EnableStackTrace: true,
+			AsyncLogging:     async,
+			BufferSize:       100,
+		}
+		logger := NewLogger(config)
+
+		logger.Info("no stack")
+		logger.Error("with stack")
+		logger.Close()
+
+		lines := strings.Split(strings.TrimSpace(writer.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("async=%v: expected 2 entries, got %d: %q", async, len(lines), writer.String())
+		}
+		var info, failure map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[0]), &info); err != nil {
+			t.Fatalf("async=%v: invalid JSON: %v", async, err)
+		}
+		if err := json.Unmarshal([]byte(lines[1]), &failure); err != nil {
+			t.Fatalf("async=%v: invalid JSON: %v", async, err)
+		}
+		if _, ok := info["stack_trace"]; ok {
+			t.Errorf("async=%v: expected no stack trace below ERROR", async)
+		}
+		frames, ok := failure["stack_trace"].([]interface{})
+		if !ok || len(frames) == 0 {
+			t.Fatalf("async=%v: expected stack frames, got %v", async, failure["stack_trace"])
+		}
+		// The first frame must be the caller, not the logger itself
+		top := frames[0].(map[string]interface{})
+		if !strings.HasSuffix(top["function"].(string), "TestLoggerStackTrace") {
+			t.Errorf("async=%v: expected caller as first frame, got %v", async, top["function"])
+		}
+		if !strings.HasSuffix(top["file"].(string), "logger_test.go") || top["line"].(float64) <= 0 {
+			t.Errorf("async=%v: unexpected frame location %v:%v", async, top["file"], top["line"])
+		}
+	}
+}
+
+func TestLoggerGoroutineID(t *testing.T) {
+	writer := &mockWriter{}
+	config := LoggerConfig{
+		Level:             INFO,
+		Output:            writer,
+		Formatter:         &JSONFormatter{ShowGoroutine: true},
+		EnableGoroutineID: true,
+	}
+	logger := NewLogger(config)
+
+	logger.Info("from goroutine")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(writer.buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	id, ok := entry["goroutine_id"].(string)
+	if !ok || id == "" {
+		t.Fatalf("Expected goroutine_id, got %v", entry["goroutine_id"])
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			t.Fatalf("Expected numeric goroutine ID, got %q", id)
+		}
+	}
+}
+
+// stackError records where it was created, like errors from stack-aware error packages
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func newStackError(msg string) *stackError {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(1, pcs)
+	return &stackError{msg: msg, pcs: pcs[:n]}
+}
+
+func (e *stackError) Error() string      { return e.msg }
+func (e *stackError) Callers() []uintptr { return e.pcs }
+
+func TestLoggerErrorChain(t *testing.T) {
+	writer := &mockWriter{}
+	config := LoggerConfig{
+		Level:     INFO,
+		Output:    writer,
+		Formatter: &JSONFormatter{},
+	}
+	logger := NewLogger(config)
+
+	pathErr := &fs.PathError{Op: "open", Path: "/missing", Err: fs.ErrNotExist}
+	err := fmt.Errorf("load config: %w", errors.Join(pathErr, errors.New("fallback failed")))
+	logger.ErrorErr(err, "startup failed", "attempt", 2)
+
+	var entry map[string]interface{}
+	if jsonErr := json.Unmarshal(writer.buf.Bytes(), &entry); jsonErr != nil {
+		t.Fatalf("invalid JSON: %v", jsonErr)
+	}
+	if entry["error"] != err.Error() {
+		t.Errorf("Expected error message %q, got %v", err.Error(), entry["error"])
+	}
+	if entry["error.type"] != "*fmt.wrapError" {
+		t.Errorf("Expected concrete error type, got %v", entry["error.type"])
+	}
+	chain, ok := entry["error.chain"].([]interface{})
+	if !ok {
+		t.Fatalf("Expected error.chain array, got %v", entry["error.chain"])
+	}
+	var types []string
+	for _, link := range chain {
+		types = append(types, link.(map[string]interface{})["type"].(string))
+	}
+	want := []string{"*errors.joinError", "*fs.PathError", "*errors.errorString", "*errors.errorString"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected chain types %v, got %v", want, types)
+	}
+}
+
+func TestStackSkipsFacadeWrappers(t *testing.T) {
+	tests := []struct {
+		function, file string
+		skip           bool
+	}{
+		{"crystal/internal/core.(*Logger).Error", "/src/crystal/internal/core/logger.go", true},
+		{"crystal.Error", "/src/crystal/crystal.go", true},
+		{"crystal/internal.Info", "/src/crystal/internal/crystal.go", true},
+		{"crystal/internal.TestGlobalLogging", "/src/crystal/internal/crystal_test.go", false},
+		{"crystal.helper", "/src/crystal/helpers.go", false},
+		{"main.handler", "/app/crystal.go", false},
+		{"example.com/crystalapp.Run", "/app/crystal.go", false},
+	}
+	for _, tt := range tests {
+		if got := isCrystalFrame(tt.function, tt.file); got != tt.skip {
+			t.Errorf("isCrystalFrame(%q, %q) = %v, want %v", tt.function, tt.file, got, tt.skip)
+		}
+	}
+}
+
+func TestLoggerErrorFieldAndStack(t *testing.T) {
+	writer := &mockWriter{}
+	config := LoggerConfig{
+		Level:            INFO,
+		Output:           writer,
+		Formatter:        &TextFormatter{EnableStackTrace: true},
+		EnableStackTrace: true,
+	}
+	logger := NewLogger(config)
+
+	// Error values in field lists are recognized, and a stack carried by the error wins over the call site
+	err := fmt.Errorf("wrapped: %w", newStackError("disk full"))
+	logger.Warn("write failed", "err", err)
+
+	output := writer.String()
+	if !strings.Contains(output, `error="wrapped: disk full" error.type=*fmt.wrapError`) {
+		t.Errorf("Expected error message and type, got %q", output)
+	}
+	if !strings.Contains(output, `error.chain=["*core.stackError: disk full"]`) {
+		t.Errorf("Expected wrapped chain, got %q", output)
+	}
+	if !strings.Contains(output, "core.newStackError") {
+		t.Errorf("Expected the error's own stack, got %q", output)
+	}
+}
+
+func TestTimerReportsCallSite(t *testing.T) {
+	for _, async := range []bool{false, true} {
+		writer := &fileLikeWriter{}
+		logger := NewLogger(LoggerConfig{
+			Level:        INFO,
+			Output:       writer,
+			Formatter:    &JSONFormatter{ShowCaller: true},
+			AsyncLogging: async,
+			BufferSize:   10,
+		})
+
+		timer := logger.StartTimer("upload")
+		_, _, startLine, _ := runtime.Caller(0)
+		timer.Stop()
+		logger.TimeOperation("query", nil, func() {})
+		_, _, opLine, _ := runtime.Caller(0)
+		logger.Close()
+
+		lines := strings.Split(strings.TrimSpace(writer.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("async=%v: expected 2 entries, got %q", async, writer.String())
+		}
+		for i, wantLine := range []int{startLine - 1, opLine - 1} {
+			var entry struct {
+				Caller struct {
+					File string `json:"file"`
+					Line int    `json:"line"`
+				} `json:"caller"`
+			}
+			if err := json.Unmarshal([]byte(lines[i]), &entry); err != nil {
+				t.Fatalf("async=%v: invalid JSON: %v", async, err)
+			}
+			if !strings.HasSuffix(entry.Caller.File, "/logger_test.go") || entry.Caller.Line != wantLine {
+				t.Errorf("async=%v: expected caller logger_test.go:%d, got %s:%d", async, wantLine, entry.Caller.File, entry.Caller.Line)
+			}
+		}
+	}
+}
+
+func TestLoggerErrorFieldKeepsKey(t *testing.T) {
+	writer := &mockWriter{}
+	logger := NewLogger(LoggerConfig{Level: INFO, Output: writer, Formatter: &TextFormatter{}})
+
+	// Only the conventional keys become the entry's error; other keys keep the error as a field
+	logger.Info("upload retried", "upload_err", errors.New("timeout"), "error", errors.New("gave up"), "err", errors.New("second"))
+
+	output := writer.String()
+	for _, want := range []string{`upload_err="timeout"`, `error="gave up"`, `err="second"`} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %s, got %q", want, output)
+		}
+	}
+}
+
+func TestLoggerTimer(t *testing.T) {
+	writer := &mockWriter{}
+	collector := metrics.NewDefaultMetricsCollector()
+	config := LoggerConfig{
+		Level:                  INFO,
+		Output:                 writer,
+		Formatter:              &TextFormatter{EnableDuration: true},
+		MetricsCollector:       collector,
+		SlowOperationThreshold: 5 * time.Millisecond,
+	}
+	logger := NewLogger(config)
+
+	fast := logger.StartTimer("cache_lookup", "key", "user:1")
+	if elapsed := fast.Stop(); elapsed <= 0 {
+		t.Errorf("Expected positive duration, got %v", elapsed)
+	}
+	fast.Stop() // Only the first stop logs
+
+	logger.TimeOperation("database_query", map[string]interface{}{"table": "users"}, func() {
+		time.Sleep(10 * time.Millisecond)
+	})
+
+	failed := logger.StartTimer("upload")
+	failed.StopWithError(errors.New("connection reset"))
+
+	lines := strings.Split(strings.TrimSpace(writer.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 timing entries, got %d: %q", len(lines), writer.String())
+	}
+	if !strings.HasPrefix(lines[0], "[INFO] cache_lookup") || !strings.Contains(lines[0], "duration=") {
+		t.Errorf("Expected INFO entry with duration, got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "[WARN] database_query") || !strings.Contains(lines[1], "table") {
+		t.Errorf("Expected slow operation escalated to WARN, got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "[ERROR] upload") || !strings.Contains(lines[2], "connection reset") {
+		t.Errorf("Expected failed operation at ERROR, got %q", lines[2])
+	}
+
+	if _, max, _, _ := collector.GetHistogram("database_query_duration"); max < 0.01 {
+		t.Errorf("Expected histogram to record at least 10ms, got %v", max)
+	}
+	if got := len(collector.GetAllHistograms()["cache_lookup_duration"]); got != 1 {
+		t.Errorf("Expected one cache_lookup_duration sample, got %d", got)
+	}
+}
+
+func TestLoggerAudit(t *testing.T) {
+	writer := &mockWriter{}
+	auditWriter := &fileLikeWriter{}
+	config := LoggerConfig{
+		Level:          FATAL,
+		Output:         writer,
+		Formatter:      &TextFormatter{},
+		AuditOutput:    auditWriter,
+		EnableSampling: true,
+		SamplingRate:   1000,
+		AsyncLogging:   true,
+		BufferSize:     10,
+		Application:    "billing",
+	}
+	logger := NewLogger(config)
+	defer logger.Close()
+
+	ctx := WithRequestID(context.Background(), "req-42")
+	ctx = WithTraceID(ctx, "trace-7")
+	ctx = WithSourceIP(ctx, "10.0.0.9")
+	ctx = WithUserID(ctx, "ctx-user")
+
+	// Audit events are written even though the level, sampler and async queue would drop regular entries
+	for i := 0; i < 3; i++ {
+		if err := logger.With("component", "auth").AuditContext(ctx, "USER_LOGIN", "login", "auth_service", "", i != 2, map[string]interface{}{"attempt": i}); err != nil {
+			t.Fatalf("Unexpected audit error: %v", err)
+		}
+	}
+	if err := logger.Audit("ROLE_CHANGE", "grant", "admin_role", "user-1", true, nil); err != nil {
+		t.Fatalf("Unexpected audit error: %v", err)
+	}
+
+	if writer.String() != "" {
+		t.Errorf("Expected audit events to stay out of the main output, got %q", writer.String())
+	}
+	if auditWriter.syncs != 4 {
+		t.Errorf("Expected the audit writer to be synced after each event, got %d syncs", auditWriter.syncs)
+	}
+	lines := strings.Split(strings.TrimSpace(auditWriter.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("Expected 4 audit records, got %d: %q", len(lines), auditWriter.String())
+	}
+
+	var first map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, key := range []string{"schema_version", "timestamp", "event_type", "actor", "action", "resource", "outcome", "source_ip", "trace_id", "span_id", "request_id", "session_id", "application", "hostname", "details"} {
+		if _, ok := first[key]; !ok {
+			t.Errorf("Expected fixed schema key %q in %s", key, lines[0])
+		}
+	}
+	if first["schema_version"] != AuditSchemaVersion || first["actor"] != "ctx-user" || first["source_ip"] != "10.0.0.9" ||
+		first["request_id"] != "req-42" || first["trace_id"] != "trace-7" || first["outcome"] != AuditOutcomeSuccess {
+		t.Errorf("Unexpected audit record %s", lines[0])
+	}
+	if ts, _ := first["timestamp"].(string); !strings.HasSuffix(ts, "Z") {
+		t.Errorf("Expected UTC timestamp, got %q", ts)
+	}
+
+	var third, fourth AuditEvent
+	if err := json.Unmarshal([]byte(lines[2]), &third); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[3]), &fourth); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if third.Outcome != AuditOutcomeFailure {
+		t.Errorf("Expected failure outcome, got %q", third.Outcome)
+	}
+	if fourth.Actor != "user-1" || fourth.Application != "billing" || fourth.RequestID != "" {
+		t.Errorf("Unexpected audit record %+v", fourth)
+	}
+}
@@ -0,0 +1,383 @@
+package core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// msgpackTimestampExt is the extension type MessagePack reserves for timestamps
+// msgpackTimestampExt adalah tipe ekstensi yang dicadangkan MessagePack untuk timestamp
+const msgpackTimestampExt = -1
+
+// MsgpackFormatter writes each log entry as one MessagePack map, with the timestamp as the native timestamp extension
+// MsgpackFormatter menulis setiap entri log sebagai satu map MessagePack, dengan timestamp sebagai ekstensi timestamp bawaan
+//
+// Entries are written back to back without separators; read them with MsgpackDecoder.
+// Entri ditulis berurutan tanpa pemisah; baca dengan MsgpackDecoder.
+type MsgpackFormatter struct{}
+
+// NewMsgpackFormatter creates a new MsgpackFormatter
+// NewMsgpackFormatter membuat MsgpackFormatter baru
+func NewMsgpackFormatter() *MsgpackFormatter {
+	return &MsgpackFormatter{}
+}
+
+// Format encodes a log entry as MessagePack
+// Format mengkodekan entri log sebagai MessagePack
+func (f *MsgpackFormatter) Format(entry interface{}) ([]byte, error) {
+	return formatBinaryEntry(msgpackEncoder{}, entry)
+}
+
+// msgpackEncoder implements binaryEncoder for MessagePack, always choosing the smallest representation
+// msgpackEncoder mengimplementasikan binaryEncoder untuk MessagePack, selalu memilih representasi terkecil
+type msgpackEncoder struct{}
+
+func (msgpackEncoder) appendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+func (msgpackEncoder) appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func (e msgpackEncoder) appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return e.appendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+}
+
+func (msgpackEncoder) appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v < 0x80:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+}
+
+func (msgpackEncoder) appendFloat32(b []byte, v float32) []byte {
+	return binary.BigEndian.AppendUint32(append(b, 0xca), math.Float32bits(v))
+}
+
+func (msgpackEncoder) appendFloat64(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func (msgpackEncoder) appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n < 32:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func (msgpackEncoder) appendBytes(b []byte, v []byte) []byte {
+	switch n := len(v); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, v...)
+}
+
+// appendTime uses the timestamp 32, 64 or 96 layout depending on the range and precision needed
+// appendTime menggunakan tata letak timestamp 32, 64, atau 96 tergantung rentang dan presisi yang diperlukan
+func (msgpackEncoder) appendTime(b []byte, t time.Time) []byte {
+	sec, nsec := t.Unix(), uint32(t.Nanosecond())
+	if uint64(sec)>>34 == 0 {
+		if nsec == 0 && sec <= math.MaxUint32 {
+			b = append(b, 0xd6, byte(0xff))
+			return binary.BigEndian.AppendUint32(b, uint32(sec))
+		}
+		b = append(b, 0xd7, byte(0xff))
+		return binary.BigEndian.AppendUint64(b, uint64(nsec)<<34|uint64(sec))
+	}
+	b = append(b, 0xc7, 12, byte(0xff))
+	b = binary.BigEndian.AppendUint32(b, nsec)
+	return binary.BigEndian.AppendUint64(b, uint64(sec))
+}
+
+func (msgpackEncoder) appendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+}
+
+func (msgpackEncoder) appendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n < 16:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+}
+
+func (msgpackEncoder) beginMap(b []byte) ([]byte, int) {
+	return append(b, 0xdf, 0, 0, 0, 0), len(b)
+}
+
+// endMap shrinks the reserved map32 header to the smallest form once the size is known
+// endMap mengecilkan header map32 yang disediakan ke bentuk terkecil setelah ukurannya diketahui
+func (e msgpackEncoder) endMap(b []byte, pos, n int) []byte {
+	var header [5]byte
+	h := e.appendMapHeader(header[:0], n)
+	shift := 5 - len(h)
+	copy(b[pos:], h)
+	copy(b[pos+len(h):], b[pos+5:])
+	return b[:len(b)-shift]
+}
+
+// MsgpackDecoder reads a stream of MessagePack log records written by MsgpackFormatter
+// MsgpackDecoder membaca aliran record log MessagePack yang ditulis oleh MsgpackFormatter
+//
+// Maps decode to map[string]interface{}, arrays to []interface{}, integers to int64 (uint64 when too large),
+// floats to float64, binary data to []byte and timestamps to time.Time.
+// Map didekode menjadi map[string]interface{}, array menjadi []interface{}, integer menjadi int64 (uint64 jika terlalu besar),
+// float menjadi float64, data biner menjadi []byte, dan timestamp menjadi time.Time.
+type MsgpackDecoder struct {
+	r *bufio.Reader
+}
+
+// NewMsgpackDecoder creates a decoder reading from r
+// NewMsgpackDecoder membuat decoder yang membaca dari r
+func NewMsgpackDecoder(r io.Reader) *MsgpackDecoder {
+	return &MsgpackDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next record, returning io.EOF when the stream ends cleanly between records
+// Decode membaca record berikutnya, mengembalikan io.EOF jika aliran berakhir dengan bersih di antara record
+func (d *MsgpackDecoder) Decode() (map[string]interface{}, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+	value, err := d.decodeValue(0)
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return binaryRecord(value, "msgpack")
+}
+
+// unexpectedEOF turns io.EOF inside a record into io.ErrUnexpectedEOF
+// unexpectedEOF mengubah io.EOF di dalam record menjadi io.ErrUnexpectedEOF
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// decodeValue reads one value of any type
+// decodeValue membaca satu nilai dari tipe apa pun
+func (d *MsgpackDecoder) decodeValue(depth int) (interface{}, error) {
+	if depth > maxBinaryDepth {
+		return nil, errors.New("msgpack: nesting too deep")
+	}
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return d.decodeMap(int(c&0x0f), depth)
+	case c&0xf0 == 0x90:
+		return d.decodeArray(int(c&0x0f), depth)
+	case c&0xe0 == 0xa0:
+		return d.readString(int(c & 0x1f))
+	}
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.readLength(c - 0xc4)
+		if err != nil {
+			return nil, err
+		}
+		return d.readBytes(n)
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.readLength(c - 0xc7)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeExt(n)
+	case 0xca:
+		v, err := d.readUint(4)
+		return float64(math.Float32frombits(uint32(v))), err
+	case 0xcb:
+		v, err := d.readUint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := d.readUint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if v > math.MaxInt64 {
+			return v, nil
+		}
+		return int64(v), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := d.readUint(size)
+		if err != nil {
+			return nil, err
+		}
+		// Sign-extend from the encoded width
+		// Perluas tanda dari lebar yang dikodekan
+		shift := 64 - 8*size
+		return int64(v<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.decodeExt(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.readLength(c - 0xd9)
+		if err != nil {
+			return nil, err
+		}
+		return d.readString(n)
+	case 0xdc, 0xdd:
+		n, err := d.readLength(c - 0xdc + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeArray(n, depth)
+	case 0xde, 0xdf:
+		n, err := d.readLength(c - 0xde + 1)
+		if err != nil {
+			return nil, err
+		}
+		return d.decodeMap(n, depth)
+	}
+	return nil, fmt.Errorf("msgpack: invalid type byte 0x%02x", c)
+}
+
+// readLength reads a big-endian length of 1, 2 or 4 bytes selected by sizeClass 0, 1 or 2
+// readLength membaca panjang big-endian 1, 2, atau 4 byte yang dipilih oleh sizeClass 0, 1, atau 2
+func (d *MsgpackDecoder) readLength(sizeClass byte) (int, error) {
+	v, err := d.readUint(1 << sizeClass)
+	if err != nil {
+		return 0, err
+	}
+	if v > maxBinaryLength {
+		return 0, fmt.Errorf("msgpack: length %d exceeds limit", v)
+	}
+	return int(v), nil
+}
+
+// readUint reads a big-endian unsigned integer of size bytes
+// readUint membaca integer unsigned big-endian berukuran size byte
+func (d *MsgpackDecoder) readUint(size int) (uint64, error) {
+	var buf [8]byte
+	if _, err := io.ReadFull(d.r, buf[:size]); err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range buf[:size] {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+func (d *MsgpackDecoder) readBytes(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	_, err := io.ReadFull(d.r, buf)
+	return buf, err
+}
+
+func (d *MsgpackDecoder) readString(n int) (string, error) {
+	buf, err := d.readBytes(n)
+	return string(buf), err
+}
+
+// decodeExt reads an extension body of n bytes, decoding timestamps and returning other extensions as raw bytes
+// decodeExt membaca isi ekstensi sebesar n byte, mendekode timestamp dan mengembalikan ekstensi lain sebagai byte mentah
+func (d *MsgpackDecoder) decodeExt(n int) (interface{}, error) {
+	typ, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	data, err := d.readBytes(n)
+	if err != nil || int8(typ) != msgpackTimestampExt {
+		return data, err
+	}
+	switch n {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(data)), 0), nil
+	case 8:
+		v := binary.BigEndian.Uint64(data)
+		return time.Unix(int64(v&(1<<34-1)), int64(v>>34)), nil
+	case 12:
+		return time.Unix(int64(binary.BigEndian.Uint64(data[4:])), int64(binary.BigEndian.Uint32(data))), nil
+	}
+	return nil, fmt.Errorf("msgpack: invalid timestamp length %d", n)
+}
+
+func (d *MsgpackDecoder) decodeArray(n, depth int) (interface{}, error) {
+	items := make([]interface{}, 0, min(n, 1024))
+	for i := 0; i < n; i++ {
+		item, err := d.decodeValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (d *MsgpackDecoder) decodeMap(n, depth int) (interface{}, error) {
+	m := make(map[string]interface{}, min(n, 1024))
+	for i := 0; i < n; i++ {
+		key, err := d.decodeValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := d.decodeValue(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		m[binaryMapKey(key)] = value
+	}
+	return m, nil
+}
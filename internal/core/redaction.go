@@ -0,0 +1,179 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"crystal/internal/redact"
+)
+
+// redactEntry applies the redactor to the entry's fields in place, once, before hooks and formatters see them
+// redactEntry menerapkan redactor ke field entri secara langsung, sekali, sebelum hook dan formatter melihatnya
+//
+// Masked, partially revealed and pseudonymized values become string fields, so every formatter writes them the same way; dropped fields are removed.
+// Nilai yang disamarkan, ditampilkan sebagian, dan dipseudonimkan menjadi field string, sehingga setiap formatter menulisnya dengan cara yang sama; field yang dibuang dihapus.
+func redactEntry(r *redact.Redactor, entry *LogEntry) {
+	for i := 0; i < entry.FieldsCount; {
+		fp := &entry.Fields[i]
+		rule, ok := r.Match(bToString(fp.Key[:fp.KeyLen]))
+		if !ok {
+			i++
+			continue
+		}
+		value := ""
+		if rule.NeedsValue() {
+			value = fieldValueString(fp)
+		}
+		value, keep := rule.Apply(value)
+		if !keep {
+			// Shift the remaining fields down so the fixed array stays dense
+			// Geser field yang tersisa ke bawah agar array tetap rapat
+			copy(entry.Fields[i:entry.FieldsCount], entry.Fields[i+1:entry.FieldsCount])
+			entry.FieldsCount--
+			entry.Fields[entry.FieldsCount] = FieldPair{}
+			continue
+		}
+		setFieldString(fp, value)
+		i++
+	}
+}
+
+// scanEntry masks PII that the scanner finds inside the message, string field values and error messages
+// scanEntry menyamarkan PII yang ditemukan scanner di dalam pesan, nilai field string, dan pesan error
+//
+// Fields holding a string or error value become string fields when something is masked. An error with PII in its
+// message or chain is replaced by a scannedError, so errors.Is and errors.As no longer see the original in hooks.
+// Field yang berisi nilai string atau error menjadi field string jika ada yang disamarkan. Error dengan PII di pesan
+// atau rantainya diganti dengan scannedError, sehingga errors.Is dan errors.As tidak lagi melihat error asli di hook.
+func scanEntry(sc *redact.Scanner, entry *LogEntry) {
+	if s, ok := sc.Scan(bToString(entry.Message[:entry.MessageLen])); ok {
+		entry.MessageLen = copy(entry.Message[:], s)
+	}
+	for i := 0; i < entry.FieldsCount; i++ {
+		fp := &entry.Fields[i]
+		if fp.IsString {
+			if s, ok := sc.Scan(bToString(fp.StringValue[:fp.StringValueLen])); ok {
+				fp.StringValueLen = copy(fp.StringValue[:], s)
+			}
+			continue
+		}
+		switch v := fp.Value.(type) {
+		case string:
+			if s, ok := sc.Scan(v); ok {
+				setFieldString(fp, s)
+			}
+		case error:
+			if s, ok := sc.Scan(v.Error()); ok {
+				setFieldString(fp, s)
+			}
+		}
+	}
+	if entry.Error != nil {
+		if err, ok := scanError(sc, entry.Error); ok {
+			entry.Error = err
+		}
+	}
+}
+
+// scannedError stands in for an error whose message or wrapped errors contained PII
+// scannedError menggantikan error yang pesan atau error terbungkusnya mengandung PII
+//
+// It reports the original's type name and wraps scanned copies of the original chain, so formatters render the
+// same error, type and causes with the PII masked.
+// Error ini melaporkan nama tipe error asli dan membungkus salinan rantai asli yang telah dipindai, sehingga formatter
+// menampilkan error, tipe, dan penyebab yang sama dengan PII disamarkan.
+type scannedError struct {
+	msg      string    // Scanned message of the original
+	typeName string    // Concrete type of the original, such as *fs.PathError
+	wrapped  []error   // Scanned copies of the errors the original wraps
+	callers  []uintptr // Stack recorded by the original, if any
+}
+
+func (e *scannedError) Error() string { return e.msg }
+
+func (e *scannedError) Unwrap() []error { return e.wrapped }
+
+func (e *scannedError) Callers() []uintptr { return e.callers }
+
+// scanError returns a scannedError copy of err when the scanner masks anything in its message or chain
+// scanError mengembalikan salinan scannedError dari err jika scanner menyamarkan sesuatu di pesan atau rantainya
+func scanError(sc *redact.Scanner, err error) (error, bool) {
+	msg, found := sc.Scan(err.Error())
+	if !found {
+		msg = err.Error()
+	}
+	var wrapped []error
+	for _, inner := range errorChain(err) {
+		copied := &scannedError{typeName: errorTypeName(inner), callers: stackOf(inner)}
+		if s, ok := sc.Scan(inner.Error()); ok {
+			copied.msg, found = s, true
+		} else {
+			copied.msg = inner.Error()
+		}
+		wrapped = append(wrapped, copied)
+	}
+	if !found {
+		return err, false
+	}
+	return &scannedError{msg: msg, typeName: errorTypeName(err), wrapped: wrapped, callers: stackOf(err)}, true
+}
+
+// redactDetails returns a redacted copy of audit details, leaving the caller's map untouched
+// redactDetails mengembalikan salinan detail audit yang telah diredaksi, tanpa mengubah map milik pemanggil
+func redactDetails(r *redact.Redactor, details map[string]interface{}) map[string]interface{} {
+	var out map[string]interface{}
+	for key, value := range details {
+		rule, ok := r.Match(key)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]interface{}, len(details))
+			for k, v := range details {
+				out[k] = v
+			}
+		}
+		s := ""
+		if rule.NeedsValue() {
+			s = fmt.Sprint(value)
+		}
+		if s, keep := rule.Apply(s); keep {
+			out[key] = s
+		} else {
+			delete(out, key)
+		}
+	}
+	if out == nil {
+		return details
+	}
+	return out
+}
+
+// fieldValueString renders a field value as text for rules that use the original value
+// fieldValueString merender nilai field sebagai teks untuk aturan yang menggunakan nilai asli
+func fieldValueString(fp *FieldPair) string {
+	switch {
+	case fp.IsString:
+		return string(fp.StringValue[:fp.StringValueLen])
+	case fp.IsInt:
+		return strconv.FormatInt(fp.IntValue, 10)
+	case fp.IsFloat64:
+		return strconv.FormatFloat(fp.Float64Value, 'f', -1, 64)
+	case fp.IsBool:
+		return strconv.FormatBool(fp.BoolValue)
+	case fp.Value == nil:
+		return ""
+	default:
+		return fmt.Sprint(fp.Value)
+	}
+}
+
+// setFieldString replaces a field's value with a string stored in its fixed buffer
+// setFieldString mengganti nilai field dengan string yang disimpan di buffer tetapnya
+func setFieldString(fp *FieldPair, value string) {
+	fp.Value = nil
+	fp.IsInt, fp.IsFloat64, fp.IsBool = false, false, false
+	fp.IntValue, fp.Float64Value, fp.BoolValue = 0, 0, false
+	fp.StringValueLen = copy(fp.StringValue[:], value)
+	fp.IsString = true
+}
@@ -0,0 +1,221 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"crystal/internal/redact"
+)
+
+func newRedactionTestLogger(out *bytes.Buffer, formatter Formatter, r *redact.Redactor) *Logger {
+	return NewLogger(LoggerConfig{
+		Level:     INFO,
+		Output:    out,
+		Formatter: formatter,
+		Redactor:  r,
+	})
+}
+
+func TestRedactorAppliesToEveryFormatter(t *testing.T) {
+	r := redact.MustNew(append([]redact.Rule{
+		{Pattern: "card_number", Match: redact.Exact, Action: redact.Partial},
+		{Pattern: "debug_*", Match: redact.Glob, Action: redact.Drop},
+	}, redact.DefaultRules()...)...)
+
+	csvFormatter := NewCSVFormatter()
+	csvFormatter.Columns = []string{"message", "fields.password", "fields.card_number", "fields.monkey", CSVExtraFieldsColumn}
+	formatters := map[string]Formatter{
+		"text": NewTextFormatter(),
+		"json": NewJSONFormatter(),
+		"csv":  csvFormatter,
+	}
+	for name, formatter := range formatters {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			logger := newRedactionTestLogger(&out, formatter, r)
+			logger.Info("checkout", "password", "hunter2", "card_number", int64(4111111111111111),
+				"monkey", "george", "debug_dump", "raw-bytes")
+			output := out.String()
+			for _, leaked := range []string{"hunter2", "4111111111111111", "debug_dump", "raw-bytes"} {
+				if strings.Contains(output, leaked) {
+					t.Errorf("Expected %q to be redacted, got %s", leaked, output)
+				}
+			}
+			for _, kept := range []string{redact.DefaultMask, "************1111", "george"} {
+				if !strings.Contains(output, kept) {
+					t.Errorf("Expected %q in output, got %s", kept, output)
+				}
+			}
+		})
+	}
+}
+
+// tokenHook records the token field value it is fired with
+type tokenHook struct{ seen string }
+
+func (h *tokenHook) Levels() []Level { return []Level{INFO} }
+
+func (h *tokenHook) Fire(entry *LogEntry) error {
+	for i := range entry.Fields[:entry.FieldsCount] {
+		if fp := &entry.Fields[i]; string(fp.Key[:fp.KeyLen]) == "token" {
+			h.seen = string(fp.StringValue[:fp.StringValueLen])
+		}
+	}
+	return nil
+}
+
+// errorHook records the error of the entry it is fired with
+type errorHook struct{ seen error }
+
+func (h *errorHook) Levels() []Level { return []Level{ERROR} }
+
+func (h *errorHook) Fire(entry *LogEntry) error {
+	h.seen = entry.Error
+	return nil
+}
+
+func TestRedactorRunsBeforeHooks(t *testing.T) {
+	var out bytes.Buffer
+	logger := newRedactionTestLogger(&out, NewJSONFormatter(), redact.Default())
+	hook := &tokenHook{}
+	logger.AddHook(hook)
+	logger.Info("login", "token", "abc")
+	if hook.seen != redact.DefaultMask {
+		t.Errorf("Expected hooks to see the masked token, got %q", hook.seen)
+	}
+}
+
+func TestRedactorAppliesToAuditDetails(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger(LoggerConfig{Level: INFO, Output: &out, AuditOutput: &out, Formatter: NewJSONFormatter(), Redactor: redact.Default()})
+	details := map[string]interface{}{"api_key": "k-123", "ip": "10.0.0.1"}
+	if err := logger.Audit("USER_LOGIN", "login", "session", "alice", true, details); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var event AuditEvent
+	if err := json.Unmarshal(out.Bytes(), &event); err != nil {
+		t.Fatalf("Unexpected JSON error: %v", err)
+	}
+	if event.Details["api_key"] != redact.DefaultMask || event.Details["ip"] != "10.0.0.1" {
+		t.Errorf("Unexpected audit details %v", event.Details)
+	}
+	if details["api_key"] != "k-123" {
+		t.Error("Expected the caller's details map to be left untouched")
+	}
+}
+
+func TestFormatterMaskingUsesDefaultRules(t *testing.T) {
+	entry := &LogEntry{Level: INFO}
+	entry.MessageLen = copy(entry.Message[:], "typing")
+	entry.SetStringField("keyboard", "qwerty")
+	entry.SetStringField("session_token", "abc")
+
+	formatter := NewJSONFormatter()
+	formatter.MaskSensitiveData = true
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !strings.Contains(string(output), `"keyboard":"qwerty"`) || !strings.Contains(string(output), `"session_token":"***"`) {
+		t.Errorf("Unexpected masked JSON %s", output)
+	}
+}
+
+func TestFormatterMaskingDefersToLoggerRedactor(t *testing.T) {
+	r := redact.MustNew(redact.Rule{Pattern: "amount"})
+	text := NewTextFormatter()
+	text.EnableColors = false
+	jsonFormatter := NewJSONFormatter()
+	logfmt := NewLogfmtFormatter()
+	text.MaskSensitiveData, jsonFormatter.MaskSensitiveData, logfmt.MaskSensitiveData = true, true, true
+
+	for name, formatter := range map[string]Formatter{"text": text, "json": jsonFormatter, "logfmt": logfmt} {
+		var out bytes.Buffer
+		logger := newRedactionTestLogger(&out, formatter, r)
+		logger.Info("charge", "amount", 42, "cvv", 123)
+		output := out.String()
+		if !strings.Contains(output, redact.DefaultMask) || strings.Contains(output, "42") {
+			t.Errorf("%s: expected the logger's Redactor to mask amount, got %s", name, output)
+		}
+		if !strings.Contains(output, "123") {
+			t.Errorf("%s: expected MaskSensitiveData to leave keys outside the Redactor's rules alone, got %s", name, output)
+		}
+	}
+}
+
+func TestValueScannerMasksMessageAndFields(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger(LoggerConfig{Level: INFO, Output: &out, Formatter: NewJSONFormatter(), ValueScanner: redact.NewScanner()})
+	logger.Info("login failed for card 4111111111111111", "contact", "jane@example.com", "attempts", 3)
+
+	var record struct {
+		Message string                 `json:"message"`
+		Fields  map[string]interface{} `json:"fields"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Unexpected JSON error: %v in %s", err, out.Bytes())
+	}
+	if record.Message != "login failed for card [REDACTED:card]" {
+		t.Errorf("Unexpected message %q", record.Message)
+	}
+	if record.Fields["contact"] != "[REDACTED:email]" || record.Fields["attempts"] != float64(3) {
+		t.Errorf("Unexpected fields %v", record.Fields)
+	}
+}
+
+func TestValueScannerMasksErrors(t *testing.T) {
+	var out bytes.Buffer
+	logger := NewLogger(LoggerConfig{Level: INFO, Output: &out, Formatter: NewJSONFormatter(), ValueScanner: redact.NewScanner()})
+	cause := &os.PathError{Op: "open", Path: "/inbox/jane@example.com", Err: os.ErrNotExist}
+	logger.Error("delivery failed", "error", fmt.Errorf("notify: %w", cause), "notify_err", errors.New("bounced for bob@example.com"))
+
+	output := out.String()
+	if strings.Contains(output, "@example.com") {
+		t.Fatalf("Expected every email to be masked, got %s", output)
+	}
+	for _, want := range []string{
+		`"error":"notify: open /inbox/[REDACTED:email]: file does not exist"`,
+		`"error.type":"*fmt.wrapError"`,
+		`{"type":"*fs.PathError","message":"open /inbox/[REDACTED:email]: file does not exist"}`,
+		`"notify_err":"bounced for [REDACTED:email]"`,
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected %s in %s", want, output)
+		}
+	}
+
+	// Errors without PII are left as they are
+	out.Reset()
+	clean := errors.New("timeout")
+	hook := &errorHook{}
+	logger.AddHook(hook)
+	logger.Error("delivery failed", "error", clean)
+	if hook.seen != clean {
+		t.Errorf("Expected the original error when nothing is masked, got %#v", hook.seen)
+	}
+}
+
+func TestPseudonymizedFieldsCorrelateAcrossFormatters(t *testing.T) {
+	p, err := redact.NewPseudonymizer("k1", []byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	r := redact.MustNew(redact.Rule{Pattern: "user_email", Action: redact.Pseudonymize, Pseudonymizer: p})
+	token := p.Token("jane@example.com")
+
+	for name, formatter := range map[string]Formatter{"text": NewTextFormatter(), "json": NewJSONFormatter()} {
+		var out bytes.Buffer
+		logger := newRedactionTestLogger(&out, formatter, r)
+		logger.Error("payment failed", "user_email", "jane@example.com")
+		logger.Error("retry failed", "user_email", "jane@example.com")
+		output := out.String()
+		if strings.Contains(output, "jane@example.com") || strings.Count(output, token) != 2 {
+			t.Errorf("%s: expected both entries to carry %s, got %s", name, token, output)
+		}
+	}
+}
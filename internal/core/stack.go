@@ -0,0 +1,143 @@
+package core
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// crystalFramePrefixes lists function name prefixes of logger internals that are skipped when capturing stack traces
+// crystalFramePrefixes berisi prefix nama fungsi internal logger yang dilewati saat mengambil stack trace
+var crystalFramePrefixes = [...]string{
+	"crystal/internal/core.(*Logger).",
+	"crystal/internal/core.(*AsyncLogger).",
+	"crystal/internal/core.(*Timer).",
+	"crystal/internal/core.captureStack",
+	"runtime.goexit",
+}
+
+// crystalFacadePackages lists the public façade packages, whose package-level functions in crystal.go forward to a logger
+// crystalFacadePackages berisi paket façade publik, yang fungsi tingkat paketnya di crystal.go meneruskan ke logger
+var crystalFacadePackages = [...]string{
+	"crystal.",
+	"crystal/internal.",
+}
+
+// isCrystalFrame reports whether a frame belongs to the logger itself rather than to application code
+// isCrystalFrame melaporkan apakah frame milik logger itu sendiri dan bukan kode aplikasi
+func isCrystalFrame(function, file string) bool {
+	for _, prefix := range crystalFramePrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	// Only the façade files are matched so tests and other code in those packages still appear
+	// Hanya file façade yang dicocokkan agar test dan kode lain dalam paket tersebut tetap muncul
+	if !strings.HasSuffix(file, "/crystal.go") {
+		return false
+	}
+	for _, prefix := range crystalFacadePackages {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// captureStack records up to depth application frames into the entry's fixed stack buffer as "function\n\tfile:line\n" lines
+// captureStack mencatat hingga depth frame aplikasi ke buffer stack tetap entri sebagai baris "function\n\tfile:line\n"
+func captureStack(entry *LogEntry, depth int) {
+	// Use a fixed array so capturing does not allocate a program counter slice
+	// Gunakan array tetap agar pengambilan tidak mengalokasikan slice program counter
+	var pcs [MAX_STACK_DEPTH + 8]uintptr
+	n := runtime.Callers(2, pcs[:])
+	writeStackFrames(entry, pcs[:n], depth)
+}
+
+// writeStackFrames resolves program counters into the entry's stack buffer, skipping logger frames and keeping at most depth frames
+// writeStackFrames menerjemahkan program counter ke buffer stack entri, melewati frame logger dan menyimpan paling banyak depth frame
+func writeStackFrames(entry *LogEntry, pcs []uintptr, depth int) {
+	if depth <= 0 || depth > MAX_STACK_DEPTH {
+		depth = MAX_STACK_DEPTH
+	}
+	frames := runtime.CallersFrames(pcs)
+	written := 0
+	buf := entry.StackTrace[:0]
+	for written < depth {
+		frame, more := frames.Next()
+		if frame.Function != "" && !isCrystalFrame(frame.Function, frame.File) {
+			// Stop before a frame that no longer fits so the buffer only ever holds whole frames
+			// Berhenti sebelum frame yang tidak muat agar buffer hanya berisi frame utuh
+			need := len(frame.Function) + len(frame.File) + 24
+			if len(buf)+need > len(entry.StackTrace) {
+				break
+			}
+			buf = append(buf, frame.Function...)
+			buf = append(buf, '\n', '\t')
+			buf = append(buf, frame.File...)
+			buf = append(buf, ':')
+			buf = strconv.AppendInt(buf, int64(frame.Line), 10)
+			buf = append(buf, '\n')
+			written++
+		}
+		if !more {
+			break
+		}
+	}
+	entry.StackTraceLen = len(buf)
+}
+
+// captureGoroutineID copies the current goroutine's ID into the entry by parsing the "goroutine N [" header of runtime.Stack
+// captureGoroutineID menyalin ID goroutine saat ini ke entri dengan mengurai header "goroutine N [" dari runtime.Stack
+func captureGoroutineID(entry *LogEntry) {
+	// Let runtime.Stack write straight into the entry's pooled buffer; "goroutine " plus 20 digits fits in 32 bytes
+	// Biarkan runtime.Stack menulis langsung ke buffer entri dari pool; "goroutine " ditambah 20 digit muat dalam 32 byte
+	buf := entry.GoroutineID[:]
+	n := runtime.Stack(buf, false)
+	const prefix = "goroutine "
+	entry.GoroutineIDLen = 0
+	if n <= len(prefix) || string(buf[:len(prefix)]) != prefix {
+		return
+	}
+	end := len(prefix)
+	for end < n && buf[end] >= '0' && buf[end] <= '9' {
+		end++
+	}
+	entry.GoroutineIDLen = copy(buf, buf[len(prefix):end])
+}
+
+// forEachStackFrame walks a stack captured by captureStack, stopping after limit frames when limit is positive or when fn returns false
+// forEachStackFrame menelusuri stack yang diambil oleh captureStack, berhenti setelah limit frame jika limit positif atau saat fn mengembalikan false
+func forEachStackFrame(stack string, limit int, fn func(function, file string, line int) bool) {
+	count := 0
+	for len(stack) > 0 && (limit <= 0 || count < limit) {
+		// Each frame is a function line followed by a tab-indented file:line line
+		// Setiap frame adalah baris fungsi diikuti oleh baris file:line yang diindentasi tab
+		nl := strings.IndexByte(stack, '\n')
+		if nl < 0 {
+			return
+		}
+		function := stack[:nl]
+		stack = stack[nl+1:]
+		nl = strings.IndexByte(stack, '\n')
+		if nl < 0 {
+			nl = len(stack)
+		}
+		location := strings.TrimPrefix(stack[:nl], "\t")
+		if nl < len(stack) {
+			stack = stack[nl+1:]
+		} else {
+			stack = ""
+		}
+		file, line := location, 0
+		if colon := strings.LastIndexByte(location, ':'); colon >= 0 {
+			if n, err := strconv.Atoi(location[colon+1:]); err == nil {
+				file, line = location[:colon], n
+			}
+		}
+		count++
+		if !fn(function, file, line) {
+			return
+		}
+	}
+}
@@ -0,0 +1,450 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// SyslogFacility is the syslog facility code combined with the severity into PRI
+// SyslogFacility adalah kode fasilitas syslog yang digabungkan dengan severity menjadi PRI
+type SyslogFacility uint8
+
+// Syslog facilities from RFC 5424 section 6.2.1
+// Fasilitas syslog dari RFC 5424 bagian 6.2.1
+const (
+	FacilityKern     SyslogFacility = 0
+	FacilityUser     SyslogFacility = 1
+	FacilityMail     SyslogFacility = 2
+	FacilityDaemon   SyslogFacility = 3
+	FacilityAuth     SyslogFacility = 4
+	FacilitySyslog   SyslogFacility = 5
+	FacilityLPR      SyslogFacility = 6
+	FacilityNews     SyslogFacility = 7
+	FacilityUUCP     SyslogFacility = 8
+	FacilityCron     SyslogFacility = 9
+	FacilityAuthPriv SyslogFacility = 10
+	FacilityFTP      SyslogFacility = 11
+	FacilityNTP      SyslogFacility = 12
+	FacilityAudit    SyslogFacility = 13
+	FacilityAlert    SyslogFacility = 14
+	FacilityClock    SyslogFacility = 15
+	FacilityLocal0   SyslogFacility = 16
+	FacilityLocal1   SyslogFacility = 17
+	FacilityLocal2   SyslogFacility = 18
+	FacilityLocal3   SyslogFacility = 19
+	FacilityLocal4   SyslogFacility = 20
+	FacilityLocal5   SyslogFacility = 21
+	FacilityLocal6   SyslogFacility = 22
+	FacilityLocal7   SyslogFacility = 23
+)
+
+// SyslogSeverity is the syslog severity code, where lower values are more severe
+// SyslogSeverity adalah kode severity syslog, di mana nilai yang lebih rendah lebih parah
+type SyslogSeverity uint8
+
+// Syslog severities from RFC 5424 section 6.2.1
+// Severity syslog dari RFC 5424 bagian 6.2.1
+const (
+	SeverityEmergency     SyslogSeverity = 0
+	SeverityAlert         SyslogSeverity = 1
+	SeverityCritical      SyslogSeverity = 2
+	SeverityError         SyslogSeverity = 3
+	SeverityWarning       SyslogSeverity = 4
+	SeverityNotice        SyslogSeverity = 5
+	SeverityInformational SyslogSeverity = 6
+	SeverityDebug         SyslogSeverity = 7
+)
+
+// SyslogStandard selects the syslog message layout
+// SyslogStandard memilih tata letak pesan syslog
+type SyslogStandard uint8
+
+const (
+	// SyslogRFC5424 writes "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+	// SyslogRFC5424 menulis "<PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG"
+	SyslogRFC5424 SyslogStandard = iota
+	// SyslogRFC3164 writes the legacy BSD layout "<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG"
+	// SyslogRFC3164 menulis tata letak BSD lama "<PRI>Mmm dd hh:mm:ss HOSTNAME TAG[PID]: MSG"
+	SyslogRFC3164
+)
+
+// defaultSyslogSeverities maps crystal's levels onto syslog severities
+// defaultSyslogSeverities memetakan tingkat crystal ke severity syslog
+var defaultSyslogSeverities = [...]SyslogSeverity{
+	TRACE:  SeverityDebug,
+	DEBUG:  SeverityDebug,
+	INFO:   SeverityInformational,
+	NOTICE: SeverityNotice,
+	WARN:   SeverityWarning,
+	ERROR:  SeverityError,
+	FATAL:  SeverityCritical,
+	PANIC:  SeverityAlert,
+}
+
+// DefaultSyslogSDID is the SD-ID used for fields; 32473 is the private enterprise number reserved for documentation
+// DefaultSyslogSDID adalah SD-ID yang digunakan untuk field; 32473 adalah nomor enterprise privat yang dicadangkan untuk dokumentasi
+const DefaultSyslogSDID = "crystal@32473"
+
+// RFC 5424 header field limits
+// Batas field header RFC 5424
+const (
+	syslogMaxHostname  = 255
+	syslogMaxAppName   = 48
+	syslogMaxMsgID     = 32
+	syslogMaxParamName = 32
+	syslogMaxTag       = 32
+)
+
+// syslogTimestampFormat is RFC 3339 limited to the six fractional digits RFC 5424 allows
+// syslogTimestampFormat adalah RFC 3339 yang dibatasi enam digit pecahan yang diizinkan RFC 5424
+const syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// SyslogFormatter writes log entries as RFC 5424 or RFC 3164 syslog messages for rsyslog, syslog-ng and similar collectors
+// SyslogFormatter menulis entri log sebagai pesan syslog RFC 5424 atau RFC 3164 untuk rsyslog, syslog-ng, dan kolektor serupa
+type SyslogFormatter struct {
+	Standard      SyslogStandard           // Standard selects RFC 5424 (default) or legacy RFC 3164 output
+	Facility      SyslogFacility           // Facility is combined with the severity into PRI; FacilityKern is reserved for the kernel and means FacilityUser
+	SeverityMap   map[Level]SyslogSeverity // SeverityMap overrides the default level to severity table for the levels it contains
+	Hostname      string                   // Hostname overrides the entry hostname
+	AppName       string                   // AppName overrides the entry application name
+	MsgID         string                   // MsgID is the RFC 5424 MSGID, "-" when empty
+	MsgIDField    string                   // MsgIDField names a string field used as MSGID when present; that field is not repeated in STRUCTURED-DATA
+	SDID          string                   // SDID is the SD-ID of the element holding fields, DefaultSyslogSDID when empty
+	OctetCounting bool                     // OctetCounting prefixes each frame with its length (RFC 6587) instead of ending it with a newline
+}
+
+// NewSyslogFormatter creates a new RFC 5424 SyslogFormatter for the user facility
+// NewSyslogFormatter membuat SyslogFormatter RFC 5424 baru untuk fasilitas user
+func NewSyslogFormatter() *SyslogFormatter {
+	return &SyslogFormatter{
+		Standard: SyslogRFC5424,
+		Facility: FacilityUser,
+		SDID:     DefaultSyslogSDID,
+	}
+}
+
+// Severity returns the syslog severity used for level
+// Severity mengembalikan severity syslog yang digunakan untuk level
+func (f *SyslogFormatter) Severity(level Level) SyslogSeverity {
+	return syslogSeverity(f.SeverityMap, level)
+}
+
+// syslogSeverity looks level up in overrides before falling back to the default table
+// syslogSeverity mencari level dalam overrides sebelum kembali ke tabel default
+func syslogSeverity(overrides map[Level]SyslogSeverity, level Level) SyslogSeverity {
+	if severity, ok := overrides[level]; ok {
+		return severity
+	}
+	if int(level) < len(defaultSyslogSeverities) {
+		return defaultSyslogSeverities[level]
+	}
+	return SeverityError
+}
+
+// Format formats a log entry as one syslog frame
+// Format memformat entri log sebagai satu frame syslog
+func (f *SyslogFormatter) Format(entry interface{}) ([]byte, error) {
+	// Cast entry to LogEntryInterface
+	logEntry, ok := entry.(LogEntryInterface)
+	if !ok {
+		return nil, fmt.Errorf("invalid entry type")
+	}
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	var b []byte
+	if f.Standard == SyslogRFC3164 {
+		b = f.appendRFC3164(buf.buf[:0], logEntry)
+	} else {
+		b = f.appendRFC5424(buf.buf[:0], logEntry)
+	}
+	if !f.OctetCounting {
+		b = append(b, '\n')
+		// Return copy to avoid buffer reuse issues and ensure memory safety
+		// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+		result := make([]byte, len(b))
+		copy(result, b)
+		return result, nil
+	}
+	// Octet counting lets stream receivers split frames that contain newlines
+	// Octet counting memungkinkan penerima stream memisahkan frame yang berisi baris baru
+	var prefix [24]byte
+	p := strconv.AppendInt(prefix[:0], int64(len(b)), 10)
+	p = append(p, ' ')
+	result := make([]byte, len(p)+len(b))
+	copy(result, p)
+	copy(result[len(p):], b)
+	return result, nil
+}
+
+// appendPRI appends "<PRI>" for the entry level
+// appendPRI menambahkan "<PRI>" untuk tingkat entri
+func (f *SyslogFormatter) appendPRI(b []byte, level Level) []byte {
+	facility := f.Facility
+	if facility == FacilityKern {
+		facility = FacilityUser
+	}
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(facility)*8+int64(f.Severity(level)), 10)
+	return append(b, '>')
+}
+
+// appendRFC5424 appends an RFC 5424 message without framing
+// appendRFC5424 menambahkan pesan RFC 5424 tanpa framing
+func (f *SyslogFormatter) appendRFC5424(b []byte, logEntry LogEntryInterface) []byte {
+	b = f.appendPRI(b, logEntry.GetLevel())
+	b = append(b, '1', ' ')
+	if ts := logEntry.GetTimestamp(); ts.IsZero() {
+		b = append(b, '-')
+	} else {
+		b = ts.AppendFormat(b, syslogTimestampFormat)
+	}
+	b = append(b, ' ')
+	b = appendSyslogHeaderField(b, f.hostname(logEntry), syslogMaxHostname)
+	b = append(b, ' ')
+	b = appendSyslogHeaderField(b, f.appName(logEntry), syslogMaxAppName)
+	b = append(b, ' ')
+	if pid := logEntry.GetPID(); pid > 0 {
+		b = strconv.AppendInt(b, int64(pid), 10)
+	} else {
+		b = append(b, '-')
+	}
+	b = append(b, ' ')
+	msgID, fromField := f.msgID(logEntry)
+	b = appendSyslogHeaderField(b, msgID, syslogMaxMsgID)
+	b = append(b, ' ')
+	b = f.appendStructuredData(b, logEntry, fromField)
+	if msg := logEntry.GetMessage(); msg != "" {
+		b = append(b, ' ')
+		b = f.appendMessage(b, msg)
+	}
+	return b
+}
+
+// appendStructuredData appends one SD-ELEMENT holding the trace IDs, error and fields, or "-" when there is nothing to write
+// appendStructuredData menambahkan satu SD-ELEMENT berisi ID tracing, error, dan field, atau "-" jika tidak ada yang ditulis
+func (f *SyslogFormatter) appendStructuredData(b []byte, logEntry LogEntryInterface, skipMsgIDField bool) []byte {
+	start := len(b)
+	b = append(b, '[')
+	sdID := f.SDID
+	if sdID == "" {
+		sdID = DefaultSyslogSDID
+	}
+	b = appendSyslogParamName(b, sdID)
+	params := len(b)
+	b = appendSyslogParamIfSet(b, "trace_id", logEntry.GetTraceID())
+	b = appendSyslogParamIfSet(b, "span_id", logEntry.GetSpanID())
+	b = appendSyslogParamIfSet(b, "user_id", logEntry.GetUserID())
+	b = appendSyslogParamIfSet(b, "session_id", logEntry.GetSessionID())
+	b = appendSyslogParamIfSet(b, "request_id", logEntry.GetRequestID())
+	if err := logEntry.GetError(); err != nil {
+		b = appendSyslogParamIfSet(b, "error", err.Error())
+		b = appendSyslogParamIfSet(b, "error.type", errorTypeName(err))
+	}
+	fields := entryFieldPairs(logEntry)
+	for i := range fields {
+		key := bToString(fields[i].Key[:fields[i].KeyLen])
+		if fieldOverridden(fields, i) || key == "" || (skipMsgIDField && key == f.MsgIDField) {
+			continue
+		}
+		b = append(b, ' ')
+		b = appendSyslogParamName(b, key)
+		b = append(b, '=', '"')
+		b = appendSyslogParamValue(b, &fields[i])
+		b = append(b, '"')
+	}
+	if len(b) == params {
+		return append(b[:start], '-')
+	}
+	return append(b, ']')
+}
+
+// appendRFC3164 appends a legacy BSD syslog message without framing, writing fields as logfmt after the message
+// appendRFC3164 menambahkan pesan syslog BSD lama tanpa framing, menulis field sebagai logfmt setelah pesan
+func (f *SyslogFormatter) appendRFC3164(b []byte, logEntry LogEntryInterface) []byte {
+	b = f.appendPRI(b, logEntry.GetLevel())
+	ts := logEntry.GetTimestamp()
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+	b = ts.AppendFormat(b, time.Stamp)
+	b = append(b, ' ')
+	b = appendSyslogHeaderField(b, f.hostname(logEntry), syslogMaxHostname)
+	b = append(b, ' ')
+	// TAG is limited to 32 alphanumeric characters in RFC 3164
+	// TAG dibatasi 32 karakter alfanumerik dalam RFC 3164
+	tag := f.appName(logEntry)
+	if tag == "" {
+		tag = "crystal"
+	}
+	n := 0
+	for i := 0; i < len(tag) && n < syslogMaxTag; i++ {
+		if c := tag[i]; c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-' || c == '_' || c == '.' || c == '/' {
+			b = append(b, c)
+			n++
+		}
+	}
+	if pid := logEntry.GetPID(); pid > 0 {
+		b = append(b, '[')
+		b = strconv.AppendInt(b, int64(pid), 10)
+		b = append(b, ']')
+	}
+	b = append(b, ':', ' ')
+	b = f.appendMessage(b, logEntry.GetMessage())
+	fields := entryFieldPairs(logEntry)
+	for i := range fields {
+		key := bToString(fields[i].Key[:fields[i].KeyLen])
+		if fieldOverridden(fields, i) || key == "" {
+			continue
+		}
+		b = append(b, ' ')
+		b = appendLogfmtKey(b, key)
+		b = append(b, '=')
+		b = appendLogfmtFieldValue(b, &fields[i])
+	}
+	if err := logEntry.GetError(); err != nil {
+		b = append(b, " error="...)
+		b = appendLogfmtString(b, err.Error())
+	}
+	return b
+}
+
+// appendMessage appends MSG, replacing line breaks with spaces unless frames are octet counted
+// appendMessage menambahkan MSG, mengganti pemisah baris dengan spasi kecuali frame menggunakan octet counting
+func (f *SyslogFormatter) appendMessage(b []byte, msg string) []byte {
+	if f.OctetCounting {
+		return append(b, msg...)
+	}
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c == '\n' || c == '\r' {
+			c = ' '
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// hostname returns the configured hostname or the entry hostname
+// hostname mengembalikan hostname yang dikonfigurasi atau hostname entri
+func (f *SyslogFormatter) hostname(logEntry LogEntryInterface) string {
+	if f.Hostname != "" {
+		return f.Hostname
+	}
+	return logEntry.GetHostname()
+}
+
+// appName returns the configured application name or the entry application
+// appName mengembalikan nama aplikasi yang dikonfigurasi atau aplikasi entri
+func (f *SyslogFormatter) appName(logEntry LogEntryInterface) string {
+	if f.AppName != "" {
+		return f.AppName
+	}
+	return logEntry.GetApplication()
+}
+
+// msgID returns the MSGID and whether it was taken from MsgIDField
+// msgID mengembalikan MSGID dan apakah nilainya diambil dari MsgIDField
+func (f *SyslogFormatter) msgID(logEntry LogEntryInterface) (string, bool) {
+	if f.MsgIDField != "" {
+		fields := entryFieldPairs(logEntry)
+		for i := len(fields) - 1; i >= 0; i-- {
+			if bToString(fields[i].Key[:fields[i].KeyLen]) != f.MsgIDField {
+				continue
+			}
+			value, _ := fields[i].Value.(string)
+			if fields[i].IsString {
+				value = bToString(fields[i].StringValue[:fields[i].StringValueLen])
+			}
+			if value != "" {
+				return value, true
+			}
+			break
+		}
+	}
+	return f.MsgID, false
+}
+
+// appendSyslogHeaderField appends a header field as printable US-ASCII of at most max bytes, "-" when empty
+// appendSyslogHeaderField menambahkan field header sebagai US-ASCII yang dapat dicetak dengan panjang maksimal max byte, "-" jika kosong
+func appendSyslogHeaderField(b []byte, value string, max int) []byte {
+	if value == "" {
+		return append(b, '-')
+	}
+	if len(value) > max {
+		value = value[:max]
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < 33 || c > 126 {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// appendSyslogParamName appends an SD-NAME, replacing characters RFC 5424 forbids and truncating to 32 bytes
+// appendSyslogParamName menambahkan SD-NAME, mengganti karakter yang dilarang RFC 5424 dan memotong menjadi 32 byte
+func appendSyslogParamName(b []byte, name string) []byte {
+	if len(name) > syslogMaxParamName {
+		name = name[:syslogMaxParamName]
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' || c == ' ' {
+			c = '_'
+		}
+		b = append(b, c)
+	}
+	return b
+}
+
+// appendSyslogParamIfSet appends ` name="value"` when value is non-empty
+// appendSyslogParamIfSet menambahkan ` name="value"` jika nilai tidak kosong
+func appendSyslogParamIfSet(b []byte, name, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = append(b, ' ')
+	b = appendSyslogParamName(b, name)
+	b = append(b, '=', '"')
+	b = appendSyslogEscaped(b, value)
+	return append(b, '"')
+}
+
+// appendSyslogParamValue appends a field value as PARAM-VALUE text, without the surrounding quotes
+// appendSyslogParamValue menambahkan nilai field sebagai teks PARAM-VALUE, tanpa tanda kutip pembungkus
+func appendSyslogParamValue(b []byte, fp *FieldPair) []byte {
+	switch {
+	case fp.IsString:
+		return appendSyslogEscaped(b, bToString(fp.StringValue[:fp.StringValueLen]))
+	case fp.IsInt:
+		return strconv.AppendInt(b, fp.IntValue, 10)
+	case fp.IsFloat64:
+		return strconv.AppendFloat(b, fp.Float64Value, 'g', -1, 64)
+	case fp.IsBool:
+		return strconv.AppendBool(b, fp.BoolValue)
+	}
+	switch v := fp.Value.(type) {
+	case string:
+		return appendSyslogEscaped(b, v)
+	case error:
+		return appendSyslogEscaped(b, v.Error())
+	case fmt.Stringer:
+		return appendSyslogEscaped(b, v.String())
+	}
+	return appendSyslogEscaped(b, fmt.Sprint(fp.Value))
+}
+
+// appendSyslogEscaped escapes '"', '\' and ']' with a backslash as RFC 5424 section 6.3.3 requires
+// appendSyslogEscaped meng-escape '"', '\', dan ']' dengan backslash sesuai RFC 5424 bagian 6.3.3
+func appendSyslogEscaped(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c == '"' || c == '\\' || c == ']' {
+			b = append(b, '\\')
+		}
+		b = append(b, s[i])
+	}
+	return b
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestSyslogFormatterRFC5424(t *testing.T) {
+	formatter := NewSyslogFormatter()
+	formatter.Facility = FacilityLocal3
+	formatter.MsgIDField = "event"
+	entry := newTestEntry(WARN, "disk almost full", "event", "DISKWARN", "path", `C:\data "main" [x]`, "free_pct", 7)
+	entry.Timestamp = testEntryTime.Add(123456789)
+	entry.PID = 4242
+	entry.HostnameLen = copy(entry.Hostname[:], "web-1")
+	entry.ApplicationLen = copy(entry.Application[:], "billing")
+	entry.TraceIDLen = copy(entry.TraceID[:], "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// local3 (19) * 8 + warning (4) = 156
+	want := `<156>1 2024-05-01T12:00:00.123456Z web-1 billing 4242 DISKWARN ` +
+		`[crystal@32473 trace_id="4bf92f3577b34da6a3ce929d0e0e4736" path="C:\\data \"main\" [x\]" free_pct="7"] disk almost full` + "\n"
+	if string(output) != want {
+		t.Errorf("Unexpected frame\n got: %s\nwant: %s", output, want)
+	}
+
+	// Without parameters the STRUCTURED-DATA is the NILVALUE
+	entry = &LogEntry{Level: INFO}
+	entry.MessageLen = copy(entry.Message[:], "line1\nline2")
+	output, err = formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "<158>1 - - - - - - line1 line2\n"; string(output) != want {
+		t.Errorf("Unexpected frame\n got: %q\nwant: %q", output, want)
+	}
+}
+
+func TestSyslogFormatterSeverityMap(t *testing.T) {
+	formatter := &SyslogFormatter{SeverityMap: map[Level]SyslogSeverity{PANIC: SeverityEmergency}}
+	tests := map[Level]int{
+		TRACE:  1*8 + 7,
+		DEBUG:  1*8 + 7,
+		INFO:   1*8 + 6,
+		NOTICE: 1*8 + 5,
+		WARN:   1*8 + 4,
+		ERROR:  1*8 + 3,
+		FATAL:  1*8 + 2,
+		PANIC:  1*8 + 0,
+	}
+	for level, pri := range tests {
+		output, err := formatter.Format(&LogEntry{Level: level})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if prefix := "<" + strconv.Itoa(pri) + ">1 "; !strings.HasPrefix(string(output), prefix) {
+			t.Errorf("Level %s: expected prefix %q, got %q", level, prefix, output)
+		}
+	}
+}
+
+func TestSyslogFormatterRFC3164AndOctetCounting(t *testing.T) {
+	formatter := &SyslogFormatter{Standard: SyslogRFC3164, Facility: FacilityDaemon, OctetCounting: true}
+	entry := newTestEntry(WARN, "disk almost full", "event", "DISKWARN", "path", `C:\data "main" [x]`, "free_pct", 7)
+	entry.PID = 4242
+	entry.HostnameLen = copy(entry.Hostname[:], "web-1")
+	entry.ApplicationLen = copy(entry.Application[:], "billing")
+	entry.Error = errors.New("quota exceeded")
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	frame := `<28>May  1 12:00:00 web-1 billing[4242]: disk almost full event=DISKWARN path="C:\\data \"main\" [x]" free_pct=7 error="quota exceeded"`
+	if want := strconv.Itoa(len(frame)) + " " + frame; string(output) != want {
+		t.Errorf("Unexpected frame\n got: %s\nwant: %s", output, want)
+	}
+
+	// Octet counting keeps embedded newlines intact
+	entry.MessageLen = copy(entry.Message[:], "first\nsecond")
+	output, err = formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	length, frameText, ok := strings.Cut(string(output), " ")
+	if n, _ := strconv.Atoi(length); !ok || n != len(frameText) || !strings.Contains(frameText, "first\nsecond") {
+		t.Errorf("Unexpected octet-counted frame %q", output)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync/atomic"
+
+	"crystal/internal/redact"
 )
 
 // Pre-computed constants for formatting to avoid runtime lookups and minimize allocations during log formatting
@@ -35,11 +38,15 @@ type TextFormatter struct {
 	FullTimestamp         bool   // FullTimestamp controls whether full timestamp format is used
 	TimestampFormat       string // TimestampFormat specifies the format string for timestamps
 	EnableStackTrace      bool   // EnableStackTrace controls whether stack traces are captured for errors
-	StackTraceDepth       int    // StackTraceDepth limits the depth of captured stack traces
+	StackTraceDepth       int    // StackTraceDepth limits the number of stack frames rendered, 0 renders all
 	EnableDuration        bool   // EnableDuration controls whether duration measurements are included
 	MaxFieldWidth         int    // MaxFieldWidth limits the width of field values to prevent overly long output
-	MaskSensitiveData     bool   // MaskSensitiveData controls whether sensitive data is masked in output
+	MaskSensitiveData     bool   // MaskSensitiveData masks keys matching the default rules when the logger has no Redactor
 	MaskString            string // MaskString specifies the string used to mask sensitive data
+	Layout                string // Layout replaces the fixed order with a template such as "{time} {level:-5} {msg} {fields}", see SetLayout
+
+	layout   atomic.Pointer[textLayout] // Compiled Layout, rebuilt when Layout changes
+	redacted bool                       // The logger's Redactor has already redacted the fields
 }
 
 // NewTextFormatter creates a new TextFormatter with default settings
@@ -74,6 +81,11 @@ func (f *TextFormatter) Format(entry interface{}) ([]byte, error) {
 	if !ok {
 		return nil, fmt.Errorf("invalid entry type")
 	}
+	// A layout template replaces the fixed order below
+	// Template layout menggantikan urutan tetap di bawah
+	if f.Layout != "" {
+		return f.formatLayout(logEntry)
+	}
 	
 	// Get a buffer from pool to avoid allocation and enable reuse
 	// Dapatkan buffer dari pool untuk menghindari alokasi dan memungkinkan penggunaan kembali
@@ -313,6 +325,18 @@ func (f *TextFormatter) Format(entry interface{}) ([]byte, error) {
 		buf.WriteByte(' ')
 		f.formatMetrics(buf, metrics)
 	}
+	// Write duration measurement if enabled and available
+	// Tulis pengukuran durasi jika diaktifkan dan tersedia
+	if f.EnableDuration && logEntry.GetDuration() > 0 {
+		buf.WriteString(" duration=")
+		buf.WriteString(logEntry.GetDuration().String())
+	}
+	// Write error details with red coloring so failures stand out
+	// Tulis detail error dengan pewarnaan merah agar kegagalan menonjol
+	if err := logEntry.GetError(); err != nil {
+		buf.WriteByte(' ')
+		f.formatError(buf, err)
+	}
 	// Write stack trace if enabled and available with gray coloring
 	// Tulis stack trace jika diaktifkan dan tersedia dengan pewarnaan abu-abu
 	if f.EnableStackTrace && logEntry.GetStackTrace() != "" {
@@ -320,9 +344,7 @@ func (f *TextFormatter) Format(entry interface{}) ([]byte, error) {
 		if f.EnableColors {
 			buf.WriteString("\033[38;5;240m") // Dark gray color for stack traces
 		}
-		// Direct buffer write to avoid string allocation
-		// Tulis buffer langsung untuk menghindari alokasi string
-		buf.WriteString(logEntry.GetStackTrace())
+		f.formatStackTrace(buf, logEntry.GetStackTrace())
 		if f.EnableColors {
 			buf.WriteString("\033[0m") // Reset color
 		}
@@ -351,6 +373,50 @@ func (f *TextFormatter) writeShortID(buf *ByteArray, id []byte) {
 	}
 }
 
+// formatError writes the error message, its concrete type and the wrapped chain as key=value pairs
+// formatError menulis pesan error, tipe konkretnya, dan rantai error terbungkus sebagai pasangan key=value
+func (f *TextFormatter) formatError(buf *ByteArray, err error) {
+	if f.EnableColors {
+		buf.WriteString("\033[31m") // Red color for errors
+	}
+	buf.WriteString("error=")
+	buf.WriteString(strconv.Quote(err.Error()))
+	buf.WriteString(" error.type=")
+	buf.WriteString(errorTypeName(err))
+	if chain := errorChain(err); len(chain) > 0 {
+		buf.WriteString(" error.chain=[")
+		for i, wrapped := range chain {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(strconv.Quote(errorLink(wrapped)))
+		}
+		buf.WriteByte(']')
+	}
+	if f.EnableColors {
+		buf.WriteString("\033[0m") // Reset color
+	}
+}
+
+// formatStackTrace writes captured frames as an indented block, limited to StackTraceDepth frames
+// formatStackTrace menulis frame yang diambil sebagai blok terindentasi, dibatasi hingga StackTraceDepth frame
+func (f *TextFormatter) formatStackTrace(buf *ByteArray, stack string) {
+	first := true
+	forEachStackFrame(stack, f.StackTraceDepth, func(function, file string, line int) bool {
+		if !first {
+			buf.WriteByte('\n')
+		}
+		first = false
+		buf.WriteString("    ")
+		buf.WriteString(function)
+		buf.WriteString("\n        ")
+		buf.WriteString(file)
+		buf.WriteByte(':')
+		buf.WriteString(strconv.Itoa(line))
+		return true
+	})
+}
+
 // formatFields formats structured logging fields with optional coloring for enhanced readability
 // formatFields memformat field logging terstruktur dengan pewarnaan opsional untuk meningkatkan keterbacaan
 func (f *TextFormatter) formatFields(buf *ByteArray, fields []interface{}) {
@@ -371,116 +437,7 @@ func (f *TextFormatter) formatFields(buf *ByteArray, fields []interface{}) {
 		// Handle different field types
 		// Tangani tipe field yang berbeda
 		if fp, ok := field.(FieldPair); ok {
-			// Write field key with optional coloring
-			// Tulis key field dengan pewarnaan opsional
-			if f.EnableColors {
-				buf.WriteString("\033[38;5;75m") // Blue color for field keys
-			}
-			// Direct buffer write to avoid string allocation
-			// Tulis buffer langsung untuk menghindari alokasi string
-			buf.Write(fp.Key[:fp.KeyLen])
-			if f.EnableColors {
-				buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
-			}
-			buf.WriteByte('=')
-			// Write field value with optional coloring
-			// Tulis nilai field dengan pewarnaan opsional
-			if f.EnableColors {
-				buf.WriteString("\033[38;5;150m") // Green color for field values
-			}
-			// Handle zero-allocation fields
-			// Tangani field zero-allocation
-			if fp.IsString {
-				// For zero-allocation strings, add quotes and handle escaping
-				// Untuk string zero-allocation, tambahkan tanda kutip dan tangani escaping
-				buf.WriteByte('"')
-				// Escape quotes in string values to maintain valid output
-				// Escape tanda kutip dalam nilai string untuk mempertahankan output yang valid
-				valueStr := string(fp.StringValue[:fp.StringValueLen])
-				
-				// Mask sensitive data if enabled
-				if f.MaskSensitiveData {
-					// Check if field key indicates sensitive data
-					keyStr := string(fp.Key[:fp.KeyLen])
-					if strings.Contains(strings.ToLower(keyStr), "password") ||
-					   strings.Contains(strings.ToLower(keyStr), "token") ||
-					   strings.Contains(strings.ToLower(keyStr), "secret") ||
-					   strings.Contains(strings.ToLower(keyStr), "key") {
-						valueStr = f.MaskString
-					}
-				}
-				
-				escaped := strings.ReplaceAll(valueStr, "\"", "\\\"")
-				buf.WriteString(escaped)
-				buf.WriteByte('"')
-			} else if fp.IsInt {
-				// For zero-allocation integers, convert to string without allocation
-				// Untuk integer zero-allocation, konversi ke string tanpa alokasi
-				buf.WriteString(strconv.FormatInt(fp.IntValue, 10))
-			} else if fp.IsFloat64 {
-				// For zero-allocation floats, convert to string without allocation
-				// Untuk float zero-allocation, konversi ke string tanpa alokasi
-				buf.WriteString(strconv.FormatFloat(fp.Float64Value, 'g', -1, 64))
-			} else if fp.IsBool {
-				// For zero-allocation booleans, convert to string without allocation
-				// Untuk boolean zero-allocation, konversi ke string tanpa alokasi
-				if fp.BoolValue {
-					buf.WriteString("true")
-				} else {
-					buf.WriteString("false")
-				}
-			} else {
-				// For interface{} fields, use the standard approach
-				// Untuk field interface{}, gunakan pendekatan standar
-				switch v := fp.Value.(type) {
-				case string:
-					// For strings, add quotes and handle escaping
-					// Untuk string, tambahkan tanda kutip dan tangani escaping
-					buf.WriteByte('"')
-					// Escape quotes in string values to maintain valid output
-					// Escape tanda kutip dalam nilai string untuk mempertahankan output yang valid
-					escaped := strings.ReplaceAll(v, "\"", "\\\"")
-					buf.WriteString(escaped)
-					buf.WriteByte('"')
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-					// For integers, convert to string without allocation where possible
-					// Untuk integer, konversi ke string tanpa alokasi jika memungkinkan
-					buf.WriteString(fmt.Sprintf("%v", v))
-				case float32, float64:
-					// For floats, convert to string without allocation where possible
-					// Untuk float, konversi ke string tanpa alokasi jika memungkinkan
-					buf.WriteString(fmt.Sprintf("%v", v))
-				case bool:
-					// For booleans, convert to string without allocation
-					// Untuk boolean, konversi ke string tanpa alokasi
-					if v {
-						buf.WriteString("true")
-					} else {
-						buf.WriteString("false")
-					}
-				default:
-					// For other types, use standard formatting
-					// Untuk tipe lain, gunakan formatting standar
-					valueStr := fmt.Sprintf("%v", v)
-					
-					// Mask sensitive data if enabled
-					if f.MaskSensitiveData {
-						// Check if field key indicates sensitive data
-						keyStr := string(fp.Key[:fp.KeyLen])
-						if strings.Contains(strings.ToLower(keyStr), "password") ||
-						   strings.Contains(strings.ToLower(keyStr), "token") ||
-						   strings.Contains(strings.ToLower(keyStr), "secret") ||
-						   strings.Contains(strings.ToLower(keyStr), "key") {
-							valueStr = f.MaskString
-						}
-					}
-					
-					buf.WriteString(valueStr)
-				}
-			}
-			if f.EnableColors {
-				buf.WriteString("\033[0m") // Reset color
-			}
+			f.formatFieldPair(buf, &fp)
 		} else {
 			// Handle non-FieldPair fields (backward compatibility)
 			// Tangani field non-FieldPair (kompatibilitas mundur)
@@ -504,6 +461,135 @@ func (f *TextFormatter) formatFields(buf *ByteArray, fields []interface{}) {
 	}
 }
 
+// formatFieldPairs formats fields like formatFields without boxing them, for layout templates
+// formatFieldPairs memformat field seperti formatFields tanpa boxing, untuk template layout
+func (f *TextFormatter) formatFieldPairs(buf *ByteArray, fields []FieldPair) {
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
+	}
+	buf.WriteByte('{')
+	for i := range fields {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		f.formatFieldPair(buf, &fields[i])
+	}
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
+	}
+	buf.WriteByte('}')
+	if f.EnableColors {
+		buf.WriteString("\033[0m") // Reset color
+	}
+}
+
+// formatFieldPair writes one field as key=value with optional coloring
+// formatFieldPair menulis satu field sebagai key=value dengan pewarnaan opsional
+func (f *TextFormatter) formatFieldPair(buf *ByteArray, fp *FieldPair) {
+	// Write field key with optional coloring
+	// Tulis key field dengan pewarnaan opsional
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;75m") // Blue color for field keys
+	}
+	// Direct buffer write to avoid string allocation
+	// Tulis buffer langsung untuk menghindari alokasi string
+	buf.Write(fp.Key[:fp.KeyLen])
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
+	}
+	buf.WriteByte('=')
+	// Write field value with optional coloring
+	// Tulis nilai field dengan pewarnaan opsional
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;150m") // Green color for field values
+	}
+	// Handle zero-allocation fields
+	// Tangani field zero-allocation
+	// Mask sensitive values before the type switch so numbers and booleans such as cvv=123 are masked too
+	// Samarkan nilai sensitif sebelum pemeriksaan tipe agar angka dan boolean seperti cvv=123 juga disamarkan
+	if f.masksField(fp) {
+		buf.WriteByte('"')
+		buf.WriteString(strings.ReplaceAll(f.MaskString, "\"", "\\\""))
+		buf.WriteByte('"')
+	} else if fp.IsString {
+		// For zero-allocation strings, add quotes and handle escaping
+		// Untuk string zero-allocation, tambahkan tanda kutip dan tangani escaping
+		buf.WriteByte('"')
+		// Escape quotes in string values to maintain valid output
+		// Escape tanda kutip dalam nilai string untuk mempertahankan output yang valid
+		escaped := strings.ReplaceAll(bToString(fp.StringValue[:fp.StringValueLen]), "\"", "\\\"")
+		buf.WriteString(escaped)
+		buf.WriteByte('"')
+	} else if fp.IsInt {
+		// For zero-allocation integers, convert to string without allocation
+		// Untuk integer zero-allocation, konversi ke string tanpa alokasi
+		var scratch [32]byte
+		buf.Write(strconv.AppendInt(scratch[:0], fp.IntValue, 10))
+	} else if fp.IsFloat64 {
+		// For zero-allocation floats, convert to string without allocation
+		// Untuk float zero-allocation, konversi ke string tanpa alokasi
+		var scratch [32]byte
+		buf.Write(strconv.AppendFloat(scratch[:0], fp.Float64Value, 'g', -1, 64))
+	} else if fp.IsBool {
+		// For zero-allocation booleans, convert to string without allocation
+		// Untuk boolean zero-allocation, konversi ke string tanpa alokasi
+		if fp.BoolValue {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	} else {
+		// For interface{} fields, use the standard approach
+		// Untuk field interface{}, gunakan pendekatan standar
+		switch v := fp.Value.(type) {
+		case string:
+			// For strings, add quotes and handle escaping
+			// Untuk string, tambahkan tanda kutip dan tangani escaping
+			buf.WriteByte('"')
+			// Escape quotes in string values to maintain valid output
+			// Escape tanda kutip dalam nilai string untuk mempertahankan output yang valid
+			escaped := strings.ReplaceAll(v, "\"", "\\\"")
+			buf.WriteString(escaped)
+			buf.WriteByte('"')
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			// For integers, convert to string without allocation where possible
+			// Untuk integer, konversi ke string tanpa alokasi jika memungkinkan
+			buf.WriteString(fmt.Sprintf("%v", v))
+		case float32, float64:
+			// For floats, convert to string without allocation where possible
+			// Untuk float, konversi ke string tanpa alokasi jika memungkinkan
+			buf.WriteString(fmt.Sprintf("%v", v))
+		case bool:
+			// For booleans, convert to string without allocation
+			// Untuk boolean, konversi ke string tanpa alokasi
+			if v {
+				buf.WriteString("true")
+			} else {
+				buf.WriteString("false")
+			}
+		default:
+			// For other types, use standard formatting
+			// Untuk tipe lain, gunakan formatting standar
+			buf.WriteString(fmt.Sprintf("%v", v))
+		}
+	}
+	if f.EnableColors {
+		buf.WriteString("\033[0m") // Reset color
+	}
+}
+
+// setRedactor records whether the logger redacts fields before they are formatted
+// setRedactor mencatat apakah logger meredaksi field sebelum diformat
+func (f *TextFormatter) setRedactor(r *redact.Redactor) {
+	f.redacted = r != nil
+}
+
+// masksField reports whether MaskSensitiveData hides the field
+// masksField melaporkan apakah MaskSensitiveData menyembunyikan field
+func (f *TextFormatter) masksField(fp *FieldPair) bool {
+	return masksKey(f.MaskSensitiveData, f.redacted, bToString(fp.Key[:fp.KeyLen]))
+}
+
 // formatTags formats log entry tags with optional coloring for categorization
 // formatTags memformat tag entri log dengan pewarnaan opsional untuk kategorisasi
 func (f *TextFormatter) formatTags(buf *ByteArray, tags []string) {
@@ -561,29 +647,7 @@ func (f *TextFormatter) formatMetrics(buf *ByteArray, metrics []interface{}) {
 		// Handle different metric types
 		// Tangani tipe metrik yang berbeda
 		if mp, ok := metric.(MetricPair); ok {
-			// Write metric key with optional coloring
-			// Tulis key metrik dengan pewarnaan opsional
-			if f.EnableColors {
-				buf.WriteString("\033[38;5;75m") // Blue color for metric keys
-			}
-			// Direct buffer write to avoid string allocation
-			// Tulis buffer langsung untuk menghindari alokasi string
-			buf.Write(mp.Key[:mp.KeyLen])
-			if f.EnableColors {
-				buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
-			}
-			buf.WriteByte('=')
-			// Write metric value with optional coloring
-			// Tulis nilai metrik dengan pewarnaan opsional
-			if f.EnableColors {
-				buf.WriteString("\033[38;5;150m") // Green color for metric values
-			}
-			// Format float value with appropriate precision
-			// Format nilai float dengan presisi yang sesuai
-			buf.WriteString(strconv.FormatFloat(mp.Value, 'f', -1, 64))
-			if f.EnableColors {
-				buf.WriteString("\033[0m") // Reset color
-			}
+			f.formatMetricPair(buf, &mp)
 		} else {
 			// Handle non-MetricPair metrics (backward compatibility)
 			// Tangani metrik non-MetricPair (kompatibilitas mundur)
@@ -605,4 +669,55 @@ func (f *TextFormatter) formatMetrics(buf *ByteArray, metrics []interface{}) {
 	if f.EnableColors {
 		buf.WriteString("\033[0m") // Reset color
 	}
-}
\ No newline at end of file
+}
+
+// formatMetricPair writes one metric as key=value with optional coloring
+// formatMetricPair menulis satu metrik sebagai key=value dengan pewarnaan opsional
+func (f *TextFormatter) formatMetricPair(buf *ByteArray, mp *MetricPair) {
+	// Write metric key with optional coloring
+	// Tulis key metrik dengan pewarnaan opsional
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;75m") // Blue color for metric keys
+	}
+	// Direct buffer write to avoid string allocation
+	// Tulis buffer langsung untuk menghindari alokasi string
+	buf.Write(mp.Key[:mp.KeyLen])
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
+	}
+	buf.WriteByte('=')
+	// Write metric value with optional coloring
+	// Tulis nilai metrik dengan pewarnaan opsional
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;150m") // Green color for metric values
+	}
+	// Format float value with appropriate precision
+	// Format nilai float dengan presisi yang sesuai
+	var scratch [32]byte
+	buf.Write(strconv.AppendFloat(scratch[:0], mp.Value, 'f', -1, 64))
+	if f.EnableColors {
+		buf.WriteString("\033[0m") // Reset color
+	}
+}
+
+// formatMetricPairs formats metrics like formatMetrics without boxing them, for layout templates
+// formatMetricPairs memformat metrik seperti formatMetrics tanpa boxing, untuk template layout
+func (f *TextFormatter) formatMetricPairs(buf *ByteArray, metrics []MetricPair) {
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
+	}
+	buf.WriteByte('(')
+	for i := range metrics {
+		if i > 0 {
+			buf.WriteByte(' ')
+		}
+		f.formatMetricPair(buf, &metrics[i])
+	}
+	if f.EnableColors {
+		buf.WriteString("\033[38;5;243m") // Dark gray color for structural elements
+	}
+	buf.WriteByte(')')
+	if f.EnableColors {
+		buf.WriteString("\033[0m") // Reset color
+	}
+}
@@ -101,4 +101,124 @@ func TestTextFormatterWithFields(t *testing.T) {
 	if !strings.Contains(outputStr, "ip_address") || !strings.Contains(outputStr, "192.168.1.1") {
 		t.Error("Expected ip_address field in output")
 	}
-}
\ No newline at end of file
+}
+
+func TestTextFormatterStackTraceDepth(t *testing.T) {
+	formatter := &TextFormatter{EnableStackTrace: true, StackTraceDepth: 2}
+
+	entry := &LogEntry{Level: ERROR}
+	stack := "main.a\n\t/app/a.go:1\nmain.b\n\t/app/b.go:2\nmain.c\n\t/app/c.go:3\n"
+	entry.StackTraceLen = copy(entry.StackTrace[:], stack)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	outputStr := string(output)
+
+	if !strings.Contains(outputStr, "\n    main.a\n        /app/a.go:1\n    main.b\n        /app/b.go:2\n") {
+		t.Errorf("Expected indented stack block, got %q", outputStr)
+	}
+	if strings.Contains(outputStr, "main.c") {
+		t.Errorf("Expected frames beyond StackTraceDepth to be omitted, got %q", outputStr)
+	}
+}
+
+func TestTextFormatterMasksEveryValueType(t *testing.T) {
+	entry := &LogEntry{Level: INFO}
+	entry.MessageLen = copy(entry.Message[:], "charge")
+	entry.SetIntField("cvv", 123)
+	entry.SetFloat64Field("secret", 4.5)
+	entry.SetBoolField("password", true)
+	entry.SetField("api_key", []byte("k-1"))
+	entry.SetIntField("amount", 42)
+
+	formatter := &TextFormatter{MaskSensitiveData: true, MaskString: "***"}
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, want := range []string{`cvv="***"`, `secret="***"`, `password="***"`, `api_key="***"`, "amount=42"} {
+		if !strings.Contains(string(output), want) {
+			t.Errorf("Expected %s in %q", want, output)
+		}
+	}
+	if strings.Contains(string(output), "123") {
+		t.Errorf("Expected the int cvv to be masked, got %q", output)
+	}
+}
+
+func TestTextFormatterLayout(t *testing.T) {
+	formatter := &TextFormatter{}
+	if err := formatter.SetLayout("{time:15:04:05.000} {level:-5}|{pid:6} [{caller:short}] {trace_id:short} {msg} {fields} {metrics}{{x}}"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entry := newTestEntry(WARN, "disk almost full\nretrying", "mount", "/var", "free_mb", 512)
+	entry.Timestamp = testEntryTime.Add(250 * time.Millisecond)
+	entry.PID = 314
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "/home/ci/app/storage/disk.go")
+	entry.Caller.Line = 88
+	entry.TraceIDLen = copy(entry.TraceID[:], "4bf92f3577b34da6a3ce929d0e0e4736")
+	entry.SetMetric("usage", 0.97)
+
+	output, err := formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "12:00:00.250 WARN |   314 [storage/disk.go:88] 4bf92f35 disk almost full\\nretrying {mount=\"/var\" free_mb=512} (usage=0.97){x}\n"
+	if string(output) != want {
+		t.Errorf("Unexpected layout output\n got: %q\nwant: %q", output, want)
+	}
+
+	// Setting Layout directly recompiles it, and the time placeholder falls back to TimestampFormat
+	formatter.Layout = "{time} {caller} {hostname:-4}|{error}"
+	formatter.TimestampFormat = time.DateOnly
+	output, err = formatter.Format(entry)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if want := "2024-05-01 /home/ci/app/storage/disk.go:88     |\n"; string(output) != want {
+		t.Errorf("Unexpected layout output\n got: %q\nwant: %q", output, want)
+	}
+}
+
+func TestTextFormatterLayoutErrors(t *testing.T) {
+	for _, layout := range []string{
+		"{msg",
+		"msg}",
+		"{unknown}",
+		"{level:wide}",
+		"{pid:short}",
+		"{fields:10}",
+	} {
+		if err := (&TextFormatter{}).SetLayout(layout); err == nil {
+			t.Errorf("Expected an error for layout %q", layout)
+		}
+	}
+
+	formatter := &TextFormatter{Layout: "{nope}"}
+	if _, err := formatter.Format(newTestEntry(INFO, "disk almost full")); err == nil {
+		t.Error("Expected Format to report an invalid Layout")
+	}
+}
+
+func TestTextFormatterLayoutAllocations(t *testing.T) {
+	formatter := &TextFormatter{EnableColors: true}
+	if err := formatter.SetLayout("{time:15:04:05.000} {level:-5} [{caller:short}] {msg} {fields} {metrics}"); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	entry := newTestEntry(WARN, "disk almost full", "mount", "/var", "free_mb", 512)
+	entry.Caller.FileLen = copy(entry.Caller.File[:], "/home/ci/app/storage/disk.go")
+	entry.Caller.Line = 88
+	entry.SetMetric("usage", 0.97)
+
+	// Only the returned copy is allocated
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := formatter.Format(entry); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs > 1 {
+		t.Errorf("Expected at most 1 allocation per Format, got %v", allocs)
+	}
+}
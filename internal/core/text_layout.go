@@ -0,0 +1,375 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// textSegment writes one literal or placeholder of a compiled layout into the buffer
+// textSegment menulis satu literal atau placeholder dari layout yang telah dikompilasi ke dalam buffer
+type textSegment func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface)
+
+// textLayout is a layout template compiled once into segment writers
+// textLayout adalah template layout yang dikompilasi sekali menjadi penulis segmen
+type textLayout struct {
+	source   string        // Layout string the segments were compiled from
+	segments []textSegment // Segment writers in output order
+}
+
+// textLayoutString describes a placeholder that writes one string value of the entry
+// textLayoutString mendeskripsikan placeholder yang menulis satu nilai string dari entri
+type textLayoutString struct {
+	color string                                  // ANSI color used when EnableColors is set
+	value func(logEntry LogEntryInterface) string // Getter for the value
+	short bool                                    // short option truncates the value to 8 characters like the default layout
+}
+
+// textLayoutStrings maps placeholder names to the string values they write
+// textLayoutStrings memetakan nama placeholder ke nilai string yang ditulisnya
+var textLayoutStrings = map[string]textLayoutString{
+	"hostname":    {color: "\033[38;5;245m", value: LogEntryInterface.GetHostname},
+	"app":         {color: "\033[38;5;245m", value: LogEntryInterface.GetApplication},
+	"application": {color: "\033[38;5;245m", value: LogEntryInterface.GetApplication},
+	"version":     {color: "\033[38;5;245m", value: LogEntryInterface.GetVersion},
+	"env":         {color: "\033[38;5;245m", value: LogEntryInterface.GetEnvironment},
+	"environment": {color: "\033[38;5;245m", value: LogEntryInterface.GetEnvironment},
+	"gid":         {color: "\033[38;5;245m", value: LogEntryInterface.GetGoroutineID},
+	"goroutine":   {color: "\033[38;5;245m", value: LogEntryInterface.GetGoroutineID},
+	"trace_id":    {color: "\033[38;5;141m", value: LogEntryInterface.GetTraceID, short: true},
+	"span_id":     {color: "\033[38;5;141m", value: LogEntryInterface.GetSpanID, short: true},
+	"user_id":     {color: "\033[38;5;141m", value: LogEntryInterface.GetUserID},
+	"session_id":  {color: "\033[38;5;141m", value: LogEntryInterface.GetSessionID},
+	"request_id":  {color: "\033[38;5;141m", value: LogEntryInterface.GetRequestID},
+}
+
+// SetLayout compiles a layout template and makes Format use it instead of the fixed field order
+// SetLayout mengkompilasi template layout dan membuat Format menggunakannya sebagai pengganti urutan field tetap
+//
+// Placeholders are written as {name} or {name:options}; {{ and }} write literal braces. Options are separated by ':'
+// and are either a width, where a negative width pads on the right like fmt's %-5s, or a keyword:
+// Placeholder ditulis sebagai {name} atau {name:options}; {{ dan }} menulis kurung kurawal literal. Opsi dipisahkan
+// dengan ':' dan berupa lebar, di mana lebar negatif menambahkan padding di kanan seperti %-5s pada fmt, atau kata kunci:
+//
+//	{time} or {time:15:04:05.000}   timestamp, using TimestampFormat when no Go layout is given
+//	{level}                         level name
+//	{msg} or {message}              message with newlines escaped
+//	{caller:short} / {caller:full}  caller as dir/file.go:line or the full path (default)
+//	{pid}, {gid}, {hostname}, {app}, {version}, {env}
+//	{trace_id:short}, {span_id:short}, {user_id}, {session_id}, {request_id}
+//	{duration}                      duration when set
+//	{fields}, {tags}, {metrics}, {error}, {stack}
+//
+// Empty values write nothing besides their padding. A newline is added after every entry.
+// Nilai kosong tidak menulis apa pun selain padding-nya. Baris baru ditambahkan setelah setiap entri.
+func (f *TextFormatter) SetLayout(layout string) error {
+	compiled, err := compileTextLayout(layout)
+	if err != nil {
+		return err
+	}
+	f.Layout = layout
+	f.layout.Store(compiled)
+	return nil
+}
+
+// compiledLayout returns the compiled Layout, recompiling it when the field was changed directly
+// compiledLayout mengembalikan Layout yang telah dikompilasi, mengkompilasi ulang jika field diubah secara langsung
+func (f *TextFormatter) compiledLayout() (*textLayout, error) {
+	if compiled := f.layout.Load(); compiled != nil && compiled.source == f.Layout {
+		return compiled, nil
+	}
+	compiled, err := compileTextLayout(f.Layout)
+	if err != nil {
+		return nil, err
+	}
+	f.layout.Store(compiled)
+	return compiled, nil
+}
+
+// formatLayout formats a log entry by running the compiled layout segments
+// formatLayout memformat entri log dengan menjalankan segmen layout yang telah dikompilasi
+func (f *TextFormatter) formatLayout(logEntry LogEntryInterface) ([]byte, error) {
+	compiled, err := f.compiledLayout()
+	if err != nil {
+		return nil, err
+	}
+	buf := getBufferFromPool()
+	defer putBufferToPool(buf)
+	for _, segment := range compiled.segments {
+		segment(f, buf, logEntry)
+	}
+	buf.WriteByte('\n')
+	// Return copy to avoid buffer reuse issues and ensure memory safety
+	// Kembalikan salinan untuk menghindari masalah penggunaan kembali buffer dan memastikan keamanan memori
+	result := make([]byte, buf.Len())
+	copy(result, buf.Bytes())
+	return result, nil
+}
+
+// compileTextLayout splits a layout into literal and placeholder segments
+// compileTextLayout memecah layout menjadi segmen literal dan placeholder
+func compileTextLayout(layout string) (*textLayout, error) {
+	compiled := &textLayout{source: layout}
+	var literal []byte
+	flush := func() {
+		if len(literal) == 0 {
+			return
+		}
+		text := string(literal)
+		compiled.segments = append(compiled.segments, func(_ *TextFormatter, buf *ByteArray, _ LogEntryInterface) {
+			buf.WriteString(text)
+		})
+		literal = literal[:0]
+	}
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		if (c == '{' || c == '}') && i+1 < len(layout) && layout[i+1] == c {
+			literal = append(literal, c)
+			i++
+			continue
+		}
+		if c == '}' {
+			return nil, fmt.Errorf("text layout: unmatched '}' at offset %d", i)
+		}
+		if c != '{' {
+			literal = append(literal, c)
+			continue
+		}
+		end := strings.IndexByte(layout[i:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("text layout: unclosed placeholder at offset %d", i)
+		}
+		segment, err := compileTextPlaceholder(layout[i+1 : i+end])
+		if err != nil {
+			return nil, err
+		}
+		flush()
+		compiled.segments = append(compiled.segments, segment)
+		i += end
+	}
+	flush()
+	return compiled, nil
+}
+
+// compileTextPlaceholder builds the segment writer for one {name:options} placeholder
+// compileTextPlaceholder membangun penulis segmen untuk satu placeholder {name:options}
+func compileTextPlaceholder(spec string) (textSegment, error) {
+	name, options, hasOptions := strings.Cut(spec, ":")
+	// The time option is a Go time layout that may itself contain colons
+	// Opsi time adalah layout waktu Go yang dapat mengandung titik dua
+	if name == "time" || name == "timestamp" {
+		format := options
+		return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+			layout := format
+			if layout == "" {
+				layout = f.TimestampFormat
+			}
+			var scratch [64]byte
+			buf.Write(logEntry.GetTimestamp().AppendFormat(scratch[:0], layout))
+		}, nil
+	}
+
+	width, short, full := 0, false, false
+	if hasOptions {
+		for _, option := range strings.Split(options, ":") {
+			switch option {
+			case "short":
+				short = true
+			case "full":
+				full = true
+			default:
+				n, err := strconv.Atoi(option)
+				if err != nil {
+					return nil, fmt.Errorf("text layout: invalid option %q for {%s}", option, name)
+				}
+				width = n
+			}
+		}
+	}
+	str, isString := textLayoutStrings[name]
+	if (short || full) && name != "caller" && !(isString && str.short) {
+		return nil, fmt.Errorf("text layout: {%s} does not support short or full", name)
+	}
+
+	switch name {
+	case "level":
+		return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+			level := logEntry.GetLevel()
+			color := ""
+			if int(level) < len(levelColors) {
+				color = levelColors[level]
+			}
+			start := f.beginLayoutValue(buf, color)
+			buf.WriteString(level.String())
+			f.endLayoutValue(buf, color, start, width)
+		}, nil
+	case "msg", "message":
+		return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+			start := f.beginLayoutValue(buf, "\033[1m")
+			writeLayoutMessage(buf, logEntry.GetMessage())
+			f.endLayoutValue(buf, "\033[1m", start, width)
+		}, nil
+	case "caller":
+		return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+			start := f.beginLayoutValue(buf, "\033[38;5;240m")
+			if file := logEntry.GetCallerFile(); file != "" {
+				if short {
+					file = shortCallerPath(file)
+				}
+				var scratch [20]byte
+				buf.WriteString(file)
+				buf.WriteByte(':')
+				buf.Write(strconv.AppendInt(scratch[:0], int64(logEntry.GetCallerLine()), 10))
+			}
+			f.endLayoutValue(buf, "\033[38;5;240m", start, width)
+		}, nil
+	case "pid":
+		return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+			start := f.beginLayoutValue(buf, "\033[38;5;245m")
+			var scratch [20]byte
+			buf.Write(strconv.AppendInt(scratch[:0], int64(logEntry.GetPID()), 10))
+			f.endLayoutValue(buf, "\033[38;5;245m", start, width)
+		}, nil
+	case "duration":
+		return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+			start := f.beginLayoutValue(buf, "")
+			if d := logEntry.GetDuration(); d > 0 {
+				buf.WriteString(d.String())
+			}
+			f.endLayoutValue(buf, "", start, width)
+		}, nil
+	case "fields", "tags", "metrics", "error", "stack":
+		if hasOptions {
+			return nil, fmt.Errorf("text layout: {%s} takes no options", name)
+		}
+		return textBlockSegments[name], nil
+	}
+	if !isString {
+		return nil, fmt.Errorf("text layout: unknown placeholder {%s}", name)
+	}
+	return func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+		start := f.beginLayoutValue(buf, str.color)
+		value := str.value(logEntry)
+		if short && len(value) > 8 {
+			value = value[:8]
+		}
+		buf.WriteString(value)
+		f.endLayoutValue(buf, str.color, start, width)
+	}, nil
+}
+
+// textBlockSegments write the structured parts of an entry in the same form as the default layout
+// textBlockSegments menulis bagian terstruktur dari entri dalam bentuk yang sama dengan layout default
+var textBlockSegments = map[string]textSegment{
+	"fields": func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+		if fields := entryFieldPairs(logEntry); len(fields) > 0 {
+			f.formatFieldPairs(buf, fields)
+		}
+	},
+	"tags": func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+		if tags := entryTags(logEntry); len(tags) > 0 {
+			f.formatTags(buf, tags)
+		}
+	},
+	"metrics": func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+		if metrics := entryMetricPairs(logEntry); len(metrics) > 0 {
+			f.formatMetricPairs(buf, metrics)
+		}
+	},
+	"error": func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+		if err := logEntry.GetError(); err != nil {
+			f.formatError(buf, err)
+		}
+	},
+	"stack": func(f *TextFormatter, buf *ByteArray, logEntry LogEntryInterface) {
+		if stack := logEntry.GetStackTrace(); stack != "" {
+			buf.WriteByte('\n')
+			if f.EnableColors {
+				buf.WriteString("\033[38;5;240m") // Dark gray color for stack traces
+			}
+			f.formatStackTrace(buf, stack)
+			if f.EnableColors {
+				buf.WriteString("\033[0m") // Reset color
+			}
+		}
+	},
+}
+
+// beginLayoutValue writes the color of a padded value and returns where its text starts
+// beginLayoutValue menulis warna dari nilai yang diberi padding dan mengembalikan posisi awal teksnya
+func (f *TextFormatter) beginLayoutValue(buf *ByteArray, color string) int {
+	if f.EnableColors && color != "" {
+		buf.WriteString(color)
+	}
+	return buf.Len()
+}
+
+// endLayoutValue pads the text written since start to width runes and resets the color
+// endLayoutValue menambahkan padding pada teks yang ditulis sejak start hingga width rune dan mereset warna
+func (f *TextFormatter) endLayoutValue(buf *ByteArray, color string, start, width int) {
+	if width != 0 {
+		padLayoutValue(buf, start, width)
+	}
+	if f.EnableColors && color != "" {
+		buf.WriteString("\033[0m") // Reset color
+	}
+}
+
+// padLayoutValue pads buf[start:] with spaces, on the left for a positive width and on the right for a negative one
+// padLayoutValue menambahkan spasi pada buf[start:], di kiri untuk lebar positif dan di kanan untuk lebar negatif
+func padLayoutValue(buf *ByteArray, start, width int) {
+	alignRight := width > 0
+	if !alignRight {
+		width = -width
+	}
+	pad := width - utf8.RuneCount(buf.buf[start:buf.len])
+	if pad <= 0 || buf.len+pad > len(buf.buf) {
+		return
+	}
+	if alignRight {
+		copy(buf.buf[start+pad:], buf.buf[start:buf.len])
+		for i := start; i < start+pad; i++ {
+			buf.buf[i] = ' '
+		}
+	} else {
+		for i := buf.len; i < buf.len+pad; i++ {
+			buf.buf[i] = ' '
+		}
+	}
+	buf.len += pad
+	buf.data = buf.buf[:buf.len]
+}
+
+// writeLayoutMessage writes the message with newlines escaped to prevent log injection, without allocating
+// writeLayoutMessage menulis pesan dengan baris baru di-escape untuk mencegah log injection, tanpa alokasi
+func writeLayoutMessage(buf *ByteArray, message string) {
+	start := 0
+	for i := 0; i < len(message); i++ {
+		c := message[i]
+		if c != '\n' && c != '\r' {
+			continue
+		}
+		buf.WriteString(message[start:i])
+		if c == '\n' {
+			buf.WriteString("\\n")
+		} else {
+			buf.WriteString("\\r")
+		}
+		start = i + 1
+	}
+	buf.WriteString(message[start:])
+}
+
+// shortCallerPath trims a caller path to its last directory and file name
+// shortCallerPath memotong path pemanggil menjadi direktori terakhir dan nama file
+func shortCallerPath(file string) string {
+	slash := strings.LastIndexByte(file, '/')
+	if slash <= 0 {
+		return file
+	}
+	if prev := strings.LastIndexByte(file[:slash], '/'); prev >= 0 {
+		return file[prev+1:]
+	}
+	return file
+}
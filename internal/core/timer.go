@@ -0,0 +1,124 @@
+package core
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// Timer measures a single operation started with StartTimer and logs its duration when stopped
+// Timer mengukur satu operasi yang dimulai dengan StartTimer dan mencatat durasinya saat dihentikan
+type Timer struct {
+	logger  *Logger       // Logger that receives the timing entry - Logger yang menerima entri waktu
+	name    string        // Operation name used as message and metric prefix - Nama operasi yang digunakan sebagai pesan dan prefix metrik
+	fields  []interface{} // Key-value pairs added to the timing entry - Pasangan key-value yang ditambahkan ke entri waktu
+	start   time.Time     // Time the operation started - Waktu operasi dimulai
+	stopped atomic.Bool   // Ensures the duration is logged only once - Memastikan durasi hanya dicatat sekali
+	file    string        // Call site that started the timer, empty when caller info is disabled - Lokasi pemanggilan yang memulai timer, kosong jika info pemanggil dinonaktifkan
+	line    int           // Line of that call site - Baris lokasi pemanggilan tersebut
+}
+
+// StartTimer starts timing an operation; call Stop or StopWithError when it finishes
+// StartTimer mulai mengukur waktu operasi; panggil Stop atau StopWithError saat operasi selesai
+func (l *Logger) StartTimer(name string, fields ...interface{}) *Timer {
+	return l.startTimer(name, fields)
+}
+
+// startTimer creates a Timer whose entry reports the caller of StartTimer or TimeOperation, since logging happens
+// further down the stack
+// startTimer membuat Timer yang entrinya melaporkan pemanggil StartTimer atau TimeOperation, karena pencatatan terjadi
+// lebih dalam di stack
+func (l *Logger) startTimer(name string, fields []interface{}) *Timer {
+	t := &Timer{
+		logger: l,
+		name:   name,
+		fields: fields,
+		start:  time.Now(),
+	}
+	if !l.config.DisableCallerInfo {
+		// Skip startTimer and its exported caller
+		// Lewati startTimer dan pemanggil yang diekspor
+		if _, file, line, ok := runtime.Caller(2); ok {
+			t.file, t.line = file, line
+		}
+	}
+	return t
+}
+
+// Stop logs the elapsed time at INFO, escalated when SlowOperationThreshold is exceeded, and returns it
+// Stop mencatat waktu yang berlalu pada INFO, dinaikkan jika SlowOperationThreshold terlampaui, dan mengembalikannya
+func (t *Timer) Stop() time.Duration {
+	return t.StopWithError(nil)
+}
+
+// StopWithError is like Stop but logs at ERROR with err attached when err is non-nil
+// StopWithError seperti Stop tetapi mencatat pada ERROR dengan err terlampir jika err tidak nil
+//
+// Only the first call logs; later calls just return the elapsed time.
+// Hanya panggilan pertama yang mencatat; panggilan berikutnya hanya mengembalikan waktu yang berlalu.
+func (t *Timer) StopWithError(err error) time.Duration {
+	elapsed := time.Since(t.start)
+	if t.stopped.Swap(true) {
+		return elapsed
+	}
+	t.logger.logDuration(t, elapsed, err)
+	return elapsed
+}
+
+// TimeOperation runs fn and logs its duration like a Timer, recording a panic as an error before re-panicking
+// TimeOperation menjalankan fn dan mencatat durasinya seperti Timer, mencatat panic sebagai error sebelum panic diteruskan
+func (l *Logger) TimeOperation(name string, fields map[string]interface{}, fn func()) time.Duration {
+	// Sort keys so repeated operations produce identically ordered fields
+	// Urutkan key agar operasi berulang menghasilkan field dengan urutan yang sama
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	keyvals := make([]interface{}, 0, len(keys)*2)
+	for _, k := range keys {
+		keyvals = append(keyvals, k, fields[k])
+	}
+	timer := l.startTimer(name, keyvals)
+	defer func() {
+		if r := recover(); r != nil {
+			timer.StopWithError(fmt.Errorf("panic: %v", r))
+			panic(r)
+		}
+	}()
+	fn()
+	return timer.Stop()
+}
+
+// logDuration records the duration in the metrics collector and logs the timing entry at the resolved level
+// logDuration mencatat durasi ke kolektor metrik dan mencatat entri waktu pada tingkat yang ditentukan
+func (l *Logger) logDuration(t *Timer, elapsed time.Duration, err error) {
+	name := t.name
+	if l.metrics != nil {
+		l.metrics.RecordHistogram(name+"_duration", elapsed.Seconds(), map[string]string{
+			fieldKeyOperation: name,
+		})
+	}
+	level := INFO
+	if err != nil {
+		level = ERROR
+	} else if l.config.SlowOperationThreshold > 0 && elapsed > l.config.SlowOperationThreshold {
+		level = l.config.SlowOperationLevel
+	}
+	entry := getEntryFromPool()
+	entry.Level = level
+	entry.Duration = elapsed
+	entry.Error = err
+	entry.MessageLen = copy(entry.Message[:], name)
+	if t.file != "" {
+		setCaller(entry, t.file, t.line)
+	}
+	addFieldsToEntry(entry, t.fields...)
+	if l.asyncLogger != nil {
+		l.asyncLogger.logEntryFrom(l, entry, nil)
+	} else {
+		l.logEntry(entry, nil)
+	}
+}
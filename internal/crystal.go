@@ -4,9 +4,13 @@ package crystal
 
 import (
 	"crystal/internal/core"
+	"crystal/internal/encryption"
+	"crystal/internal/integrity"
 	"crystal/internal/outputs"
+	"crystal/internal/redact"
 	"crystal/internal/rotation"
 	"crystal/internal/sampling"
+	"crystal/internal/tracecontext"
 	"crystal/internal/metrics"
 	"crystal/internal/config"
 	"crystal/internal/interfaces"
@@ -19,7 +23,51 @@ type Level = core.Level
 type Formatter = core.Formatter
 type TextFormatter = core.TextFormatter
 type JSONFormatter = core.JSONFormatter
+type ECSFormatter = core.ECSFormatter
+type LogfmtFormatter = core.LogfmtFormatter
+type LogfmtPair = core.LogfmtPair
+type SyslogFormatter = core.SyslogFormatter
+type SyslogFacility = core.SyslogFacility
+type SyslogSeverity = core.SyslogSeverity
+type SyslogStandard = core.SyslogStandard
+type GELFFormatter = core.GELFFormatter
+type GELFCompression = core.GELFCompression
+type MsgpackFormatter = core.MsgpackFormatter
+type MsgpackDecoder = core.MsgpackDecoder
+type CBORFormatter = core.CBORFormatter
+type CBORDecoder = core.CBORDecoder
+type CSVFormatter = core.CSVFormatter
+type CSVHeaderWriter = core.CSVHeaderWriter
+type ConsoleFormatter = core.ConsoleFormatter
+type ConsoleColorMode = core.ConsoleColorMode
+type Redactor = redact.Redactor
+type RedactRule = redact.Rule
+type RedactAction = redact.Action
+type RedactMatchMode = redact.MatchMode
+type Pseudonymizer = redact.Pseudonymizer
+type PIIScanner = redact.Scanner
+type PIIDetector = redact.Detector
+type PIISpan = redact.Span
+type PIIFinding = redact.Finding
+type ChainWriter = integrity.Writer
+type ChainConfig = integrity.Config
+type ChainLink = integrity.Link
+type ChainCheckpoint = integrity.Checkpoint
+type ChainVerifier = integrity.Verifier
+type ChainResult = integrity.Result
+type BrokenLinkError = integrity.BrokenLinkError
+type EncryptionKey = encryption.Key
+type EncryptionKeyFunc = encryption.KeyFunc
+type DecryptReader = encryption.Reader
+type EncryptWriter = encryption.Writer
+type SpanContext = tracecontext.SpanContext
+type TraceState = tracecontext.TraceState
+type TraceStateMember = tracecontext.TraceStateMember
+type Timer = core.Timer
+type AuditEvent = core.AuditEvent
+type FieldCollisionPolicy = core.FieldCollisionPolicy
 type BufferedWriter = outputs.BufferedWriter
+type UDPOutput = outputs.UDPOutput
 type RotatingFileWriter = rotation.RotatingFileWriter
 type SamplingLogger = sampling.SamplingLogger
 type LoggerConfig = config.LoggerConfig
@@ -40,15 +88,134 @@ const (
 	PANIC  = core.PANIC
 )
 
+// Field collision policies for JSONFormatter.FlattenFields
+const (
+	FieldCollisionPrefix    = core.FieldCollisionPrefix
+	FieldCollisionOverwrite = core.FieldCollisionOverwrite
+	FieldCollisionSkip      = core.FieldCollisionSkip
+)
+
+// Syslog standards, facilities and severities for SyslogFormatter
+const (
+	SyslogRFC5424 = core.SyslogRFC5424
+	SyslogRFC3164 = core.SyslogRFC3164
+
+	FacilityKern     = core.FacilityKern
+	FacilityUser     = core.FacilityUser
+	FacilityMail     = core.FacilityMail
+	FacilityDaemon   = core.FacilityDaemon
+	FacilityAuth     = core.FacilityAuth
+	FacilitySyslog   = core.FacilitySyslog
+	FacilityLPR      = core.FacilityLPR
+	FacilityNews     = core.FacilityNews
+	FacilityUUCP     = core.FacilityUUCP
+	FacilityCron     = core.FacilityCron
+	FacilityAuthPriv = core.FacilityAuthPriv
+	FacilityFTP      = core.FacilityFTP
+	FacilityNTP      = core.FacilityNTP
+	FacilityAudit    = core.FacilityAudit
+	FacilityAlert    = core.FacilityAlert
+	FacilityClock    = core.FacilityClock
+	FacilityLocal0   = core.FacilityLocal0
+	FacilityLocal1   = core.FacilityLocal1
+	FacilityLocal2   = core.FacilityLocal2
+	FacilityLocal3   = core.FacilityLocal3
+	FacilityLocal4   = core.FacilityLocal4
+	FacilityLocal5   = core.FacilityLocal5
+	FacilityLocal6   = core.FacilityLocal6
+	FacilityLocal7   = core.FacilityLocal7
+
+	SeverityEmergency     = core.SeverityEmergency
+	SeverityAlert         = core.SeverityAlert
+	SeverityCritical      = core.SeverityCritical
+	SeverityError         = core.SeverityError
+	SeverityWarning       = core.SeverityWarning
+	SeverityNotice        = core.SeverityNotice
+	SeverityInformational = core.SeverityInformational
+	SeverityDebug         = core.SeverityDebug
+)
+
+// GELF compression modes for GELFFormatter
+const (
+	GELFCompressionNone = core.GELFCompressionNone
+	GELFCompressionGzip = core.GELFCompressionGzip
+	GELFCompressionZlib = core.GELFCompressionZlib
+)
+
+// CSVExtraFieldsColumn packs fields not named by other CSVFormatter columns as JSON
+const CSVExtraFieldsColumn = core.CSVExtraFieldsColumn
+
+// Color modes for ConsoleFormatter
+const (
+	ConsoleColorAuto   = core.ConsoleColorAuto
+	ConsoleColorAlways = core.ConsoleColorAlways
+	ConsoleColorNever  = core.ConsoleColorNever
+)
+
+// Redaction rule actions and key match modes
+const (
+	RedactMask         = redact.Mask
+	RedactDrop         = redact.Drop
+	RedactPartial      = redact.Partial
+	RedactPseudonymize = redact.Pseudonymize
+
+	RedactExact     = redact.Exact
+	RedactSubstring = redact.Substring
+	RedactGlob      = redact.Glob
+	RedactRegex     = redact.Regex
+)
+
+// W3C Trace Context header names and the sampled trace flag
+const (
+	TraceParentHeader = tracecontext.TraceParentHeader
+	TraceStateHeader  = tracecontext.TraceStateHeader
+	TraceFlagSampled  = tracecontext.FlagSampled
+)
+
 // Convenience functions
 var (
 	NewDefaultLogger      = core.NewDefaultLogger
 	NewLogger             = core.NewLogger
 	NewTextFormatter      = core.NewTextFormatter
 	NewJSONFormatter      = core.NewJSONFormatter
+	NewECSFormatter       = core.NewECSFormatter
+	NewLogfmtFormatter    = core.NewLogfmtFormatter
+	ParseLogfmt           = core.ParseLogfmt
+	NewSyslogFormatter    = core.NewSyslogFormatter
+	NewGELFFormatter      = core.NewGELFFormatter
+	NewMsgpackFormatter   = core.NewMsgpackFormatter
+	NewMsgpackDecoder     = core.NewMsgpackDecoder
+	NewCBORFormatter      = core.NewCBORFormatter
+	NewCBORDecoder        = core.NewCBORDecoder
+	NewCSVFormatter       = core.NewCSVFormatter
+	NewConsoleFormatter   = core.NewConsoleFormatter
+	NewRedactor           = redact.New
+	DefaultRedactor       = redact.Default
+	DefaultRedactRules    = redact.DefaultRules
+	NewPIIScanner         = redact.NewScanner
+	DefaultPIIDetectors   = redact.DefaultDetectors
+	NewPseudonymizer      = redact.NewPseudonymizer
+	NewChainWriter        = integrity.NewWriter
+	LastChainLink         = integrity.LastLink
+	NewEncryptWriter      = encryption.NewWriter
+	NewDecryptReader      = encryption.NewReader
+	OpenEncryptedFile     = encryption.OpenFile
+	EncryptionKeys        = encryption.Keys
+	ParseEncryptionKey    = encryption.ParseKey
 	NewBufferedWriter     = outputs.NewBufferedWriter
+	NewEncryptedFileOutput = outputs.NewEncryptedFileOutput
+	NewUDPOutput          = outputs.NewUDPOutput
+	NewGELFUDPOutput      = outputs.NewGELFUDPOutput
 	NewRotatingFileWriter = rotation.NewRotatingFileWriter
 	NewSamplingLogger     = sampling.NewSamplingLogger
+	ExtractTraceContext   = tracecontext.Extract
+	InjectTraceContext    = tracecontext.Inject
+	InjectTraceHeader     = tracecontext.InjectHeader
+	StartSpan             = tracecontext.StartSpan
+	SpanFromContext       = tracecontext.FromContext
+	ContextWithSpan       = tracecontext.ContextWithSpanContext
+	ParseTraceParent      = tracecontext.ParseTraceParent
+	ParseTraceState       = tracecontext.ParseTraceState
 	NewDefaultMetricsCollector = metrics.NewDefaultMetricsCollector
 )
 
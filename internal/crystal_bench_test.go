@@ -48,9 +48,9 @@ func BenchmarkLogger(b *testing.B) {
 	})
 	
 	b.Run("WithContext", func(b *testing.B) {
-		ctx := context.WithValue(context.Background(), "trace_id", "trace-123")
-		ctx = context.WithValue(ctx, "span_id", "span-456")
-		ctx = context.WithValue(ctx, "user_id", "user-789")
+		ctx := core.WithTraceID(context.Background(), "trace-123")
+		ctx = core.WithSpanID(ctx, "span-456")
+		ctx = core.WithUserID(ctx, "user-789")
 		
 		b.ReportAllocs()
 		b.ResetTimer()
@@ -71,6 +71,18 @@ func BenchmarkLogger(b *testing.B) {
 		}
 	})
 	
+	b.Run("JSONFormatterTypedFields", func(b *testing.B) {
+		jsonConfig := config
+		jsonConfig.Formatter = core.NewJSONFormatter()
+		jsonLogger := core.NewLogger(jsonConfig)
+		
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			jsonLogger.Info("user action", "user_id", "12345", "status", 200, "success", true, "rate", 0.95)
+		}
+	})
+	
 	b.Run("ZeroAllocationStringFields", func(b *testing.B) {
 		b.ReportAllocs()
 		b.ResetTimer()
@@ -94,4 +106,29 @@ func BenchmarkLogger(b *testing.B) {
 			logger.Info("user action", "user_id", "12345", "status", 200, "success", true, "rate", 0.95)
 		}
 	})
-}
\ No newline at end of file
+	
+	b.Run("GoroutineIDDisabled", func(b *testing.B) {
+		gidConfig := config
+		gidConfig.Formatter = &core.TextFormatter{ShowGoroutine: true}
+		gidLogger := core.NewLogger(gidConfig)
+		
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			gidLogger.Info("test message")
+		}
+	})
+	
+	b.Run("GoroutineIDEnabled", func(b *testing.B) {
+		gidConfig := config
+		gidConfig.Formatter = &core.TextFormatter{ShowGoroutine: true}
+		gidConfig.EnableGoroutineID = true
+		gidLogger := core.NewLogger(gidConfig)
+		
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			gidLogger.Info("test message")
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrNotEncrypted is returned when input does not start with a stream header.
+	ErrNotEncrypted = errors.New("encryption: not an encrypted log stream")
+
+	// ErrUnknownKey is returned by a KeyFunc that has no key for an ID.
+	ErrUnknownKey = errors.New("encryption: unknown key ID")
+
+	// ErrAuthFailed is returned for segments that do not decrypt, because the key is
+	// wrong or the data was modified.
+	ErrAuthFailed = errors.New("encryption: segment failed authentication")
+
+	// ErrCorrupt is returned for malformed headers and segment lengths.
+	ErrCorrupt = errors.New("encryption: corrupt stream")
+)
+
+// KeyFunc returns the key material for a key ID read from a stream header.
+type KeyFunc func(id string) ([]byte, error)
+
+// Keys returns a KeyFunc that looks keys up by ID, for reading files written before a key rotation.
+func Keys(keys ...Key) KeyFunc {
+	byID := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		byID[k.ID] = k.Secret
+	}
+	return func(id string) ([]byte, error) {
+		if secret, ok := byID[id]; ok {
+			return secret, nil
+		}
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, id)
+	}
+}
+
+// Reader decrypts the streams read from an underlying reader, decompressing compressed
+// streams, and returns the original log bytes. An input that ends inside a segment
+// returns io.ErrUnexpectedEOF after the complete segments before it.
+type Reader struct {
+	src  *bufio.Reader
+	keys KeyFunc
+	cur  *stream
+	out  io.Reader // cur, or a gzip reader over it
+}
+
+// NewReader reads the first stream header from r. Empty input is an empty log;
+// anything else must start with a header.
+func NewReader(r io.Reader, keys KeyFunc) (*Reader, error) {
+	dr := &Reader{src: bufio.NewReaderSize(r, 64*1024), keys: keys}
+	if _, err := dr.src.Peek(1); err == io.EOF {
+		return dr, nil
+	}
+	if err := dr.open(); err != nil {
+		return nil, err
+	}
+	return dr, nil
+}
+
+// Read reads decrypted data, moving on to the next stream when one ends.
+func (r *Reader) Read(p []byte) (int, error) {
+	for {
+		if r.out == nil {
+			return 0, io.EOF
+		}
+		n, err := r.out.Read(p)
+		if err == io.EOF && r.cur.next {
+			if oerr := r.open(); oerr != nil {
+				return n, oerr
+			}
+			if n == 0 {
+				continue
+			}
+			return n, nil
+		}
+		return n, err
+	}
+}
+
+// open starts the stream whose header is next in the input.
+func (r *Reader) open() error {
+	s, flags, err := r.readHeader()
+	if err != nil {
+		return err
+	}
+	r.cur, r.out = s, s
+	if flags&flagGzip != 0 {
+		zr, err := gzip.NewReader(s)
+		if err != nil {
+			return fmt.Errorf("%w: compressed stream: %v", ErrCorrupt, err)
+		}
+		r.out = zr
+	}
+	return nil
+}
+
+func (r *Reader) readHeader() (*stream, byte, error) {
+	fixed := make([]byte, len(magic)+3)
+	if _, err := io.ReadFull(r.src, fixed); err != nil || string(fixed[:len(magic)]) != magic {
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, 0, err
+		}
+		return nil, 0, ErrNotEncrypted
+	}
+	if fixed[len(magic)] != version {
+		return nil, 0, fmt.Errorf("%w: unsupported version %d", ErrCorrupt, fixed[len(magic)])
+	}
+	flags := fixed[len(magic)+1]
+	rest := make([]byte, int(fixed[len(magic)+2])+noncePrefixSz)
+	if _, err := io.ReadFull(r.src, rest); err != nil {
+		return nil, 0, fmt.Errorf("%w: truncated header", ErrCorrupt)
+	}
+	keyID := string(rest[:len(rest)-noncePrefixSz])
+	secret, err := r.keys(keyID)
+	if err != nil {
+		return nil, 0, err
+	}
+	aead, err := newAEAD(secret)
+	if err != nil {
+		return nil, 0, fmt.Errorf("key %q: %w", keyID, err)
+	}
+	s := &stream{src: r.src, aead: aead, header: append(fixed, rest...)}
+	copy(s.nonce[:], rest[len(rest)-noncePrefixSz:])
+	return s, flags, nil
+}
+
+// stream reads the segments of one stream, returning io.EOF at the end of the input
+// or at the next stream's header.
+type stream struct {
+	src    *bufio.Reader
+	aead   cipher.AEAD
+	header []byte
+	nonce  [12]byte
+	seq    uint64
+	buf    []byte
+	plain  []byte // Decrypted bytes not yet returned
+	done   bool
+	next   bool // Another stream's header follows
+}
+
+func (s *stream) Read(p []byte) (int, error) {
+	for len(s.plain) == 0 {
+		if s.done {
+			return 0, io.EOF
+		}
+		if err := s.readSegment(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, s.plain)
+	s.plain = s.plain[n:]
+	return n, nil
+}
+
+func (s *stream) readSegment() error {
+	prefix, err := s.src.Peek(lengthSize)
+	if len(prefix) == 0 && err == io.EOF {
+		s.done = true
+		return nil
+	}
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	// The magic read as a length is far above the segment limit, so a header is unambiguous
+	if bytes.Equal(prefix, []byte(magic[:lengthSize])) {
+		s.done, s.next = true, true
+		return nil
+	}
+	n := int(binary.BigEndian.Uint32(prefix))
+	if n < tagSize || n > MaxSegmentSize+tagSize {
+		return fmt.Errorf("%w: segment %d has length %d", ErrCorrupt, s.seq, n)
+	}
+	if s.seq > 1<<32-1 {
+		return ErrTooManySegments
+	}
+	s.src.Discard(lengthSize)
+	if cap(s.buf) < n {
+		s.buf = make([]byte, n)
+	}
+	s.buf = s.buf[:n]
+	if _, err := io.ReadFull(s.src, s.buf); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	binary.BigEndian.PutUint32(s.nonce[noncePrefixSz:], uint32(s.seq))
+	plain, err := s.aead.Open(s.buf[:0], s.nonce[:], s.buf, s.header)
+	if err != nil {
+		return fmt.Errorf("%w (segment %d)", ErrAuthFailed, s.seq)
+	}
+	s.plain = plain
+	s.seq++
+	return nil
+}
+
+// OpenFile opens an encrypted log file for reading. The name does not matter: rotated
+// .gz.enc files are compressed inside the encryption and decompressed by the Reader.
+func OpenFile(path string, keys KeyFunc) (io.ReadCloser, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewReader(f, keys)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// ParseKey parses a key written as "ID=HEX", or just HEX for a key with an empty ID.
+func ParseKey(s string) (Key, error) {
+	s = strings.TrimSpace(s)
+	var k Key
+	if i := strings.LastIndexByte(s, '='); i >= 0 {
+		k.ID, s = s[:i], s[i+1:]
+	}
+	secret, err := hex.DecodeString(s)
+	if err != nil {
+		return Key{}, fmt.Errorf("encryption: key %q is not hex: %w", k.ID, err)
+	}
+	k.Secret = secret
+	return k, k.Validate()
+}
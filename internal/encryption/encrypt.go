@@ -0,0 +1,238 @@
+// Package encryption encrypts log files at rest with AES-256-GCM.
+//
+// An encrypted file is one or more streams. Each stream starts with a header holding a
+// magic value, a format version, flags, the ID of the key it was written with and a
+// random nonce prefix chosen for that stream, followed by length-prefixed segments:
+//
+//	header:  "CRYENC" | version (1) | flags (1) | key ID length (1) | key ID | nonce prefix (8)
+//	segment: sealed length (4, big-endian) | AES-256-GCM ciphertext and tag
+//
+// Every Write becomes one segment, or several for writes over MaxSegmentSize, so a
+// rotating writer never splits a segment across files. A segment's nonce is the stream's
+// prefix followed by its 4-byte index, and the whole header is authenticated with every
+// segment, so segments cannot be modified, reordered or moved to another stream without
+// failing to decrypt. Reopening a file for append starts a new stream after the existing
+// ones; a Reader decrypts them all in order. Removing whole segments from the end of a
+// file is not detected; pair encryption with the integrity package when that matters.
+//
+// Streams flagged as compressed hold gzip data, which a Reader decompresses. Rotated
+// files are compressed before they are encrypted, since ciphertext does not compress,
+// and named .gz.enc because they are encrypted containers rather than gzip files.
+package encryption
+
+import (
+	"bufio"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+const (
+	// KeySize is the length of an AES-256 key.
+	KeySize = 32
+
+	// MaxKeyIDLength is the longest key ID that fits in a header.
+	MaxKeyIDLength = 255
+
+	// MaxSegmentSize is the most plaintext sealed in one segment; longer writes are split.
+	MaxSegmentSize = 1 << 20
+
+	// Overhead is the number of bytes a segment adds to its plaintext.
+	Overhead = lengthSize + tagSize
+)
+
+const (
+	magic         = "CRYENC"
+	version       = 1
+	flagGzip      = 1 << 0
+	noncePrefixSz = 8
+	lengthSize    = 4
+	tagSize       = 16
+)
+
+var (
+	// ErrKeySize is returned for keys that are not KeySize bytes long.
+	ErrKeySize = errors.New("encryption: key must be 32 bytes for AES-256")
+
+	// ErrInvalidKeyID is returned for key IDs longer than MaxKeyIDLength.
+	ErrInvalidKeyID = errors.New("encryption: key ID is too long")
+
+	// ErrTooManySegments is returned when a stream would reuse a nonce.
+	ErrTooManySegments = errors.New("encryption: too many segments in one stream")
+)
+
+// Key is an AES-256 key and the ID written in stream headers to find it again when reading.
+type Key struct {
+	ID     string // Identifies the key in headers, at most MaxKeyIDLength bytes
+	Secret []byte // KeySize bytes of key material
+}
+
+// Validate reports whether the key can be used to encrypt.
+func (k Key) Validate() error {
+	if len(k.Secret) != KeySize {
+		return ErrKeySize
+	}
+	if len(k.ID) > MaxKeyIDLength {
+		return ErrInvalidKeyID
+	}
+	return nil
+}
+
+// Writer encrypts everything written to it as one stream on an underlying writer.
+// The header is written together with the first segment.
+type Writer struct {
+	mu     sync.Mutex
+	out    io.Writer
+	aead   cipher.AEAD
+	header []byte
+	nonce  [12]byte
+	seq    uint64
+	buf    []byte
+	opened bool // The header has been written
+}
+
+// NewWriter starts a new stream on out, encrypted with key under a fresh random nonce prefix.
+func NewWriter(out io.Writer, key Key) (*Writer, error) {
+	return newWriter(out, key, 0)
+}
+
+// CheckAppend returns ErrNotEncrypted when the file at path holds data that does not start
+// with a stream header. A stream appended to such a file could never be read, as a Reader
+// rejects input that does not start with a header. A missing or empty file passes.
+func CheckAppend(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	head := make([]byte, len(magic))
+	n, err := io.ReadFull(f, head)
+	if n == 0 && err == io.EOF {
+		return nil
+	}
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	if string(head[:n]) != magic {
+		return fmt.Errorf("%s: %w; move it away before enabling encryption", path, ErrNotEncrypted)
+	}
+	return nil
+}
+
+func newWriter(out io.Writer, key Key, flags byte) (*Writer, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key.Secret)
+	if err != nil {
+		return nil, err
+	}
+	w := &Writer{out: out, aead: aead}
+	if _, err := rand.Read(w.nonce[:noncePrefixSz]); err != nil {
+		return nil, fmt.Errorf("encryption: reading nonce: %w", err)
+	}
+	w.header = append(w.header, magic...)
+	w.header = append(w.header, version, flags, byte(len(key.ID)))
+	w.header = append(w.header, key.ID...)
+	w.header = append(w.header, w.nonce[:noncePrefixSz]...)
+	return w, nil
+}
+
+// Write seals p and writes each segment with a single call to the underlying writer.
+// It reports len(p) on success; the bytes reaching the underlying writer include the
+// header and Overhead per segment.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > MaxSegmentSize {
+			chunk = chunk[:MaxSegmentSize]
+		}
+		if err := w.writeSegment(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// writeSegment seals one segment; the segment index only advances once the write succeeds.
+func (w *Writer) writeSegment(plain []byte) error {
+	if w.seq > 1<<32-1 {
+		return ErrTooManySegments
+	}
+	binary.BigEndian.PutUint32(w.nonce[noncePrefixSz:], uint32(w.seq))
+	w.buf = w.buf[:0]
+	if !w.opened {
+		w.buf = append(w.buf, w.header...)
+	}
+	w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(plain)+tagSize))
+	w.buf = w.aead.Seal(w.buf, w.nonce[:], plain, w.header)
+	if _, err := w.out.Write(w.buf); err != nil {
+		return err
+	}
+	w.opened = true
+	w.seq++
+	return nil
+}
+
+// Sync syncs the underlying writer when it supports it.
+func (w *Writer) Sync() error {
+	if s, ok := w.out.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// NewCompressedWriter starts a stream on out that holds gzip-compressed data: bytes
+// written to it are compressed, then encrypted. Close flushes the compressed data
+// but does not close out.
+func NewCompressedWriter(out io.Writer, key Key) (io.WriteCloser, error) {
+	w, err := newWriter(out, key, flagGzip)
+	if err != nil {
+		return nil, err
+	}
+	// Buffer the compressor's output so segments are large rather than one per flate block
+	buf := bufio.NewWriterSize(w, 64*1024)
+	return &compressedWriter{zw: gzip.NewWriter(buf), buf: buf}, nil
+}
+
+type compressedWriter struct {
+	zw  *gzip.Writer
+	buf *bufio.Writer
+}
+
+func (c *compressedWriter) Write(p []byte) (int, error) {
+	return c.zw.Write(p)
+}
+
+func (c *compressedWriter) Close() error {
+	if err := c.zw.Close(); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+func newAEAD(secret []byte) (cipher.AEAD, error) {
+	if len(secret) != KeySize {
+		return nil, ErrKeySize
+	}
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,159 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+var testKey = Key{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}
+
+func encrypt(t *testing.T, key Key, writes ...string) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewWriter(&out, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, s := range writes {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+	}
+	return out.Bytes()
+}
+
+func decrypt(data []byte, keys KeyFunc) (string, error) {
+	r, err := NewReader(bytes.NewReader(data), keys)
+	if err != nil {
+		return "", err
+	}
+	plain, err := io.ReadAll(r)
+	return string(plain), err
+}
+
+func TestRoundTrip(t *testing.T) {
+	records := []string{"INFO one\n", "INFO two\n", strings.Repeat("x", MaxSegmentSize+10) + "\n"}
+	data := encrypt(t, testKey, records...)
+	if bytes.Contains(data, []byte("INFO")) {
+		t.Error("Expected no plaintext in the output")
+	}
+	if !bytes.HasPrefix(data, []byte("CRYENC\x01\x00\x02k1")) {
+		t.Errorf("Unexpected header %q", data[:12])
+	}
+	want := len(testKey.ID) + len(magic) + 3 + noncePrefixSz + 4*Overhead + len(strings.Join(records, ""))
+	if len(data) != want {
+		t.Errorf("Expected %d bytes for four segments, got %d", want, len(data))
+	}
+
+	got, err := decrypt(data, Keys(testKey))
+	if err != nil || got != strings.Join(records, "") {
+		t.Fatalf("Unexpected round trip (%d bytes), %v", len(got), err)
+	}
+}
+
+func TestStreamsUseFreshNonces(t *testing.T) {
+	a := encrypt(t, testKey, "same record\n")
+	b := encrypt(t, testKey, "same record\n")
+	if bytes.Equal(a, b) {
+		t.Error("Expected two streams of the same data to differ")
+	}
+
+	// Appending after a restart starts another stream, possibly under a rotated key
+	rotated := Key{ID: "k2", Secret: bytes.Repeat([]byte{7}, KeySize)}
+	data := append(append(a, encrypt(t, rotated, "after restart\n")...), b...)
+	got, err := decrypt(data, Keys(testKey, rotated))
+	if err != nil || got != "same record\nafter restart\nsame record\n" {
+		t.Errorf("Unexpected concatenated streams %q, %v", got, err)
+	}
+
+	_, err = decrypt(data, Keys(testKey))
+	if !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey for the rotated key, got %v", err)
+	}
+}
+
+func TestReaderRejectsTampering(t *testing.T) {
+	data := encrypt(t, testKey, "first\n", "second\n", "third\n")
+	header := len(magic) + 3 + len(testKey.ID) + noncePrefixSz
+	seg := Overhead + len("first\n")
+
+	flipped := bytes.Clone(data)
+	flipped[header+seg+lengthSize+2] ^= 1
+
+	keyID := bytes.Clone(data)
+	keyID[len(magic)+3] = 'K'
+
+	// Swapping the first two segments, which have the same length
+	second := header + seg
+	swapped := append(bytes.Clone(data[:header]), data[second:second+Overhead+len("second\n")]...)
+	swapped = append(swapped, data[header:second]...)
+	swapped = append(swapped, data[second+Overhead+len("second\n"):]...)
+
+	tests := []struct {
+		name string
+		data []byte
+		keys KeyFunc
+		want error
+	}{
+		{"modified ciphertext", flipped, Keys(testKey), ErrAuthFailed},
+		{"reordered segments", swapped, Keys(testKey), ErrAuthFailed},
+		{"changed key ID", keyID, Keys(testKey, Key{ID: "K1", Secret: testKey.Secret}), ErrAuthFailed},
+		{"wrong key", data, Keys(Key{ID: "k1", Secret: bytes.Repeat([]byte{1}, KeySize)}), ErrAuthFailed},
+		{"truncated segment", data[:len(data)-3], Keys(testKey), io.ErrUnexpectedEOF},
+		{"plaintext", []byte("INFO plain log line\n"), Keys(testKey), ErrNotEncrypted},
+	}
+	for _, tt := range tests {
+		if _, err := decrypt(tt.data, tt.keys); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestCompressedStream(t *testing.T) {
+	var out bytes.Buffer
+	w, err := NewCompressedWriter(&out, testKey)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	text := strings.Repeat("INFO request handled status=200\n", 2000)
+	io.WriteString(w, text)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	if out.Len() >= len(text)/10 {
+		t.Errorf("Expected the stream to be compressed, got %d bytes for %d", out.Len(), len(text))
+	}
+	got, err := decrypt(out.Bytes(), Keys(testKey))
+	if err != nil || got != text {
+		t.Errorf("Unexpected decompressed stream (%d bytes), %v", len(got), err)
+	}
+}
+
+func TestEmptyInput(t *testing.T) {
+	if got, err := decrypt(nil, Keys(testKey)); err != nil || got != "" {
+		t.Errorf("Expected an empty log, got %q, %v", got, err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	if _, err := NewWriter(io.Discard, Key{Secret: []byte("short")}); !errors.Is(err, ErrKeySize) {
+		t.Errorf("Expected ErrKeySize, got %v", err)
+	}
+	if _, err := NewWriter(io.Discard, Key{ID: strings.Repeat("k", 256), Secret: testKey.Secret}); !errors.Is(err, ErrInvalidKeyID) {
+		t.Errorf("Expected ErrInvalidKeyID, got %v", err)
+	}
+
+	k, err := ParseKey("k1=3031323334353637383961626364656630313233343536373839616263646566\n")
+	if err != nil || k.ID != "k1" || !bytes.Equal(k.Secret, testKey.Secret) {
+		t.Errorf("Unexpected key %+v, %v", k, err)
+	}
+	if k, err := ParseKey("3031323334353637383961626364656630313233343536373839616263646566"); err != nil || k.ID != "" {
+		t.Errorf("Unexpected key without an ID %+v, %v", k, err)
+	}
+	if _, err := ParseKey("k1=zz"); err == nil {
+		t.Error("Expected an error for a key that is not hex")
+	}
+}
@@ -0,0 +1,205 @@
+package encryption_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"crystal/internal/encryption"
+	"crystal/internal/outputs"
+	"crystal/internal/rotation"
+)
+
+var testKey = encryption.Key{ID: "k1", Secret: []byte("0123456789abcdef0123456789abcdef")}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	r, err := encryption.OpenFile(path, encryption.Keys(testKey))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer r.Close()
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("Unexpected read error in %s: %v", path, err)
+	}
+	return string(plain)
+}
+
+func TestEncryptedFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	for _, line := range []string{"INFO first run\n", "INFO second run\n"} {
+		out, err := outputs.NewEncryptedFileOutput(path, testKey)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		io.WriteString(out, line)
+		out.Close()
+	}
+	raw, _ := os.ReadFile(path)
+	if bytes.Contains(raw, []byte("run")) {
+		t.Error("Expected no plaintext on disk")
+	}
+	if got := readFile(t, path); got != "INFO first run\nINFO second run\n" {
+		t.Errorf("Unexpected contents %q", got)
+	}
+}
+
+func TestEncryptedRotationCompressesThenEncrypts(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := rotation.NewRotatingFileWriter(path, &rotation.RotationConfig{
+		MaxSize:    400,
+		Compress:   true,
+		Encryption: &testKey,
+		OnRotate: func(w io.Writer) error {
+			_, err := io.WriteString(w, "# header\n")
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var want strings.Builder
+	for i := 0; i < 30; i++ {
+		line := fmt.Sprintf("INFO request %d handled\n", i)
+		want.WriteString(line)
+		if _, err := io.WriteString(w, line); err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+	}
+	w.Close()
+
+	// Backups are compressed in the background
+	var backups []string
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		all, _ := filepath.Glob(filepath.Join(dir, "log.*"))
+		backups = backups[:0]
+		for _, f := range all {
+			if strings.HasSuffix(f, ".gz.enc") {
+				backups = append(backups, f)
+			}
+		}
+		if len(backups) > 0 && len(backups) == len(all) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Backups were not compressed: %v", all)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	sort.Strings(backups)
+
+	var got strings.Builder
+	for _, f := range append(backups, path) {
+		raw, _ := os.ReadFile(f)
+		if bytes.Contains(raw, []byte("request")) || bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+			t.Errorf("Expected %s to be encrypted, not plain or bare gzip", f)
+		}
+		got.WriteString(strings.ReplaceAll(readFile(t, f), "# header\n", ""))
+	}
+	if got.String() != want.String() {
+		t.Errorf("Unexpected records across %d files:\n%s", len(backups)+1, got.String())
+	}
+}
+
+func TestEncryptionRefusesPlaintextFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	os.WriteFile(path, []byte("INFO written before encryption\n"), 0644)
+
+	if _, err := outputs.NewEncryptedFileOutput(path, testKey); !errors.Is(err, encryption.ErrNotEncrypted) {
+		t.Errorf("Expected the file output to refuse a plaintext file, got %v", err)
+	}
+	if _, err := rotation.NewRotatingFileWriter(path, &rotation.RotationConfig{Encryption: &testKey}); !errors.Is(err, encryption.ErrNotEncrypted) {
+		t.Errorf("Expected the rotating writer to refuse a plaintext file, got %v", err)
+	}
+	if raw, _ := os.ReadFile(path); string(raw) != "INFO written before encryption\n" {
+		t.Errorf("Expected the plaintext file to be left alone, got %q", raw)
+	}
+}
+
+func TestEncryptedRotationReadsEarlierKeys(t *testing.T) {
+	oldKey := encryption.Key{ID: "k0", Secret: []byte("fedcba9876543210fedcba9876543210")}
+	for _, keys := range []encryption.KeyFunc{nil, encryption.Keys(oldKey, testKey)} {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		out, err := outputs.NewEncryptedFileOutput(path, oldKey)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		io.WriteString(out, "INFO under the old key\n")
+		out.Close()
+
+		errs := make(chan error, 1)
+		w, err := rotation.NewRotatingFileWriter(path, &rotation.RotationConfig{
+			MaxSize:        1,
+			Compress:       true,
+			Encryption:     &testKey,
+			DecryptionKeys: keys,
+			ErrorHandler:   func(err error) { errs <- err },
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		io.WriteString(w, "INFO under the new key\n")
+		w.Close()
+
+		if keys == nil {
+			select {
+			case err := <-errs:
+				if !errors.Is(err, encryption.ErrUnknownKey) {
+					t.Errorf("Expected an unknown key error, got %v", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Expected the compression error to be reported")
+			}
+			if backups, _ := filepath.Glob(filepath.Join(dir, "log.*.log")); len(backups) != 1 {
+				t.Errorf("Expected the uncompressed backup to be kept, got %v", backups)
+			}
+			continue
+		}
+		var backups []string
+		for deadline := time.Now().Add(5 * time.Second); len(backups) == 0 && time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			backups, _ = filepath.Glob(filepath.Join(dir, "log.*.gz.enc"))
+		}
+		if len(backups) != 1 {
+			t.Fatalf("Expected one compressed backup, got %v", backups)
+		}
+		if got := readFile(t, path); got != "INFO under the new key\n" {
+			t.Errorf("Unexpected current file %q", got)
+		}
+		r, err := encryption.OpenFile(backups[0], keys)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		plain, _ := io.ReadAll(r)
+		r.Close()
+		if string(plain) != "INFO under the old key\n" {
+			t.Errorf("Unexpected backup %q", plain)
+		}
+		select {
+		case err := <-errs:
+			t.Errorf("Unexpected compression error: %v", err)
+		default:
+		}
+	}
+}
+
+func TestRotationRejectsInvalidKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	_, err := rotation.NewRotatingFileWriter(path, &rotation.RotationConfig{Encryption: &encryption.Key{Secret: []byte("short")}})
+	if err == nil {
+		t.Error("Expected an invalid key to be rejected")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected no file to be created for an invalid key")
+	}
+}
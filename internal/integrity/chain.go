@@ -0,0 +1,318 @@
+// Package integrity makes log files tamper-evident with an HMAC hash chain.
+//
+// A Writer appends a sequence number and an HMAC-SHA256 to every newline-terminated
+// record. The MAC covers the previous record's MAC, the sequence number and the record
+// bytes, so editing, deleting, inserting or reordering records breaks the chain. JSON
+// records get the values as trailing "chain_seq" and "chain_mac" members; any other
+// line gets " chain_seq=N chain_mac=HEX" appended. Periodic checkpoint records state
+// the chain head and can be signed with Ed25519, so they can be published or archived
+// and checked without the HMAC secret.
+//
+// The chain lives in the Writer, not in a file, so wrapping a rotating writer continues
+// it across rotations. A Verifier walks the resulting files, compressed or not, and
+// reports the first broken link.
+package integrity
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// MinSecretLength is the shortest HMAC secret accepted by NewWriter.
+	MinSecretLength = 16
+
+	// DefaultCheckpointEvery is the number of records between checkpoints when Config.CheckpointEvery is zero.
+	DefaultCheckpointEvery = 1000
+)
+
+var (
+	// ErrShortSecret is returned for chain secrets shorter than MinSecretLength.
+	ErrShortSecret = errors.New("integrity: chain secret is too short")
+
+	// ErrClosed is returned by writes after Close.
+	ErrClosed = errors.New("integrity: writer is closed")
+
+	// ErrOnRotate is returned by NewWriter for a rotating writer with an OnRotate hook,
+	// because what the hook writes to each new file would not be chained.
+	ErrOnRotate = errors.New("integrity: output writes OnRotate data that cannot be chained")
+)
+
+// Link identifies the head of a chain: the last sequence number written and its MAC.
+// The zero Link is the start of a new chain.
+type Link struct {
+	Seq uint64
+	MAC [sha256.Size]byte
+}
+
+// Config configures a chained Writer.
+type Config struct {
+	Secret             []byte             // HMAC-SHA256 key shared with verifiers
+	KeyID              string             // Identifies the secret and signing key in checkpoints
+	CheckpointEvery    int                // Records between checkpoints, 0 means DefaultCheckpointEvery, negative disables
+	CheckpointInterval time.Duration      // Also checkpoint on the first write after this much time, 0 disables
+	SigningKey         ed25519.PrivateKey // Signs checkpoints when set
+	Resume             Link               // Continues an existing chain, e.g. from LastLink after a restart
+}
+
+// Writer chains records written to an underlying writer. Records must be written
+// newline-terminated; a partial record is held until its newline arrives. A failed
+// write drops the rest of p without advancing the chain.
+type Writer struct {
+	mu              sync.Mutex
+	out             io.Writer
+	config          Config
+	mac             hash.Hash
+	link            Link
+	pending         []byte    // Partial record awaiting its newline
+	buf             []byte    // Scratch buffer for the chained record
+	sinceCheckpoint int       // Records written since the last checkpoint
+	lastCheckpoint  time.Time // Time of the last checkpoint
+	closed          bool
+	now             func() time.Time
+}
+
+// NewWriter wraps out so that every record written through it is chained. A rotating
+// writer's OnRotate hook writes around the chain, so such an output is refused with ErrOnRotate.
+func NewWriter(out io.Writer, config Config) (*Writer, error) {
+	if len(config.Secret) < MinSecretLength {
+		return nil, ErrShortSecret
+	}
+	if h, ok := out.(interface{ HasOnRotate() bool }); ok && h.HasOnRotate() {
+		return nil, ErrOnRotate
+	}
+	if config.CheckpointEvery == 0 {
+		config.CheckpointEvery = DefaultCheckpointEvery
+	}
+	w := &Writer{
+		out:    out,
+		config: config,
+		mac:    hmac.New(sha256.New, append([]byte(nil), config.Secret...)),
+		link:   config.Resume,
+		now:    time.Now,
+	}
+	w.lastCheckpoint = w.now()
+	return w, nil
+}
+
+// Write chains every complete record in p and writes each one with a single call to the underlying writer,
+// so a rotating writer never splits a record across files.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrClosed
+	}
+	data := p
+	if len(w.pending) > 0 {
+		w.pending = append(w.pending, p...)
+		data = w.pending
+	}
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeRecord(data[:i]); err != nil {
+			w.pending = w.pending[:0]
+			return 0, err
+		}
+		data = data[i+1:]
+		if w.checkpointDue() {
+			if err := w.writeCheckpoint(); err != nil {
+				w.pending = w.pending[:0]
+				return 0, err
+			}
+		}
+	}
+	w.pending = append(w.pending[:0], data...)
+	return len(p), nil
+}
+
+// Checkpoint writes a checkpoint record for the current chain head.
+func (w *Writer) Checkpoint() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrClosed
+	}
+	return w.writeCheckpoint()
+}
+
+// Link returns the current chain head, which can be stored to resume the chain later.
+func (w *Writer) Link() Link {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.link
+}
+
+// Sync syncs the underlying writer when it supports it.
+func (w *Writer) Sync() error {
+	if s, ok := w.out.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close chains any partial record, writes a final checkpoint and closes the underlying writer,
+// except for stdout and stderr.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	var err error
+	if len(w.pending) > 0 {
+		err = w.writeRecord(w.pending)
+		w.pending = w.pending[:0]
+	}
+	if err == nil && w.sinceCheckpoint > 0 {
+		err = w.writeCheckpoint()
+	}
+	if c, ok := w.out.(io.Closer); ok && w.out != os.Stdout && w.out != os.Stderr {
+		if cerr := c.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// writeRecord chains one record and writes it; the chain only advances once the write succeeds.
+func (w *Writer) writeRecord(record []byte) error {
+	seq := w.link.Seq + 1
+	mac := computeMAC(w.mac, &w.link.MAC, seq, record)
+	w.buf = appendChained(w.buf[:0], record, seq, &mac)
+	w.buf = append(w.buf, '\n')
+	if _, err := w.out.Write(w.buf); err != nil {
+		return err
+	}
+	w.link = Link{Seq: seq, MAC: mac}
+	w.sinceCheckpoint++
+	return nil
+}
+
+func (w *Writer) checkpointDue() bool {
+	if w.config.CheckpointEvery > 0 && w.sinceCheckpoint >= w.config.CheckpointEvery {
+		return true
+	}
+	return w.config.CheckpointInterval > 0 && w.sinceCheckpoint > 0 && w.now().Sub(w.lastCheckpoint) >= w.config.CheckpointInterval
+}
+
+// writeCheckpoint writes a chained record stating the current head, signed when a signing key is configured.
+func (w *Writer) writeCheckpoint() error {
+	now := w.now()
+	cp := Checkpoint{
+		HeadSeq: w.link.Seq,
+		HeadMAC: hex.EncodeToString(w.link.MAC[:]),
+		Time:    now.UTC().Format(time.RFC3339Nano),
+		KeyID:   w.config.KeyID,
+	}
+	if w.config.SigningKey != nil {
+		cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(w.config.SigningKey, cp.message()))
+	}
+	if err := w.writeRecord(cp.appendJSON(nil)); err != nil {
+		return err
+	}
+	w.sinceCheckpoint = 0
+	w.lastCheckpoint = now
+	return nil
+}
+
+// Checkpoint is the record a Writer writes to state the chain head at a point in time.
+type Checkpoint struct {
+	HeadSeq   uint64 `json:"head_seq"`      // Sequence number of the record before the checkpoint
+	HeadMAC   string `json:"head_mac"`      // Hex MAC of that record
+	Time      string `json:"time"`          // RFC 3339 time the checkpoint was written
+	KeyID     string `json:"key_id"`        // Config.KeyID of the writer
+	Signature string `json:"sig,omitempty"` // Base64 Ed25519 signature of the fields above
+}
+
+// checkpointPrefix starts every checkpoint record and identifies it to verifiers.
+const checkpointPrefix = `{"chain_checkpoint":true,`
+
+// appendJSON appends the checkpoint record in a fixed member order.
+func (cp *Checkpoint) appendJSON(b []byte) []byte {
+	b = append(b, checkpointPrefix...)
+	b = append(b, `"head_seq":`...)
+	b = strconv.AppendUint(b, cp.HeadSeq, 10)
+	b = append(b, `,"head_mac":`...)
+	b = strconv.AppendQuote(b, cp.HeadMAC)
+	b = append(b, `,"time":`...)
+	b = strconv.AppendQuote(b, cp.Time)
+	b = append(b, `,"key_id":`...)
+	b = strconv.AppendQuote(b, cp.KeyID)
+	if cp.Signature != "" {
+		b = append(b, `,"sig":`...)
+		b = strconv.AppendQuote(b, cp.Signature)
+	}
+	return append(b, '}')
+}
+
+// message is the byte string a checkpoint signature covers.
+func (cp *Checkpoint) message() []byte {
+	b := append([]byte("crystal-chain-checkpoint:"), strconv.FormatUint(cp.HeadSeq, 10)...)
+	b = append(b, ':')
+	b = append(b, cp.HeadMAC...)
+	b = append(b, ':')
+	b = append(b, cp.Time...)
+	b = append(b, ':')
+	return append(b, cp.KeyID...)
+}
+
+// computeMAC returns HMAC(prev || seq || record), with seq as 8 big-endian bytes.
+func computeMAC(h hash.Hash, prev *[sha256.Size]byte, seq uint64, record []byte) (mac [sha256.Size]byte) {
+	var s [8]byte
+	binary.BigEndian.PutUint64(s[:], seq)
+	h.Reset()
+	h.Write(prev[:])
+	h.Write(s[:])
+	h.Write(record)
+	h.Sum(mac[:0])
+	return mac
+}
+
+// isJSONObject reports whether a record looks like a JSON object, which gets the chain values as members.
+func isJSONObject(record []byte) bool {
+	return len(record) >= 2 && record[0] == '{' && record[len(record)-1] == '}'
+}
+
+// appendChained appends record with its chain trailer.
+func appendChained(b, record []byte, seq uint64, mac *[sha256.Size]byte) []byte {
+	if isJSONObject(record) {
+		b = append(b, record[:len(record)-1]...)
+		if len(bytes.TrimSpace(record[1:len(record)-1])) > 0 {
+			b = append(b, ',')
+		}
+		b = append(b, jsonSeqKey...)
+		b = strconv.AppendUint(b, seq, 10)
+		b = append(b, jsonMACKey...)
+		b = hex.AppendEncode(b, mac[:])
+		return append(b, `"}`...)
+	}
+	b = append(b, record...)
+	b = append(b, textSeqKey...)
+	b = strconv.AppendUint(b, seq, 10)
+	b = append(b, textMACKey...)
+	return hex.AppendEncode(b, mac[:])
+}
+
+const (
+	jsonSeqKey = `"chain_seq":`
+	jsonMACKey = `,"chain_mac":"`
+	textSeqKey = ` chain_seq=`
+	textMACKey = ` chain_mac=`
+)
@@ -0,0 +1,274 @@
+package integrity
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"crystal/internal/rotation"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func writeChain(t *testing.T, config Config, records ...string) []byte {
+	t.Helper()
+	var out bytes.Buffer
+	w, err := NewWriter(&out, config)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, record := range records {
+		if _, err := w.Write([]byte(record)); err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Unexpected close error: %v", err)
+	}
+	return out.Bytes()
+}
+
+func TestWriterChainsRecords(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	data := writeChain(t, Config{Secret: testSecret, KeyID: "k1", CheckpointEvery: 2, SigningKey: priv},
+		`{"level":"INFO","message":"a"}`+"\n",
+		"INFO b\n{}\n",
+		"partial ", "record\n",
+	)
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected 4 records and 2 checkpoints, got %d lines:\n%s", len(lines), data)
+	}
+	if !strings.HasPrefix(lines[0], `{"level":"INFO","message":"a","chain_seq":1,"chain_mac":"`) {
+		t.Errorf("Unexpected JSON record %s", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "INFO b chain_seq=2 chain_mac=") {
+		t.Errorf("Unexpected text record %s", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], `{"chain_checkpoint":true,"head_seq":2,`) || !strings.Contains(lines[2], `"chain_seq":3,`) {
+		t.Errorf("Unexpected checkpoint %s", lines[2])
+	}
+	if !strings.HasPrefix(lines[3], `{"chain_seq":4,`) {
+		t.Errorf("Unexpected empty object record %s", lines[3])
+	}
+	if !strings.HasPrefix(lines[4], "partial record chain_seq=5 ") {
+		t.Errorf("Expected the split record to be chained whole, got %s", lines[4])
+	}
+
+	result, err := (&Verifier{Secret: testSecret, PublicKey: pub}).Verify(bytes.NewReader(data), "app.log")
+	if err != nil {
+		t.Fatalf("Unexpected verify error: %v", err)
+	}
+	if result.Records != 6 || result.Checkpoints != 2 || result.First != 1 || result.Last.Seq != 6 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	other, _, _ := ed25519.GenerateKey(nil)
+	if _, err := (&Verifier{Secret: testSecret, PublicKey: other}).Verify(bytes.NewReader(data), "app.log"); err == nil {
+		t.Error("Expected a checkpoint signed with another key to fail")
+	}
+	if _, err := (&Verifier{Secret: []byte("another secret of some length")}).Verify(bytes.NewReader(data), "app.log"); err == nil {
+		t.Error("Expected verification with the wrong secret to fail")
+	}
+}
+
+func TestVerifierReportsFirstBrokenLink(t *testing.T) {
+	var records []string
+	for i := 1; i <= 5; i++ {
+		records = append(records, fmt.Sprintf(`{"n":%d}`+"\n", i))
+	}
+	data := writeChain(t, Config{Secret: testSecret, CheckpointEvery: -1}, records...)
+	lines := strings.SplitAfter(string(data), "\n")[:5]
+
+	tests := []struct {
+		name   string
+		lines  []string
+		line   int
+		reason string
+	}{
+		{"edited", []string{lines[0], strings.Replace(lines[1], `"n":2`, `"n":9`, 1), lines[2]}, 2, "MAC mismatch"},
+		{"deleted", []string{lines[0], lines[2], lines[3]}, 2, "found record 3"},
+		{"reordered", []string{lines[0], lines[2], lines[1]}, 2, "found record 3"},
+		{"inserted", []string{lines[0], "{\"n\":99}\n", lines[1]}, 2, "not chained"},
+		{"truncated", []string{lines[0], lines[1][:20]}, 2, "truncated"},
+	}
+	for _, tt := range tests {
+		_, err := (&Verifier{Secret: testSecret}).Verify(strings.NewReader(strings.Join(tt.lines, "")), "app.log")
+		var broken *BrokenLinkError
+		if !errors.As(err, &broken) || broken.Line != tt.line || !strings.Contains(broken.Reason, tt.reason) {
+			t.Errorf("%s: expected a break at line %d (%s), got %v", tt.name, tt.line, tt.reason, err)
+		}
+	}
+}
+
+func TestVerifierChecksTheEnd(t *testing.T) {
+	var records []string
+	for i := 1; i <= 4; i++ {
+		records = append(records, fmt.Sprintf(`{"n":%d}`+"\n", i))
+	}
+	data := writeChain(t, Config{Secret: testSecret, CheckpointEvery: -1}, records...)
+	full, err := (&Verifier{Secret: testSecret, RequireCheckpoint: true}).Verify(bytes.NewReader(data), "app.log")
+	if err != nil || !full.EndsWithCheckpoint || full.Last.Seq != 5 {
+		t.Fatalf("Expected the closed chain to end with a checkpoint at record 5, got %+v, %v", full, err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	cut := strings.Join(lines[:3], "")
+
+	tail, err := (&Verifier{Secret: testSecret}).Verify(strings.NewReader(cut), "app.log")
+	if err != nil || tail.EndsWithCheckpoint {
+		t.Errorf("Expected the cut chain to verify without a checkpoint at the end, got %+v, %v", tail, err)
+	}
+	tests := []struct {
+		name     string
+		verifier Verifier
+		reason   string
+	}{
+		{"end", Verifier{Secret: testSecret, End: full.Last}, "before the expected head 5"},
+		{"checkpoint", Verifier{Secret: testSecret, RequireCheckpoint: true}, "does not end with a checkpoint"},
+		{"replaced", Verifier{Secret: testSecret, End: Link{Seq: 2, MAC: full.Last.MAC}}, "does not match the expected head"},
+	}
+	for _, tt := range tests {
+		_, err := tt.verifier.Verify(strings.NewReader(cut), "app.log")
+		var broken *BrokenLinkError
+		if !errors.As(err, &broken) || !strings.Contains(broken.Reason, tt.reason) {
+			t.Errorf("%s: expected a break (%s), got %v", tt.name, tt.reason, err)
+		}
+	}
+	if _, err := (&Verifier{Secret: testSecret, End: tail.Last}).Verify(bytes.NewReader(data), "app.log"); err != nil {
+		t.Errorf("Expected a chain that continued past End to verify, got %v", err)
+	}
+}
+
+func TestChainContinuesAcrossRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	rotating, err := rotation.NewRotatingFileWriter(path, &rotation.RotationConfig{MaxSize: 300})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	w, err := NewWriter(rotating, Config{Secret: testSecret, CheckpointEvery: 4})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < 12; i++ {
+		fmt.Fprintf(w, "INFO request %d handled\n", i)
+	}
+
+	// Restart: a new writer resumes the chain from the current file
+	w.Close()
+	current, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	link, err := LastLink(current)
+	current.Close()
+	if err != nil || link.Seq == 0 {
+		t.Fatalf("Unexpected last link %+v, %v", link, err)
+	}
+	rotating, _ = rotation.NewRotatingFileWriter(path, &rotation.RotationConfig{MaxSize: 300})
+	w, _ = NewWriter(rotating, Config{Secret: testSecret, CheckpointEvery: 4, Resume: link})
+	fmt.Fprintf(w, "INFO restarted\n")
+	w.Close()
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) < 3 {
+		t.Fatalf("Expected the file to rotate, got %v", files)
+	}
+	// Compress one backup the way rotation does
+	backup := files[0]
+	if backup == path {
+		backup = files[1]
+	}
+	raw, _ := os.ReadFile(backup)
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(raw)
+	zw.Close()
+	os.WriteFile(backup+".gz", gz.Bytes(), 0644)
+	os.Remove(backup)
+
+	files, _ = filepath.Glob(filepath.Join(dir, "*"))
+	result, err := (&Verifier{Secret: testSecret}).VerifyFiles(files...)
+	if err != nil {
+		t.Fatalf("Unexpected verify error: %v", err)
+	}
+	if result.First != 1 || result.Last.Seq != result.Records || result.Files[len(result.Files)-1] != path {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	previous, err := openHead(t, result.Files[len(result.Files)-2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Losing a backup in the middle of the chain is a broken link
+	var middle string
+	for _, f := range result.Files[1 : len(result.Files)-1] {
+		middle = f
+	}
+	os.Remove(middle)
+	files, _ = filepath.Glob(filepath.Join(dir, "*"))
+	_, err = (&Verifier{Secret: testSecret}).VerifyFiles(files...)
+	var broken *BrokenLinkError
+	if !errors.As(err, &broken) || !strings.Contains(broken.Reason, "deleted") {
+		t.Errorf("Expected a missing file to break the chain, got %v", err)
+	}
+
+	// Dropping the oldest files, as MaxBackups does, is a break unless the missing head is allowed
+	_, err = (&Verifier{Secret: testSecret}).VerifyFiles(path)
+	if !errors.As(err, &broken) || broken.Seq != 1 || !strings.Contains(broken.Reason, "does not start at record 1") {
+		t.Errorf("Expected a chain without record 1 to be rejected, got %v", err)
+	}
+	tail, err := (&Verifier{Secret: testSecret, AllowMissingHead: true}).VerifyFiles(path)
+	if err != nil || !tail.Trusted || tail.First == 1 {
+		t.Errorf("Expected the newest file to verify on trust, got %+v, %v", tail, err)
+	}
+
+	// A known head before the newest file verifies it without trust
+	tail, err = (&Verifier{Secret: testSecret, Start: previous}).VerifyFiles(path)
+	if err != nil || tail.Trusted {
+		t.Errorf("Expected the newest file to verify from Start, got %+v, %v", tail, err)
+	}
+	previous.MAC[0] ^= 1
+	if _, err := (&Verifier{Secret: testSecret, Start: previous}).VerifyFiles(path); !errors.As(err, &broken) {
+		t.Errorf("Expected a wrong Start to break the chain, got %v", err)
+	}
+}
+
+// openHead returns the last link of a possibly compressed chain file.
+func openHead(t *testing.T, path string) (Link, error) {
+	t.Helper()
+	r, closeFn, err := openChainFile(path)
+	if err != nil {
+		return Link{}, err
+	}
+	defer closeFn()
+	return LastLink(r)
+}
+
+func TestNewWriterRejectsOnRotate(t *testing.T) {
+	rotating, err := rotation.NewRotatingFileWriter(filepath.Join(t.TempDir(), "app.csv"), &rotation.RotationConfig{
+		MaxSize:  300,
+		OnRotate: func(w io.Writer) error { _, err := io.WriteString(w, "time,level,message\n"); return err },
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer rotating.Close()
+	if _, err := NewWriter(rotating, Config{Secret: testSecret}); !errors.Is(err, ErrOnRotate) {
+		t.Errorf("Expected ErrOnRotate, got %v", err)
+	}
+}
+
+func TestNewWriterRejectsShortSecret(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, Config{Secret: []byte("short")}); !errors.Is(err, ErrShortSecret) {
+		t.Errorf("Expected ErrShortSecret, got %v", err)
+	}
+}
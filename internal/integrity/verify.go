@@ -0,0 +1,330 @@
+package integrity
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BrokenLinkError reports the first record at which a chain does not verify.
+type BrokenLinkError struct {
+	File   string // File or stream name
+	Line   int    // 1-based line number within File
+	Seq    uint64 // Sequence number expected at that line
+	Reason string
+}
+
+func (e *BrokenLinkError) Error() string {
+	return fmt.Sprintf("%s:%d: chain broken at record %d: %s", e.File, e.Line, e.Seq, e.Reason)
+}
+
+// Result summarizes what a Verifier checked.
+type Result struct {
+	Files              []string // Files in chain order
+	Records            uint64   // Records checked, including checkpoints
+	Checkpoints        int      // Checkpoint records among them
+	First              uint64   // Sequence number of the first record checked
+	Last               Link     // Head of the chain after the last record
+	Trusted            bool     // The first record was taken on trust because AllowMissingHead let the chain start after record 1
+	EndsWithCheckpoint bool     // The last record is a checkpoint, as after Close
+}
+
+// Verifier checks chained records. Without a Start link the chain must begin at record 1,
+// so deleting the oldest records is reported as a break. When old backups are deleted on
+// purpose, pass the head they ended at as Start, or set AllowMissingHead to take the first
+// record found on trust and check every later record against it.
+//
+// Deleting the newest records, or the whole newest file, leaves a shorter chain that still
+// verifies. To catch that, publish the head or the last checkpoint somewhere the log host
+// cannot write to, and pass it as End on the next run: the chain must then reach End and
+// match its MAC. RequireCheckpoint reports a chain that does not finish with a checkpoint,
+// which is how a chain closed by ChainWriter.Close ends.
+type Verifier struct {
+	Secret            []byte            // HMAC secret the records were written with
+	PublicKey         ed25519.PublicKey // When set, every checkpoint must carry a valid signature
+	Start             Link              // Expected head before the first record, zero when unknown
+	End               Link              // Head published earlier that the chain must reach, zero when unknown
+	AllowMissingHead  bool              // Without Start, accept a chain whose first record is above 1 and set Result.Trusted
+	RequireCheckpoint bool              // Report a chain whose last record is not a checkpoint
+}
+
+// verification is the state of one Verifier run across files.
+type verification struct {
+	v       *Verifier
+	mac     hash.Hash
+	link    Link
+	started bool
+	result  Result
+	body    []byte // Scratch buffer for the record without its trailer
+	name    string // Stream the last line was read from
+	line    int    // Number of that line within name
+}
+
+// Verify checks the chained records read from r, reporting name in errors.
+func (v *Verifier) Verify(r io.Reader, name string) (Result, error) {
+	s := v.start()
+	if err := s.verify(r, name); err != nil {
+		return s.result, err
+	}
+	return s.result, s.finish()
+}
+
+// VerifyFiles checks a set of files as one chain. Files are ordered by the sequence number
+// of their first record, so rotated backups may be given in any order; .gz files are
+// decompressed. It stops at the first broken link, returning a *BrokenLinkError, and also
+// returns one when the chain ends before Verifier.End or, with RequireCheckpoint, without
+// a checkpoint.
+func (v *Verifier) VerifyFiles(paths ...string) (Result, error) {
+	type chainFile struct {
+		path  string
+		first uint64
+	}
+	files := make([]chainFile, 0, len(paths))
+	for _, path := range paths {
+		first, err := firstSeq(path)
+		if err != nil {
+			return Result{}, err
+		}
+		files = append(files, chainFile{path, first})
+	}
+	// Files without a chained first record sort first so the break is reported
+	sort.SliceStable(files, func(i, j int) bool { return files[i].first < files[j].first })
+
+	s := v.start()
+	for _, f := range files {
+		s.result.Files = append(s.result.Files, f.path)
+		if err := s.verifyFile(f.path); err != nil {
+			return s.result, err
+		}
+	}
+	return s.result, s.finish()
+}
+
+// LastLink returns the head of the chain in r without checking MACs, for Config.Resume after a restart.
+func LastLink(r io.Reader) (Link, error) {
+	var link Link
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			if _, seq, mac, ok := splitRecord(nil, line[:len(line)-1]); ok {
+				link = Link{Seq: seq, MAC: mac}
+			}
+		}
+		if err == io.EOF {
+			return link, nil
+		}
+		if err != nil {
+			return link, err
+		}
+	}
+}
+
+func (v *Verifier) start() *verification {
+	return &verification{
+		v:       v,
+		mac:     hmac.New(sha256.New, v.Secret),
+		link:    v.Start,
+		started: v.Start != Link{},
+	}
+}
+
+func (s *verification) verifyFile(path string) error {
+	r, closeFn, err := openChainFile(path)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+	return s.verify(r, path)
+}
+
+func (s *verification) verify(r io.Reader, name string) error {
+	br := bufio.NewReader(r)
+	for lineNo := 1; ; lineNo++ {
+		line, err := br.ReadBytes('\n')
+		if len(line) == 0 && err == io.EOF {
+			return nil
+		}
+		s.name, s.line = name, lineNo
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if reason := s.check(line); reason != "" {
+			return &BrokenLinkError{File: name, Line: lineNo, Seq: s.link.Seq + 1, Reason: reason}
+		}
+	}
+}
+
+// check verifies one line and advances the chain, returning why it does not verify or "".
+func (s *verification) check(line []byte) string {
+	if line[len(line)-1] != '\n' {
+		return "truncated record"
+	}
+	body, seq, mac, ok := splitRecord(s.body[:0], line[:len(line)-1])
+	s.body = body
+	if !ok {
+		return "record is not chained"
+	}
+	if !s.started && seq != 1 {
+		if !s.v.AllowMissingHead {
+			return fmt.Sprintf("found record %d; the chain does not start at record 1", seq)
+		}
+		// The chain began in a file that is no longer present, so the first record is taken on trust
+		s.link.Seq = seq - 1
+		s.result.Trusted = true
+	} else {
+		if seq != s.link.Seq+1 {
+			return fmt.Sprintf("found record %d; records were deleted, inserted or reordered", seq)
+		}
+		if want := computeMAC(s.mac, &s.link.MAC, seq, body); !hmac.Equal(want[:], mac[:]) {
+			return "MAC mismatch; the record was modified"
+		}
+		if isCheckpoint(body) {
+			if reason := s.checkCheckpoint(body); reason != "" {
+				return reason
+			}
+		}
+	}
+	if s.v.End != (Link{}) && seq == s.v.End.Seq && mac != s.v.End.MAC {
+		return "record does not match the expected head; the newest records were replaced"
+	}
+	s.result.EndsWithCheckpoint = isCheckpoint(body)
+	if s.result.EndsWithCheckpoint {
+		s.result.Checkpoints++
+	}
+	if s.result.Records == 0 {
+		s.result.First = seq
+	}
+	s.started = true
+	s.link = Link{Seq: seq, MAC: mac}
+	s.result.Records++
+	s.result.Last = s.link
+	return ""
+}
+
+// finish checks how the chain ended once every record verified.
+func (s *verification) finish() error {
+	var reason string
+	switch {
+	case s.link.Seq < s.v.End.Seq:
+		reason = fmt.Sprintf("chain ends before the expected head %d; the newest records were deleted", s.v.End.Seq)
+	case s.v.RequireCheckpoint && !s.result.EndsWithCheckpoint:
+		reason = "chain does not end with a checkpoint; the newest records may have been deleted"
+	default:
+		return nil
+	}
+	return &BrokenLinkError{File: s.name, Line: s.line + 1, Seq: s.link.Seq + 1, Reason: reason}
+}
+
+// checkCheckpoint returns why a checkpoint record is invalid, or "" when it matches the chain head.
+func (s *verification) checkCheckpoint(body []byte) string {
+	var cp Checkpoint
+	if err := json.Unmarshal(body, &cp); err != nil {
+		return "malformed checkpoint: " + err.Error()
+	}
+	if cp.HeadSeq != s.link.Seq || cp.HeadMAC != hex.EncodeToString(s.link.MAC[:]) {
+		return fmt.Sprintf("checkpoint names head %d, chain is at %d", cp.HeadSeq, s.link.Seq)
+	}
+	if s.v.PublicKey == nil {
+		return ""
+	}
+	sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil || !ed25519.Verify(s.v.PublicKey, cp.message(), sig) {
+		return "checkpoint signature is missing or invalid"
+	}
+	return ""
+}
+
+func isCheckpoint(body []byte) bool {
+	return bytes.HasPrefix(body, []byte(checkpointPrefix))
+}
+
+// splitRecord removes the chain trailer from line, appending the original record to dst.
+func splitRecord(dst, line []byte) (body []byte, seq uint64, mac [sha256.Size]byte, ok bool) {
+	var rest []byte
+	if bytes.HasSuffix(line, []byte(`"}`)) {
+		i := bytes.LastIndex(line, []byte(jsonSeqKey))
+		if i < 1 {
+			return dst, 0, mac, false
+		}
+		rest = line[i+len(jsonSeqKey) : len(line)-2]
+		cut := i
+		if line[i-1] == ',' {
+			cut--
+		}
+		body = append(append(dst, line[:cut]...), '}')
+		seq, mac, ok = parseTrailer(rest, jsonMACKey)
+		return body, seq, mac, ok
+	}
+	i := bytes.LastIndex(line, []byte(textSeqKey))
+	if i < 0 {
+		return dst, 0, mac, false
+	}
+	seq, mac, ok = parseTrailer(line[i+len(textSeqKey):], textMACKey)
+	return append(dst, line[:i]...), seq, mac, ok
+}
+
+// parseTrailer parses "N" + sep + 64 hex digits.
+func parseTrailer(rest []byte, sep string) (seq uint64, mac [sha256.Size]byte, ok bool) {
+	i := bytes.Index(rest, []byte(sep))
+	if i <= 0 || len(rest)-i-len(sep) != 2*sha256.Size {
+		return 0, mac, false
+	}
+	seq, err := strconv.ParseUint(string(rest[:i]), 10, 64)
+	if err != nil || seq == 0 {
+		return 0, mac, false
+	}
+	if _, err := hex.Decode(mac[:], rest[i+len(sep):]); err != nil {
+		return 0, mac, false
+	}
+	return seq, mac, true
+}
+
+// firstSeq returns the sequence number of a file's first record, or 0 when it is empty or not chained.
+func firstSeq(path string) (uint64, error) {
+	r, closeFn, err := openChainFile(path)
+	if err != nil {
+		return 0, err
+	}
+	defer closeFn()
+	line, err := bufio.NewReader(r).ReadBytes('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	_, seq, _, _ := splitRecord(nil, bytes.TrimSuffix(line, []byte("\n")))
+	return seq, nil
+}
+
+// openChainFile opens a file, decompressing it when its name ends in .gz.
+func openChainFile(path string) (io.Reader, func() error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, f.Close, nil
+	}
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return zr, func() error {
+		zr.Close()
+		return f.Close()
+	}, nil
+}
@@ -17,6 +17,7 @@ type BufferedWriter struct {
 	flushInterval time.Duration // Interval between automatic flush operations
 	done          chan struct{} // Channel to signal worker shutdown
 	wg            sync.WaitGroup // WaitGroup to coordinate worker shutdown
+	stopOnce      sync.Once     // Ensures shutdown is signalled only once
 	batchTimeout  time.Duration // Maximum time to wait for a full batch
 	
 	// Statistics counters
@@ -197,15 +198,38 @@ func (bw *BufferedWriter) Flush() error {
 	return nil
 }
 
+// Sync flushes buffered data and fsyncs the underlying writer when it supports it.
+func (bw *BufferedWriter) Sync() error {
+	bw.Flush()
+	if s, ok := bw.writer.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Stop stops the worker goroutine and flushes remaining data without closing the underlying writer.
+func (bw *BufferedWriter) Stop() error {
+	bw.stopOnce.Do(func() {
+		// Signal shutdown to worker goroutine
+		// Sinyalkan shutdown ke goroutine worker
+		close(bw.done)
+		
+		// Wait for worker goroutine to complete processing
+		// Tunggu goroutine worker menyelesaikan pemrosesan
+		bw.wg.Wait()
+		
+		// Flush anything queued after the worker's final flush
+		// Flush apa pun yang diantrekan setelah flush terakhir worker
+		bw.flushBatch(bw.collectBatch())
+	})
+	return nil
+}
+
 // Close closes the writer by signaling shutdown, waiting for worker completion, flushing remaining data, and closing underlying writer.
 func (bw *BufferedWriter) Close() error {
-	// Signal shutdown to worker goroutine
-	// Sinyalkan shutdown ke goroutine worker
-	close(bw.done)
-	
-	// Wait for worker goroutine to complete processing
-	// Tunggu goroutine worker menyelesaikan pemrosesan
-	bw.wg.Wait()
+	// Stop the worker and flush remaining data
+	// Hentikan worker dan flush data yang tersisa
+	bw.Stop()
 	
 	// Close underlying writer if it implements io.Closer interface
 	// Tutup penulis yang mendasarinya jika mengimplementasikan interface io.Closer
@@ -2,12 +2,16 @@
 package outputs
 
 import (
+	"io"
 	"os"
+
+	"crystal/internal/encryption"
 )
 
 // FileOutput represents a file output destination.
 type FileOutput struct {
 	file *os.File
+	out  io.Writer // file, or an encrypting writer over it
 }
 
 // NewFileOutput creates a new file output destination.
@@ -19,12 +23,45 @@ func NewFileOutput(filename string) (*FileOutput, error) {
 	
 	return &FileOutput{
 		file: file,
+		out:  file,
 	}, nil
 }
 
+// NewEncryptedFileOutput creates a file output that encrypts everything written with key.
+// Appending to an existing file starts a new encrypted stream after its contents. An
+// existing file that is not encrypted is refused with an error wrapping
+// encryption.ErrNotEncrypted, as the result could not be decrypted.
+func NewEncryptedFileOutput(filename string, key encryption.Key) (*FileOutput, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	if err := encryption.CheckAppend(filename); err != nil {
+		return nil, err
+	}
+	fo, err := NewFileOutput(filename)
+	if err != nil {
+		return nil, err
+	}
+	w, err := encryption.NewWriter(fo.file, key)
+	if err != nil {
+		fo.file.Close()
+		return nil, err
+	}
+	fo.out = w
+	return fo, nil
+}
+
 // Write writes data to the file output.
 func (fo *FileOutput) Write(p []byte) (n int, err error) {
-	return fo.file.Write(p)
+	return fo.out.Write(p)
+}
+
+// Sync commits the file's contents to stable storage.
+func (fo *FileOutput) Sync() error {
+	if fo.file != nil {
+		return fo.file.Sync()
+	}
+	return nil
 }
 
 // Close closes the file output.
@@ -255,6 +255,17 @@ func (r *RotatingFileWriter) cleanupBackups() error {
 	return nil
 }
 
+// Sync commits the current file's contents to stable storage.
+func (r *RotatingFileWriter) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
+	if r.file != nil {
+		return r.file.Sync()
+	}
+	return nil
+}
+
 // Close closes the rotating file writer.
 func (r *RotatingFileWriter) Close() error {
 	r.mu.Lock()
@@ -0,0 +1,109 @@
+package outputs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/rand/v2"
+	"net"
+	"sync"
+)
+
+// GELF chunking constants from the GELF 1.1 specification.
+const (
+	gelfChunkMagic0    = 0x1e
+	gelfChunkMagic1    = 0x0f
+	gelfChunkHeaderLen = 12
+	gelfMaxChunks      = 128
+
+	// GELFChunkSizeWAN is the recommended datagram size when the path to Graylog crosses the internet.
+	GELFChunkSizeWAN = 1420
+	// GELFChunkSizeLAN is the recommended datagram size inside a local network.
+	GELFChunkSizeLAN = 8154
+)
+
+// ErrGELFTooManyChunks is returned when a payload would need more than 128 GELF chunks.
+var ErrGELFTooManyChunks = errors.New("gelf: message needs more than 128 chunks")
+
+// UDPOutput sends every write as one UDP datagram. Place it directly behind the
+// formatter, since buffering would merge messages into one datagram.
+type UDPOutput struct {
+	mu        sync.Mutex
+	conn      net.Conn
+	chunkSize int
+}
+
+// NewUDPOutput creates a UDP output that sends each write as a single datagram.
+func NewUDPOutput(addr string) (*UDPOutput, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &UDPOutput{conn: conn}, nil
+}
+
+// NewGELFUDPOutput creates a UDP output for GELF that splits writes larger than
+// chunkSize bytes into GELF chunks. A chunkSize of 0 uses GELFChunkSizeWAN.
+func NewGELFUDPOutput(addr string, chunkSize int) (*UDPOutput, error) {
+	if chunkSize <= 0 {
+		chunkSize = GELFChunkSizeWAN
+	}
+	if chunkSize <= gelfChunkHeaderLen {
+		return nil, errors.New("gelf: chunk size must be larger than the chunk header")
+	}
+	out, err := NewUDPOutput(addr)
+	if err != nil {
+		return nil, err
+	}
+	out.chunkSize = chunkSize
+	return out, nil
+}
+
+// Write sends p as one datagram, or as GELF chunks when it exceeds the chunk size.
+func (u *UDPOutput) Write(p []byte) (n int, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.chunkSize == 0 || len(p) <= u.chunkSize {
+		return u.conn.Write(p)
+	}
+	chunks, err := GELFChunks(p, u.chunkSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, chunk := range chunks {
+		if _, err := u.conn.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the underlying connection.
+func (u *UDPOutput) Close() error {
+	return u.conn.Close()
+}
+
+// GELFChunks splits payload into GELF chunks of at most chunkSize bytes each,
+// header included. All chunks share a random 8-byte message ID.
+func GELFChunks(payload []byte, chunkSize int) ([][]byte, error) {
+	dataSize := chunkSize - gelfChunkHeaderLen
+	if dataSize <= 0 {
+		return nil, errors.New("gelf: chunk size must be larger than the chunk header")
+	}
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, ErrGELFTooManyChunks
+	}
+	var id [8]byte
+	binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	chunks := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		data := payload[i*dataSize : min((i+1)*dataSize, len(payload))]
+		chunk := make([]byte, 0, gelfChunkHeaderLen+len(data))
+		chunk = append(chunk, gelfChunkMagic0, gelfChunkMagic1)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		chunk = append(chunk, data...)
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
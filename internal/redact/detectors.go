@@ -0,0 +1,400 @@
+package redact
+
+import (
+	"net/netip"
+	"strings"
+)
+
+const digits = "0123456789"
+
+// cut is a candidate end position and the number of significant characters before it.
+type cut struct {
+	end, n int
+}
+
+// CardDetector finds payment card numbers of 13 to 19 digits, optionally grouped by single
+// spaces or dashes, that pass the Luhn check.
+type CardDetector struct{}
+
+func (CardDetector) Name() string       { return "card" }
+func (CardDetector) Candidates() string { return digits }
+
+func (CardDetector) Find(dst []Span, s string) []Span {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) || (i > 0 && isWordByte(s[i-1])) {
+			continue
+		}
+		var num [19]byte
+		var cuts [20]cut
+		n, nc, j := 0, 0, i
+		for j < len(s) {
+			c := s[j]
+			if isDigit(c) {
+				if n == len(num) {
+					break
+				}
+				num[n] = c
+				n++
+				j++
+				continue
+			}
+			if (c == ' ' || c == '-') && j+1 < len(s) && isDigit(s[j+1]) && nc < len(cuts)-1 {
+				// A number followed by unrelated digits may still be a card up to this separator
+				cuts[nc] = cut{j, n}
+				nc++
+				j++
+				continue
+			}
+			break
+		}
+		if j == len(s) || !isWordByte(s[j]) {
+			cuts[nc] = cut{j, n}
+			nc++
+		}
+		for k := nc - 1; k >= 0; k-- {
+			if cuts[k].n >= 13 && luhnValid(num[:cuts[k].n]) {
+				dst = append(dst, Span{i, cuts[k].end})
+				break
+			}
+		}
+		i = j
+	}
+	return dst
+}
+
+// luhnValid reports whether the digits pass the Luhn checksum.
+func luhnValid(d []byte) bool {
+	sum, double := 0, false
+	for i := len(d) - 1; i >= 0; i-- {
+		v := int(d[i] - '0')
+		if double {
+			if v *= 2; v > 9 {
+				v -= 9
+			}
+		}
+		sum += v
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// EmailDetector finds email addresses whose domain ends in an alphabetic top-level domain.
+type EmailDetector struct{}
+
+func (EmailDetector) Name() string       { return "email" }
+func (EmailDetector) Candidates() string { return "@" }
+
+func (EmailDetector) Find(dst []Span, s string) []Span {
+	last := 0
+	for p := 0; p < len(s); p++ {
+		if s[p] != '@' || p < last {
+			continue
+		}
+		start := p
+		for start > last && isEmailLocalByte(s[start-1]) {
+			start--
+		}
+		for start < p && s[start] == '.' {
+			start++
+		}
+		end := p + 1
+		for end < len(s) && (isAlnum(s[end]) || s[end] == '.' || s[end] == '-') {
+			end++
+		}
+		// Trailing punctuation usually ends the sentence rather than the domain
+		for end > p+1 && (s[end-1] == '.' || s[end-1] == '-') {
+			end--
+		}
+		dot := strings.LastIndexByte(s[p+1:end], '.')
+		if start == p || dot <= 0 || !isTopLevelDomain(s[p+2+dot:end]) {
+			continue
+		}
+		dst = append(dst, Span{start, end})
+		last = end
+	}
+	return dst
+}
+
+func isTopLevelDomain(tld string) bool {
+	if len(tld) < 2 {
+		return false
+	}
+	for i := 0; i < len(tld); i++ {
+		if lower(tld[i]) < 'a' || lower(tld[i]) > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// IBANDetector finds international bank account numbers, compact or printed in groups of
+// four, that pass the ISO 13616 mod-97 check.
+type IBANDetector struct{}
+
+func (IBANDetector) Name() string       { return "iban" }
+func (IBANDetector) Candidates() string { return digits }
+
+func (IBANDetector) Find(dst []Span, s string) []Span {
+	for i := 0; i+15 <= len(s); i++ {
+		if !isUpper(s[i]) || !isUpper(s[i+1]) || !isDigit(s[i+2]) || !isDigit(s[i+3]) || (i > 0 && isWordByte(s[i-1])) {
+			continue
+		}
+		var buf [34]byte
+		var cuts [10]cut
+		n, nc, j := 0, 0, i
+		for j < len(s) && n < len(buf) {
+			c := s[j]
+			if isUpper(c) || isDigit(c) {
+				buf[n] = c
+				n++
+				j++
+				continue
+			}
+			if c == ' ' && n%4 == 0 && j+1 < len(s) && (isUpper(s[j+1]) || isDigit(s[j+1])) && nc < len(cuts)-1 {
+				cuts[nc] = cut{j, n}
+				nc++
+				j++
+				continue
+			}
+			break
+		}
+		if j == len(s) || !isWordByte(s[j]) {
+			cuts[nc] = cut{j, n}
+			nc++
+		}
+		for k := nc - 1; k >= 0; k-- {
+			if cuts[k].n >= 15 && ibanValid(buf[:cuts[k].n]) {
+				dst = append(dst, Span{i, cuts[k].end})
+				i = cuts[k].end - 1
+				break
+			}
+		}
+	}
+	return dst
+}
+
+// ibanValid moves the country code and check digits to the end and checks the remainder mod 97.
+func ibanValid(iban []byte) bool {
+	r := 0
+	for k := 0; k < len(iban); k++ {
+		c := iban[(k+4)%len(iban)]
+		if isDigit(c) {
+			r = (r*10 + int(c-'0')) % 97
+		} else {
+			r = (r*100 + int(c-'A') + 10) % 97
+		}
+	}
+	return r == 1
+}
+
+// JWTDetector finds JSON Web Tokens in compact serialization, header.payload.signature.
+type JWTDetector struct{}
+
+func (JWTDetector) Name() string       { return "jwt" }
+func (JWTDetector) Candidates() string { return "J" }
+
+func (JWTDetector) Find(dst []Span, s string) []Span {
+	for off := 0; ; {
+		idx := strings.Index(s[off:], "eyJ")
+		if idx < 0 {
+			return dst
+		}
+		start := off + idx
+		off = start + 3
+		if start > 0 && isBase64URLByte(s[start-1]) {
+			continue
+		}
+		j := skipBase64URL(s, start)
+		if j >= len(s) || s[j] != '.' || !strings.HasPrefix(s[j+1:], "eyJ") {
+			continue
+		}
+		j = skipBase64URL(s, j+1)
+		if j >= len(s) || s[j] != '.' {
+			continue
+		}
+		j = skipBase64URL(s, j+1)
+		dst = append(dst, Span{start, j})
+		off = j
+	}
+}
+
+func skipBase64URL(s string, j int) int {
+	for j < len(s) && isBase64URLByte(s[j]) {
+		j++
+	}
+	return j
+}
+
+// awsKeyPrefixes are the four-letter prefixes of AWS access key and other unique IDs.
+var awsKeyPrefixes = [...]string{"AKIA", "ASIA", "ABIA", "ACCA", "AGPA", "AIDA", "AIPA", "ANPA", "ANVA", "APKA", "AROA", "ASCA"}
+
+// AWSKeyDetector finds AWS-style access key IDs: a known prefix followed by 16 upper-case letters or digits.
+type AWSKeyDetector struct{}
+
+func (AWSKeyDetector) Name() string       { return "aws_access_key" }
+func (AWSKeyDetector) Candidates() string { return "A" }
+
+func (AWSKeyDetector) Find(dst []Span, s string) []Span {
+	for i := 0; i+20 <= len(s); i++ {
+		if s[i] != 'A' || (i > 0 && isWordByte(s[i-1])) || (i+20 < len(s) && isWordByte(s[i+20])) {
+			continue
+		}
+		known := false
+		for _, prefix := range awsKeyPrefixes {
+			if s[i:i+4] == prefix {
+				known = true
+				break
+			}
+		}
+		if !known {
+			continue
+		}
+		valid := true
+		for k := i + 4; k < i+20; k++ {
+			if !isUpper(s[k]) && !isDigit(s[k]) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			dst = append(dst, Span{i, i + 20})
+			i += 19
+		}
+	}
+	return dst
+}
+
+// BearerDetector finds the credential after the word "bearer", as in an Authorization header.
+// The credential must be at least 8 characters and mix letters with digits or symbols, or be at
+// least 16 characters, so "the bearer of bad news" is left alone but letter-only tokens are not.
+type BearerDetector struct{}
+
+func (BearerDetector) Name() string       { return "bearer" }
+func (BearerDetector) Candidates() string { return "rR" }
+
+func (BearerDetector) Find(dst []Span, s string) []Span {
+	for i := 0; i+7 < len(s); i++ {
+		if !hasFoldPrefix(s[i:], "bearer") || (i > 0 && isWordByte(s[i-1])) || s[i+6] != ' ' {
+			continue
+		}
+		start := i + 6
+		for start < len(s) && s[start] == ' ' {
+			start++
+		}
+		j := start
+		for j < len(s) && isToken68Byte(s[j]) {
+			j++
+		}
+		for j > start && s[j-1] == '.' {
+			j--
+		}
+		for j < len(s) && s[j] == '=' {
+			j++
+		}
+		letter, other := false, false
+		for k := start; k < j; k++ {
+			if isAlnum(s[k]) && !isDigit(s[k]) {
+				letter = true
+			} else {
+				other = true
+			}
+		}
+		if n := j - start; n >= 16 || (n >= 8 && letter && other) {
+			dst = append(dst, Span{start, j})
+			i = j - 1
+		}
+	}
+	return dst
+}
+
+// IPv4Detector finds dotted-decimal IPv4 addresses, ignoring longer dotted numbers such as version strings.
+type IPv4Detector struct{}
+
+func (IPv4Detector) Name() string       { return "ipv4" }
+func (IPv4Detector) Candidates() string { return digits }
+
+func (IPv4Detector) Find(dst []Span, s string) []Span {
+	for i := 0; i < len(s); i++ {
+		if !isDigit(s[i]) || (i > 0 && (isWordByte(s[i-1]) || s[i-1] == '.')) {
+			continue
+		}
+		j, ok := i, true
+		for octet := 0; octet < 4 && ok; octet++ {
+			if octet > 0 {
+				if ok = j < len(s) && s[j] == '.'; !ok {
+					break
+				}
+				j++
+			}
+			v, k := 0, j
+			for k < len(s) && isDigit(s[k]) && k-j < 3 {
+				v = v*10 + int(s[k]-'0')
+				k++
+			}
+			ok = k > j && v <= 255
+			j = k
+		}
+		if !ok || (j < len(s) && (isWordByte(s[j]) || (s[j] == '.' && j+1 < len(s) && isDigit(s[j+1])))) {
+			continue
+		}
+		dst = append(dst, Span{i, j})
+		i = j - 1
+	}
+	return dst
+}
+
+// IPv6Detector finds IPv6 addresses, including compressed and IPv4-mapped forms, validated with net/netip.
+type IPv6Detector struct{}
+
+func (IPv6Detector) Name() string       { return "ipv6" }
+func (IPv6Detector) Candidates() string { return ":" }
+
+func (IPv6Detector) Find(dst []Span, s string) []Span {
+	for i := 0; i < len(s); i++ {
+		if !isHex(s[i]) && s[i] != ':' {
+			continue
+		}
+		j, colons, hex := i, 0, false
+		for j < len(s) && (isHex(s[j]) || s[j] == ':' || s[j] == '.') {
+			if s[j] == ':' {
+				colons++
+			}
+			hex = hex || isHex(s[j])
+			j++
+		}
+		start := i
+		i = j - 1
+		if colons < 2 || !hex || (start > 0 && isWordByte(s[start-1])) || (j < len(s) && isWordByte(s[j])) {
+			continue
+		}
+		for end := j; end > start; end-- {
+			if _, err := netip.ParseAddr(s[start:end]); err == nil {
+				dst = append(dst, Span{start, end})
+				break
+			}
+			if s[end-1] != '.' && s[end-1] != ':' {
+				break
+			}
+		}
+	}
+	return dst
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+func isUpper(c byte) bool { return 'A' <= c && c <= 'Z' }
+func isAlnum(c byte) bool { return isDigit(c) || ('a' <= lower(c) && lower(c) <= 'z') }
+func isHex(c byte) bool   { return isDigit(c) || ('a' <= lower(c) && lower(c) <= 'f') }
+
+// isWordByte reports whether c continues a word, so a match next to it would be part of a larger token.
+func isWordByte(c byte) bool { return isAlnum(c) || c == '_' }
+
+func isEmailLocalByte(c byte) bool {
+	return isAlnum(c) || strings.IndexByte("._%+-", c) >= 0
+}
+
+func isBase64URLByte(c byte) bool { return isAlnum(c) || c == '-' || c == '_' }
+
+// isToken68Byte reports whether c may appear in an RFC 7235 token68 credential, apart from padding.
+func isToken68Byte(c byte) bool {
+	return isAlnum(c) || strings.IndexByte("-._~+/", c) >= 0
+}
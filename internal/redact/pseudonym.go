@@ -0,0 +1,80 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+	"sync"
+)
+
+const (
+	// MinSecretLength is the shortest secret NewPseudonymizer accepts.
+	MinSecretLength = 16
+
+	// PseudonymBytes is how many bytes of the HMAC-SHA256 are kept, written as twice as many hex digits.
+	PseudonymBytes = 8
+)
+
+var (
+	// ErrShortSecret is returned for pseudonymization secrets shorter than MinSecretLength.
+	ErrShortSecret = errors.New("redact: pseudonymization secret is too short")
+
+	// ErrInvalidKeyID is returned for key IDs containing the ':' separator.
+	ErrInvalidKeyID = errors.New("redact: key ID must not contain ':'")
+)
+
+// Pseudonymizer replaces values with a truncated HMAC-SHA256 under a secret. Equal values give
+// equal tokens across entries and services sharing the secret, so they can still be correlated,
+// but the value cannot be recovered. Tokens are prefixed with the key ID, as in "k2:3f9a1c0d5e7b2a46",
+// so a rotated secret is visible in the logs. A Pseudonymizer is safe for concurrent use.
+type Pseudonymizer struct {
+	keyID  string
+	hashes sync.Pool
+}
+
+// NewPseudonymizer creates a pseudonymizer for the secret identified by keyID; an empty keyID omits the prefix.
+func NewPseudonymizer(keyID string, secret []byte) (*Pseudonymizer, error) {
+	if len(secret) < MinSecretLength {
+		return nil, ErrShortSecret
+	}
+	if strings.IndexByte(keyID, ':') >= 0 {
+		return nil, ErrInvalidKeyID
+	}
+	key := append([]byte(nil), secret...)
+	p := &Pseudonymizer{keyID: keyID}
+	p.hashes.New = func() interface{} {
+		return hmac.New(sha256.New, key)
+	}
+	return p, nil
+}
+
+// KeyID returns the identifier written before every token.
+func (p *Pseudonymizer) KeyID() string {
+	return p.keyID
+}
+
+// Token returns the pseudonym for value.
+func (p *Pseudonymizer) Token(value string) string {
+	h := p.hashes.Get().(hash.Hash)
+	h.Reset()
+	h.Write([]byte(value))
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	p.hashes.Put(h)
+
+	b := make([]byte, 0, len(p.keyID)+1+2*PseudonymBytes)
+	if p.keyID != "" {
+		b = append(b, p.keyID...)
+		b = append(b, ':')
+	}
+	b = hex.AppendEncode(b, sum[:PseudonymBytes])
+	return string(b)
+}
+
+// Replace pseudonymizes a scanner match, so it can be used as Scanner.Replace.
+func (p *Pseudonymizer) Replace(detector, match string) string {
+	return p.Token(match)
+}
@@ -0,0 +1,68 @@
+package redact
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func TestPseudonymizerToken(t *testing.T) {
+	p, err := NewPseudonymizer("k1", testSecret)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	a, b := p.Token("jane@example.com"), p.Token("jane@example.com")
+	if a != b {
+		t.Errorf("Expected stable tokens, got %q and %q", a, b)
+	}
+	if !regexp.MustCompile(`^k1:[0-9a-f]{16}$`).MatchString(a) {
+		t.Errorf("Unexpected token format %q", a)
+	}
+	if p.Token("john@example.com") == a {
+		t.Error("Expected different values to give different tokens")
+	}
+
+	// Another process with the same secret agrees; a rotated secret does not
+	same, _ := NewPseudonymizer("k1", testSecret)
+	rotated, _ := NewPseudonymizer("k2", []byte("fedcba9876543210fedcba9876543210"))
+	if same.Token("jane@example.com") != a {
+		t.Error("Expected pseudonymizers sharing a secret to agree")
+	}
+	if got := rotated.Token("jane@example.com"); got[:3] != "k2:" || got[3:] == a[3:] {
+		t.Errorf("Unexpected token after rotation %q", got)
+	}
+
+	unprefixed, _ := NewPseudonymizer("", testSecret)
+	if got := unprefixed.Token("jane@example.com"); got != a[3:] {
+		t.Errorf("Expected the unprefixed token %q, got %q", a[3:], got)
+	}
+}
+
+func TestPseudonymizerErrors(t *testing.T) {
+	if _, err := NewPseudonymizer("k1", []byte("short")); !errors.Is(err, ErrShortSecret) {
+		t.Errorf("Expected ErrShortSecret, got %v", err)
+	}
+	if _, err := NewPseudonymizer("k:1", testSecret); !errors.Is(err, ErrInvalidKeyID) {
+		t.Errorf("Expected ErrInvalidKeyID, got %v", err)
+	}
+	if _, err := New(Rule{Pattern: "email", Action: Pseudonymize}); !errors.Is(err, ErrInvalidRule) {
+		t.Errorf("Expected ErrInvalidRule without a pseudonymizer, got %v", err)
+	}
+}
+
+func TestPseudonymizeRule(t *testing.T) {
+	p, _ := NewPseudonymizer("k1", testSecret)
+	r := MustNew(Rule{Pattern: "*email*", Match: Glob, Action: Pseudonymize, Pseudonymizer: p})
+	got, keep := r.Redact("user_email", "jane@example.com")
+	if !keep || got != p.Token("jane@example.com") {
+		t.Errorf("Redact() = %q, %v; want the pseudonym", got, keep)
+	}
+
+	sc := NewScanner(EmailDetector{})
+	sc.Replace = p.Replace
+	if got, _ := sc.Scan("from jane@example.com"); got != "from "+p.Token("jane@example.com") {
+		t.Errorf("Unexpected scanner replacement %q", got)
+	}
+}
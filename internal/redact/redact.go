@@ -0,0 +1,302 @@
+// Package redact implements the key-based redaction rules the Crystal logger applies to
+// entry fields before any hook or formatter sees them.
+//
+// A Redactor holds an ordered list of rules. Each rule matches field keys exactly, by
+// substring, by glob or by regular expression, always ignoring ASCII case, and decides
+// whether a matching value is masked, dropped, partially revealed or pseudonymized. The
+// first matching rule wins, so specific rules should come before broad ones.
+//
+// A Scanner complements the key rules by finding sensitive values, such as card numbers
+// or email addresses, inside messages and other free text.
+package redact
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Action is what a rule does to the value of a matching key.
+type Action uint8
+
+const (
+	// Mask replaces the value with the rule's mask string.
+	Mask Action = iota
+
+	// Drop removes the field from the entry.
+	Drop
+
+	// Partial masks all but the last Rule.Reveal characters of the value.
+	Partial
+
+	// Pseudonymize replaces the value with a stable keyed hash from Rule.Pseudonymizer.
+	Pseudonymize
+)
+
+// MatchMode selects how Rule.Pattern is compared with a key.
+type MatchMode uint8
+
+const (
+	// Exact matches keys equal to the pattern.
+	Exact MatchMode = iota
+
+	// Substring matches keys containing the pattern.
+	Substring
+
+	// Glob matches keys against a pattern where * matches any run of characters and ? matches one.
+	Glob
+
+	// Regex matches keys against a regular expression.
+	Regex
+)
+
+const (
+	// DefaultMask is the replacement used by Mask rules that do not set their own.
+	DefaultMask = "***"
+
+	// DefaultReveal is the number of trailing characters Partial rules keep when Reveal is zero.
+	DefaultReveal = 4
+)
+
+// ErrInvalidRule is returned by New for rules with an unknown mode or action, an empty pattern
+// or a Pseudonymize action without a Pseudonymizer.
+var ErrInvalidRule = errors.New("redact: invalid rule")
+
+// SensitiveKeys is the explicit list of keys the default redactor masks wherever they appear.
+var SensitiveKeys = []string{
+	"password", "passwd", "pwd", "passphrase",
+	"secret", "client_secret",
+	"token", "access_token", "refresh_token", "id_token",
+	"api_key", "apikey", "private_key", "privatekey", "access_key", "accesskey",
+	"authorization", "auth", "cookie", "set-cookie",
+	"credit_card", "card_number", "cvv", "ssn",
+}
+
+// Rule matches field keys and says what to do with their values.
+type Rule struct {
+	Pattern string    // Key, fragment, glob or regular expression depending on Match
+	Match   MatchMode // How Pattern is compared with keys
+	Action  Action    // What happens to a matching value
+	Mask    string    // Replacement for Mask and the fill for Partial, empty means DefaultMask and '*'
+	Reveal  int       // Trailing characters left visible by Partial, 0 means DefaultReveal
+
+	Pseudonymizer *Pseudonymizer // Token source for Pseudonymize, required by that action
+}
+
+// Redactor applies an ordered list of rules; it is immutable and safe for concurrent use.
+type Redactor struct {
+	rules []compiledRule
+}
+
+// compiledRule is a validated rule with its regular expression compiled.
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// New validates and compiles the rules, keeping their order.
+func New(rules ...Rule) (*Redactor, error) {
+	r := &Redactor{rules: make([]compiledRule, 0, len(rules))}
+	for i, rule := range rules {
+		if rule.Pattern == "" || rule.Match > Regex || rule.Action > Pseudonymize || rule.Reveal < 0 ||
+			(rule.Action == Pseudonymize && rule.Pseudonymizer == nil) {
+			return nil, fmt.Errorf("%w: rule %d", ErrInvalidRule, i)
+		}
+		c := compiledRule{Rule: rule}
+		if rule.Match == Regex {
+			re, err := regexp.Compile("(?i)" + rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("%w: rule %d: %v", ErrInvalidRule, i, err)
+			}
+			c.re = re
+		}
+		r.rules = append(r.rules, c)
+	}
+	return r, nil
+}
+
+// MustNew is like New but panics on an invalid rule; it is meant for package-level variables.
+func MustNew(rules ...Rule) *Redactor {
+	r, err := New(rules...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// DefaultRules masks SensitiveKeys exactly plus keys that contain password, secret or token
+// or end in _key, -key or .key; unlike a bare "key" fragment this leaves monkey and keyboard alone.
+func DefaultRules() []Rule {
+	rules := make([]Rule, 0, len(SensitiveKeys)+6)
+	for _, key := range SensitiveKeys {
+		rules = append(rules, Rule{Pattern: key, Match: Exact})
+	}
+	return append(rules,
+		Rule{Pattern: "password", Match: Substring},
+		Rule{Pattern: "secret", Match: Substring},
+		Rule{Pattern: "token", Match: Substring},
+		Rule{Pattern: "*_key", Match: Glob},
+		Rule{Pattern: "*-key", Match: Glob},
+		Rule{Pattern: "*.key", Match: Glob},
+	)
+}
+
+var defaultRedactor = MustNew(DefaultRules()...)
+
+// Default returns the shared redactor built from DefaultRules.
+func Default() *Redactor {
+	return defaultRedactor
+}
+
+// Rules returns a copy of the redactor's rules, so a caller can extend them and build a new redactor.
+func (r *Redactor) Rules() []Rule {
+	rules := make([]Rule, len(r.rules))
+	for i := range r.rules {
+		rules[i] = r.rules[i].Rule
+	}
+	return rules
+}
+
+// Match returns the first rule matching key; it does not allocate for non-regex rules.
+func (r *Redactor) Match(key string) (Rule, bool) {
+	if r == nil {
+		return Rule{}, false
+	}
+	for i := range r.rules {
+		if r.rules[i].matches(key) {
+			return r.rules[i].Rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Redact applies the first rule matching key to value. It returns the value to log and
+// false when the field should be dropped; values of unmatched keys are returned unchanged.
+func (r *Redactor) Redact(key, value string) (string, bool) {
+	rule, ok := r.Match(key)
+	if !ok {
+		return value, true
+	}
+	return rule.Apply(value)
+}
+
+// NeedsValue reports whether Apply uses the original value, so callers only render values that matter.
+func (rule Rule) NeedsValue() bool {
+	return rule.Action == Partial || rule.Action == Pseudonymize
+}
+
+// Apply returns the redacted form of value and false when the rule drops it.
+func (rule Rule) Apply(value string) (string, bool) {
+	switch rule.Action {
+	case Drop:
+		return "", false
+	case Partial:
+		reveal := rule.Reveal
+		if reveal == 0 {
+			reveal = DefaultReveal
+		}
+		fill := '*'
+		if rule.Mask != "" {
+			fill, _ = utf8.DecodeRuneInString(rule.Mask)
+		}
+		return RevealLast(value, reveal, fill), true
+	case Pseudonymize:
+		return rule.Pseudonymizer.Token(value), true
+	default:
+		if rule.Mask != "" {
+			return rule.Mask, true
+		}
+		return DefaultMask, true
+	}
+}
+
+// RevealLast replaces every character of value but the last n with fill. Values of n
+// characters or fewer are masked completely, since revealing them would reveal everything.
+func RevealLast(value string, n int, fill rune) string {
+	count := utf8.RuneCountInString(value)
+	hidden := count - n
+	if hidden <= 0 {
+		hidden, n = count, 0
+	}
+	b := make([]byte, 0, hidden*utf8.RuneLen(fill)+len(value))
+	for i := 0; i < hidden; i++ {
+		b = utf8.AppendRune(b, fill)
+	}
+	if n > 0 {
+		tail := len(value)
+		for i := 0; i < n; i++ {
+			_, size := utf8.DecodeLastRuneInString(value[:tail])
+			tail -= size
+		}
+		b = append(b, value[tail:]...)
+	}
+	return string(b)
+}
+
+// matches reports whether key satisfies the rule, ignoring ASCII case.
+func (c *compiledRule) matches(key string) bool {
+	switch c.Match {
+	case Exact:
+		return len(key) == len(c.Pattern) && hasFoldPrefix(key, c.Pattern)
+	case Substring:
+		for i := 0; i+len(c.Pattern) <= len(key); i++ {
+			if hasFoldPrefix(key[i:], c.Pattern) {
+				return true
+			}
+		}
+		return false
+	case Glob:
+		return matchGlob(c.Pattern, key)
+	default:
+		return c.re.MatchString(key)
+	}
+}
+
+// hasFoldPrefix reports whether s starts with prefix, ignoring ASCII case.
+func hasFoldPrefix(s, prefix string) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i := 0; i < len(prefix); i++ {
+		if lower(s[i]) != lower(prefix[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchGlob matches s against a pattern of literals, * and ?, ignoring ASCII case.
+// It backtracks only to the most recent star, so it runs in linear time for typical keys.
+func matchGlob(pattern, s string) bool {
+	p, i := 0, 0
+	star, mark := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || lower(pattern[p]) == lower(s[i])):
+			p++
+			i++
+		case star >= 0:
+			p = star + 1
+			mark++
+			i = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// lower folds an ASCII upper-case letter to lower case.
+func lower(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
@@ -0,0 +1,118 @@
+package redact
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDefaultRules(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"password", true},
+		{"DB_Password", true},
+		{"auth_token", true},
+		{"client_secret", true},
+		{"encryption_key", true},
+		{"x-api-key", true},
+		{"apiKey", true},
+		{"Authorization", true},
+		{"monkey", false},
+		{"keyboard", false},
+		{"key_count", false},
+		{"username", false},
+	}
+	for _, tt := range tests {
+		if _, got := Default().Match(tt.key); got != tt.want {
+			t.Errorf("Default().Match(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestMatchModes(t *testing.T) {
+	r, err := New(
+		Rule{Pattern: "card", Match: Exact, Action: Partial},
+		Rule{Pattern: "internal", Match: Substring, Action: Drop},
+		Rule{Pattern: "user.*.email", Match: Glob, Mask: "[email]"},
+		Rule{Pattern: `^ssn_\d+$`, Match: Regex},
+	)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tests := []struct {
+		key, value string
+		want       string
+		keep       bool
+	}{
+		{"CARD", "4111111111111111", "************1111", true},
+		{"card_type", "visa", "visa", true},
+		{"is_internal_note", "x", "", false},
+		{"user.primary.email", "a@b.c", "[email]", true},
+		{"user.email", "a@b.c", "a@b.c", true},
+		{"SSN_2", "123-45-6789", DefaultMask, true},
+		{"ssn_x", "123-45-6789", "123-45-6789", true},
+	}
+	for _, tt := range tests {
+		got, keep := r.Redact(tt.key, tt.value)
+		if got != tt.want || keep != tt.keep {
+			t.Errorf("Redact(%q, %q) = %q, %v; want %q, %v", tt.key, tt.value, got, keep, tt.want, tt.keep)
+		}
+	}
+}
+
+func TestFirstRuleWins(t *testing.T) {
+	r := MustNew(
+		Rule{Pattern: "session_token", Match: Exact, Action: Partial, Reveal: 2},
+		Rule{Pattern: "token", Match: Substring, Action: Drop},
+	)
+	if got, keep := r.Redact("session_token", "abcdef"); got != "****ef" || !keep {
+		t.Errorf("Expected the exact rule to win, got %q, %v", got, keep)
+	}
+	if _, keep := r.Redact("refresh_token", "abcdef"); keep {
+		t.Error("Expected the substring rule to drop refresh_token")
+	}
+}
+
+func TestRevealLast(t *testing.T) {
+	tests := []struct {
+		value string
+		n     int
+		fill  rune
+		want  string
+	}{
+		{"4111111111111111", 4, '*', "************1111"},
+		{"1234", 4, '*', "****"},
+		{"", 4, '*', ""},
+		{"héllo wörld", 3, '•', "••••••••rld"},
+	}
+	for _, tt := range tests {
+		if got := RevealLast(tt.value, tt.n, tt.fill); got != tt.want {
+			t.Errorf("RevealLast(%q, %d) = %q, want %q", tt.value, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestNewRejectsInvalidRules(t *testing.T) {
+	for _, rule := range []Rule{
+		{},
+		{Pattern: "x", Match: Regex + 1},
+		{Pattern: "x", Action: Partial + 1},
+		{Pattern: "x", Reveal: -1},
+		{Pattern: "(", Match: Regex},
+	} {
+		if _, err := New(rule); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("New(%+v) error = %v, want ErrInvalidRule", rule, err)
+		}
+	}
+}
+
+func TestMatchDoesNotAllocate(t *testing.T) {
+	allocs := testing.AllocsPerRun(100, func() {
+		Default().Match("request_path")
+		Default().Match("X-Api-Key")
+	})
+	if allocs != 0 {
+		t.Errorf("Expected Match not to allocate, got %v allocations", allocs)
+	}
+}
@@ -0,0 +1,146 @@
+package redact
+
+import (
+	"sort"
+	"strings"
+)
+
+// Span is the byte range [Start, End) of a sensitive value inside a string.
+type Span struct {
+	Start, End int
+}
+
+// Detector finds sensitive values inside free text such as messages and string field values.
+type Detector interface {
+	// Name labels what the detector finds; the default replacement is [REDACTED:name].
+	Name() string
+
+	// Candidates lists bytes of which every match contains at least one. The scanner skips
+	// the detector when none of them appears in a value; an empty string means always run.
+	Candidates() string
+
+	// Find appends the spans of sensitive values in s to dst in order and returns it.
+	Find(dst []Span, s string) []Span
+}
+
+// Finding is a span reported by a named detector.
+type Finding struct {
+	Span
+	Detector string
+}
+
+// Scanner runs a set of detectors over values and masks what they find. It is safe for
+// concurrent use once built; set Replace before sharing it.
+type Scanner struct {
+	// Replace returns the text written in place of a match; nil writes [REDACTED:detector].
+	Replace func(detector, match string) string
+
+	detectors []Detector
+	sets      []byteSet // Candidate bytes per detector
+	any       byteSet   // Union of all candidate bytes, checked before any detector runs
+}
+
+// byteSet is a 256-bit set of byte values.
+type byteSet [4]uint64
+
+func (b *byteSet) add(c byte) {
+	b[c>>6] |= 1 << (c & 63)
+}
+
+func (b *byteSet) intersects(o *byteSet) bool {
+	return b[0]&o[0]|b[1]&o[1]|b[2]&o[2]|b[3]&o[3] != 0
+}
+
+// NewScanner builds a scanner from the detectors, or from DefaultDetectors when none are given.
+func NewScanner(detectors ...Detector) *Scanner {
+	if len(detectors) == 0 {
+		detectors = DefaultDetectors()
+	}
+	sc := &Scanner{detectors: detectors, sets: make([]byteSet, len(detectors))}
+	for i, d := range detectors {
+		candidates := d.Candidates()
+		if candidates == "" {
+			sc.sets[i] = byteSet{^uint64(0), ^uint64(0), ^uint64(0), ^uint64(0)}
+		}
+		for j := 0; j < len(candidates); j++ {
+			sc.sets[i].add(candidates[j])
+		}
+		for k := range sc.any {
+			sc.any[k] |= sc.sets[i][k]
+		}
+	}
+	return sc
+}
+
+// DefaultDetectors returns the built-in detectors for card numbers, email addresses, IBANs,
+// JWTs, AWS access key IDs, bearer tokens and IPv4 and IPv6 addresses.
+func DefaultDetectors() []Detector {
+	return []Detector{
+		CardDetector{}, EmailDetector{}, IBANDetector{}, JWTDetector{},
+		AWSKeyDetector{}, BearerDetector{}, IPv4Detector{}, IPv6Detector{},
+	}
+}
+
+// Find returns the non-overlapping findings in s ordered by position. Values containing
+// none of the detectors' candidate bytes are rejected after one pass without allocating.
+func (sc *Scanner) Find(s string) []Finding {
+	var seen byteSet
+	for i := 0; i < len(s); i++ {
+		seen.add(s[i])
+	}
+	if !sc.any.intersects(&seen) {
+		return nil
+	}
+	var found []Finding
+	var spans []Span
+	for i, d := range sc.detectors {
+		if !sc.sets[i].intersects(&seen) {
+			continue
+		}
+		spans = d.Find(spans[:0], s)
+		for _, span := range spans {
+			found = append(found, Finding{Span: span, Detector: d.Name()})
+		}
+	}
+	if len(found) < 2 {
+		return found
+	}
+	// Keep the earliest and then longest match where detectors overlap, e.g. an IPv4 tail inside an IPv6 address
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Start != found[j].Start {
+			return found[i].Start < found[j].Start
+		}
+		return found[i].End > found[j].End
+	})
+	kept := found[:1]
+	for _, f := range found[1:] {
+		if f.Start >= kept[len(kept)-1].End {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// Scan returns s with every finding replaced and whether anything was found.
+func (sc *Scanner) Scan(s string) (string, bool) {
+	found := sc.Find(s)
+	if len(found) == 0 {
+		return s, false
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	last := 0
+	for _, f := range found {
+		b.WriteString(s[last:f.Start])
+		if sc.Replace != nil {
+			b.WriteString(sc.Replace(f.Detector, s[f.Start:f.End]))
+		} else {
+			b.WriteString("[REDACTED:")
+			b.WriteString(f.Detector)
+			b.WriteByte(']')
+		}
+		last = f.End
+	}
+	b.WriteString(s[last:])
+	return b.String(), true
+}
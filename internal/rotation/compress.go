@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+
+	"crystal/internal/encryption"
 )
 
 // compressFile compresses a file using gzip
@@ -34,13 +36,26 @@ func (r *RotatingFileWriter) compressFile(srcFilename, dstFilename string) error
 		}
 	}()
 
-	gzWriter := gzip.NewWriter(dstFile)
+	var src io.Reader = srcFile
+	var gzWriter io.WriteCloser
+	if r.key != nil {
+		// Compress, then encrypt: ciphertext does not compress, so the backup is
+		// decrypted and written again as a compressed encrypted stream
+		if src, err = encryption.NewReader(srcFile, r.keys); err != nil {
+			return err
+		}
+		if gzWriter, err = encryption.NewCompressedWriter(dstFile, *r.key); err != nil {
+			return err
+		}
+	} else {
+		gzWriter = gzip.NewWriter(dstFile)
+	}
 	defer gzWriter.Close()
 
 	buf := r.bufferPool.Get().([]byte)
 	defer r.bufferPool.Put(buf)
 
-	_, err = io.CopyBuffer(gzWriter, srcFile, buf)
+	_, err = io.CopyBuffer(gzWriter, src, buf)
 	if err != nil {
 		return err
 	}
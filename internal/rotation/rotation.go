@@ -2,11 +2,14 @@ package rotation
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 	"time"
+
+	"crystal/internal/encryption"
 )
 
 // RotationConfig holds configuration for log rotation
@@ -18,6 +21,24 @@ type RotationConfig struct {
 	Compress        bool
 	RotationTime    time.Duration
 	FilenamePattern string
+	// OnRotate is called with the new file after each rotation, before the
+	// pending write, for example to write a CSV header. Bytes it writes count
+	// towards MaxSize. It must not call Write on the RotatingFileWriter, and
+	// its bytes bypass writers wrapping this one, so a chained
+	// integrity.Writer refuses a RotatingFileWriter with OnRotate set.
+	OnRotate func(w io.Writer) error
+	// Encryption, when set, encrypts every file with AES-256-GCM. Rotated
+	// files are compressed before they are encrypted and named .gz.enc, as
+	// they are not gzip files. MaxSize counts the encrypted bytes on disk.
+	// An existing file that is not encrypted is refused.
+	Encryption *encryption.Key
+	// DecryptionKeys finds the keys of streams in a rotated file when it is
+	// compressed, so a file appended to after a key change can still be
+	// read. It defaults to Encryption alone.
+	DecryptionKeys encryption.KeyFunc
+	// ErrorHandler, when set, receives errors from compressing rotated files
+	// in the background. The uncompressed file is kept when compression fails.
+	ErrorHandler func(error)
 }
 
 // RotatingFileWriter provides log rotation with compression
@@ -31,13 +52,21 @@ type RotatingFileWriter struct {
 	rotationTime   time.Duration
 	currentSize    int64
 	file           *os.File
+	out            io.Writer // file, or an encrypting writer over it
 	mu             sync.Mutex
 	lastRotation   time.Time
 	pattern        string
 	compressedExt  string
 	bufferPool     sync.Pool
+	onRotate       func(w io.Writer) error
+	key            *encryption.Key
+	keys           encryption.KeyFunc
+	errorHandler   func(error)
 }
 
+// encryptedCompressedExt names compressed backups when encryption is on, as they are not gzip files.
+const encryptedCompressedExt = ".gz.enc"
+
 // NewRotatingFileWriter creates a new rotating file writer
 func NewRotatingFileWriter(filename string, config *RotationConfig) (*RotatingFileWriter, error) {
 	r := &RotatingFileWriter{
@@ -46,9 +75,26 @@ func NewRotatingFileWriter(filename string, config *RotationConfig) (*RotatingFi
 		compressedExt: ".gz",
 		bufferPool: sync.Pool{
 			New: func() interface{} {
-				return make([]byte, 0, 64*1024)
+				return make([]byte, 64*1024)
 			},
 		},
+		onRotate:      config.OnRotate,
+		key:           config.Encryption,
+		keys:          config.DecryptionKeys,
+		errorHandler:  config.ErrorHandler,
+	}
+
+	if r.key != nil {
+		if err := r.key.Validate(); err != nil {
+			return nil, err
+		}
+		if err := encryption.CheckAppend(filename); err != nil {
+			return nil, err
+		}
+		if r.keys == nil {
+			r.keys = encryption.Keys(*r.key)
+		}
+		r.compressedExt = encryptedCompressedExt
 	}
 
 	if config.MaxSize > 0 {
@@ -71,10 +117,11 @@ func NewRotatingFileWriter(filename string, config *RotationConfig) (*RotatingFi
 		r.rotationTime = config.RotationTime
 	}
 
+	// Only the file name is a time layout; digits in the directory must not be formatted
 	if config.FilenamePattern != "" {
-		r.pattern = filepath.Join(filepath.Dir(filename), config.FilenamePattern)
+		r.pattern = config.FilenamePattern
 	} else {
-		r.pattern = filepath.Join(filepath.Dir(filename), "log.2006-01-02T15-04-05.000.log")
+		r.pattern = "log.2006-01-02T15-04-05.000.log"
 	}
 
 	info, err := os.Stat(filename)
@@ -82,20 +129,27 @@ func NewRotatingFileWriter(filename string, config *RotationConfig) (*RotatingFi
 		if !os.IsNotExist(err) {
 			return nil, err
 		}
-		r.file, err = os.Create(filename)
+		file, err := os.Create(filename)
 		if err != nil {
 			return nil, err
 		}
+		if err := r.setFile(file); err != nil {
+			return nil, err
+		}
 		r.lastRotation = time.Now()
 		return r, nil
 	}
 
-	r.file, err = os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
+	// An encrypted file reopened for append gets a new stream after the existing ones
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, err
 	}
-
 	r.currentSize = info.Size()
+	if err := r.setFile(file); err != nil {
+		return nil, err
+	}
+
 	r.lastRotation = time.Now()
 	return r, nil
 }
@@ -111,13 +165,7 @@ func (r *RotatingFileWriter) Write(p []byte) (n int, err error) {
 		}
 	}
 
-	n, err = r.file.Write(p)
-	if err != nil {
-		return n, err
-	}
-
-	r.currentSize += int64(n)
-	return n, nil
+	return r.out.Write(p)
 }
 
 // needsRotation determines if rotation is needed
@@ -139,23 +187,19 @@ func (r *RotatingFileWriter) needsRotation() bool {
 	return false
 }
 
-// rotate performs the actual file rotation
+// rotate performs the actual file rotation. The caller must hold r.mu.
 func (r *RotatingFileWriter) rotate() error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	
 	if r.file != nil {
 		if err := r.file.Close(); err != nil {
 			return err
 		}
 	}
 
-	var newFilename string
-	if r.localTime {
-		newFilename = time.Now().Format(r.pattern)
-	} else {
-		newFilename = time.Now().UTC().Format(r.pattern)
+	now := time.Now()
+	if !r.localTime {
+		now = now.UTC()
 	}
+	newFilename := filepath.Join(filepath.Dir(r.filename), now.Format(r.pattern))
 
 	// Check if target file already exists
 	if _, err := os.Stat(newFilename); err == nil {
@@ -169,21 +213,29 @@ func (r *RotatingFileWriter) rotate() error {
 		return err
 	}
 
-	var err error
-	r.file, err = os.Create(r.filename)
+	file, err := os.Create(r.filename)
 	if err != nil {
 		return err
 	}
-
 	r.currentSize = 0
+	if err := r.setFile(file); err != nil {
+		return err
+	}
+
 	r.lastRotation = time.Now()
 
+	if r.onRotate != nil {
+		if err := r.onRotate(rotatedFile{r}); err != nil {
+			return err
+		}
+	}
+
 	if r.compress {
 		go func() {
 			compressedName := newFilename + r.compressedExt
-			if err := r.compressFile(newFilename, compressedName); err != nil {
-				// Log error but don't block rotation
-				return
+			if err := r.compressFile(newFilename, compressedName); err != nil && r.errorHandler != nil {
+				// Rotation does not wait for compression, so the error is reported instead
+				r.errorHandler(fmt.Errorf("rotation: compressing %s: %w", newFilename, err))
 			}
 		}()
 	}
@@ -195,6 +247,66 @@ func (r *RotatingFileWriter) rotate() error {
 	return nil
 }
 
+// setFile makes file the current file, starting a new encrypted stream on it when encryption is enabled.
+func (r *RotatingFileWriter) setFile(file *os.File) error {
+	r.file, r.out = file, sizedFile{r}
+	if r.key == nil {
+		return nil
+	}
+	w, err := encryption.NewWriter(sizedFile{r}, *r.key)
+	if err != nil {
+		file.Close()
+		return err
+	}
+	r.out = w
+	return nil
+}
+
+// sizedFile writes to the current file and counts the bytes that reach it, so that
+// MaxSize and Size agree with the file on disk whether or not it is encrypted.
+type sizedFile struct {
+	r *RotatingFileWriter
+}
+
+func (w sizedFile) Write(p []byte) (int, error) {
+	n, err := w.r.file.Write(p)
+	w.r.currentSize += int64(n)
+	return n, err
+}
+
+// rotatedFile writes to the new file from OnRotate while r.mu is held.
+type rotatedFile struct {
+	r *RotatingFileWriter
+}
+
+func (w rotatedFile) Write(p []byte) (int, error) {
+	return w.r.out.Write(p)
+}
+
+// Size returns the number of bytes in the current file.
+func (r *RotatingFileWriter) Size() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.currentSize
+}
+
+// HasOnRotate reports whether OnRotate is set. Wrappers that must see every byte
+// written to the files, such as a chained integrity.Writer, use it to refuse the writer.
+func (r *RotatingFileWriter) HasOnRotate() bool {
+	return r.onRotate != nil
+}
+
+// Sync commits the current file's contents to stable storage
+func (r *RotatingFileWriter) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil {
+		return r.file.Sync()
+	}
+	return nil
+}
+
 // Close closes the rotating file writer
 func (r *RotatingFileWriter) Close() error {
 	r.mu.Lock()
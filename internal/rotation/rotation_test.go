@@ -0,0 +1,181 @@
+package rotation
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeLines writes n numbered lines, failing instead of hanging if a write deadlocks.
+func writeLines(t *testing.T, w io.Writer, n int) string {
+	t.Helper()
+	var want strings.Builder
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < n; i++ {
+			line := fmt.Sprintf("INFO request %02d handled\n", i)
+			want.WriteString(line)
+			if _, err := io.WriteString(w, line); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Unexpected write error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Write did not return; rotation deadlocked")
+	}
+	return want.String()
+}
+
+// waitForBackups waits until every backup in dir has the given suffix and returns them sorted.
+func waitForBackups(t *testing.T, dir, suffix string) []string {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		all, _ := filepath.Glob(filepath.Join(dir, "log.*"))
+		done := len(all) > 0
+		for _, f := range all {
+			done = done && strings.HasSuffix(f, suffix)
+		}
+		if done {
+			sort.Strings(all)
+			return all
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected backups ending in %q, got %v", suffix, all)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func readAll(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestRotatesWhenMaxSizeReached(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFileWriter(path, &RotationConfig{MaxSize: 100})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := writeLines(t, w, 12)
+	w.Close()
+
+	backups := waitForBackups(t, dir, ".log")
+	if len(backups) < 2 {
+		t.Fatalf("Expected several rotations, got %v", backups)
+	}
+	var got strings.Builder
+	for _, f := range append(backups, path) {
+		content := readAll(t, f)
+		if len(content) > 100+len("INFO request 00 handled\n") {
+			t.Errorf("%s holds %d bytes, more than MaxSize allows", f, len(content))
+		}
+		got.WriteString(content)
+	}
+	if got.String() != want {
+		t.Errorf("Expected every line once in order, got:\n%s", got.String())
+	}
+}
+
+func TestFilenamePatternKeepsDirectoryDigits(t *testing.T) {
+	// "01" and "2006" are time layout elements and must not be formatted in the directory
+	dir := filepath.Join(t.TempDir(), "run-01-2006")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFileWriter(path, &RotationConfig{MaxSize: 50, FilenamePattern: "log.2006-01-02.150405.000.log"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	writeLines(t, w, 4)
+	w.Close()
+
+	backups := waitForBackups(t, dir, ".log")
+	year := time.Now().UTC().Format("2006")
+	for _, f := range backups {
+		if filepath.Dir(f) != dir || !strings.HasPrefix(filepath.Base(f), "log."+year+"-") {
+			t.Errorf("Unexpected backup %s", f)
+		}
+	}
+}
+
+func TestCompressesRotatedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFileWriter(path, &RotationConfig{MaxSize: 100, Compress: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := writeLines(t, w, 12)
+	w.Close()
+
+	var got strings.Builder
+	for _, f := range append(waitForBackups(t, dir, ".gz"), path) {
+		got.WriteString(readAll(t, f))
+	}
+	if got.String() != want {
+		t.Errorf("Expected the compressed backups to hold every line, got:\n%s", got.String())
+	}
+}
+
+func TestOnRotateWritesHeader(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	w, err := NewRotatingFileWriter(path, &RotationConfig{
+		MaxSize: 100,
+		OnRotate: func(w io.Writer) error {
+			_, err := io.WriteString(w, "# header\n")
+			return err
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	writeLines(t, w, 12)
+	if size := int64(len(readAll(t, path))); w.Size() != size {
+		t.Errorf("Expected header bytes to count towards the size, got %d for %d bytes", w.Size(), size)
+	}
+	w.Close()
+
+	backups := waitForBackups(t, dir, ".log")
+	for i, f := range append(backups, path) {
+		hasHeader := strings.HasPrefix(readAll(t, f), "# header\nINFO request")
+		// The first file was created before any rotation
+		if hasHeader != (i > 0) {
+			t.Errorf("%s: header written = %v", f, hasHeader)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package tracecontext implements W3C Trace Context propagation for the Crystal logger.
+//
+// It parses and validates traceparent and tracestate headers, stores the result in a
+// context.Context where the logger picks up trace and span IDs, creates child spans and
+// injects headers into outbound requests without depending on a tracing SDK.
+package tracecontext
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"crystal/internal/core"
+)
+
+const (
+	// TraceParentHeader is the W3C header carrying version, trace ID, parent span ID and flags.
+	TraceParentHeader = "traceparent"
+
+	// TraceStateHeader is the W3C header carrying vendor-specific trace state.
+	TraceStateHeader = "tracestate"
+
+	// FlagSampled marks a trace as sampled by the caller.
+	FlagSampled byte = 0x01
+
+	// traceParentLen is the exact length of a version 00 traceparent value.
+	traceParentLen = 55
+
+	// maxVersion is the forbidden version value.
+	maxVersion = 0xff
+)
+
+var (
+	// ErrInvalidTraceParent is returned when a traceparent header is malformed.
+	ErrInvalidTraceParent = errors.New("tracecontext: invalid traceparent")
+
+	// ErrInvalidTraceState is returned when a tracestate header is malformed.
+	ErrInvalidTraceState = errors.New("tracecontext: invalid tracestate")
+)
+
+// spanContextKey is the context key under which the current SpanContext is stored.
+type spanContextKey struct{}
+
+// SpanContext identifies a span within a trace as described by a traceparent header.
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+	State   TraceState
+}
+
+// IsValid reports whether both the trace ID and span ID are non-zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// IsSampled reports whether the sampled flag is set.
+func (sc SpanContext) IsSampled() bool {
+	return sc.Flags&FlagSampled != 0
+}
+
+// TraceIDHex returns the trace ID as 32 lowercase hex characters.
+func (sc SpanContext) TraceIDHex() string {
+	return hex.EncodeToString(sc.TraceID[:])
+}
+
+// SpanIDHex returns the span ID as 16 lowercase hex characters.
+func (sc SpanContext) SpanIDHex() string {
+	return hex.EncodeToString(sc.SpanID[:])
+}
+
+// TraceParent formats the span context as a version 00 traceparent value.
+func (sc SpanContext) TraceParent() string {
+	var buf [traceParentLen]byte
+	buf[0], buf[1], buf[2] = '0', '0', '-'
+	hex.Encode(buf[3:35], sc.TraceID[:])
+	buf[35] = '-'
+	hex.Encode(buf[36:52], sc.SpanID[:])
+	buf[52] = '-'
+	hex.Encode(buf[53:55], []byte{sc.Flags})
+	return string(buf[:])
+}
+
+// ParseTraceParent parses and validates a traceparent header value.
+//
+// Versions above 00 are accepted as long as the version 00 prefix is well formed,
+// as required for forward compatibility.
+func ParseTraceParent(value string) (SpanContext, error) {
+	var sc SpanContext
+	if len(value) < traceParentLen {
+		return sc, ErrInvalidTraceParent
+	}
+	if value[2] != '-' || value[35] != '-' || value[52] != '-' {
+		return sc, ErrInvalidTraceParent
+	}
+	var version [1]byte
+	if !decodeLowerHex(version[:], value[0:2]) || version[0] == maxVersion {
+		return sc, ErrInvalidTraceParent
+	}
+	// Version 00 has a fixed length; future versions may append fields after a dash
+	if version[0] == 0 && len(value) != traceParentLen {
+		return sc, ErrInvalidTraceParent
+	}
+	if len(value) > traceParentLen && value[traceParentLen] != '-' {
+		return sc, ErrInvalidTraceParent
+	}
+	if !decodeLowerHex(sc.TraceID[:], value[3:35]) || !decodeLowerHex(sc.SpanID[:], value[36:52]) {
+		return sc, ErrInvalidTraceParent
+	}
+	var flags [1]byte
+	if !decodeLowerHex(flags[:], value[53:55]) {
+		return sc, ErrInvalidTraceParent
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, ErrInvalidTraceParent
+	}
+	return sc, nil
+}
+
+// decodeLowerHex decodes src into dst, rejecting uppercase digits as the specification requires.
+func decodeLowerHex(dst []byte, src string) bool {
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	_, err := hex.Decode(dst, []byte(src))
+	return err == nil
+}
+
+// Extract parses the traceparent and tracestate headers and returns a context carrying the span context.
+//
+// An invalid traceparent leaves ctx unchanged and returns ErrInvalidTraceParent. An invalid
+// tracestate is discarded while the traceparent is still honored.
+func Extract(ctx context.Context, header http.Header) (context.Context, error) {
+	sc, err := ParseTraceParent(header.Get(TraceParentHeader))
+	if err != nil {
+		return ctx, err
+	}
+	if values := header.Values(TraceStateHeader); len(values) > 0 {
+		if state, err := ParseTraceState(joinHeaderValues(values)); err == nil {
+			sc.State = state
+		}
+	}
+	return ContextWithSpanContext(ctx, sc), nil
+}
+
+// joinHeaderValues combines repeated header fields into one list as permitted by RFC 9110.
+func joinHeaderValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	n := len(values) - 1
+	for _, v := range values {
+		n += len(v)
+	}
+	buf := make([]byte, 0, n)
+	for i, v := range values {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, v...)
+	}
+	return string(buf)
+}
+
+// Inject writes the traceparent and tracestate headers for the span context in ctx onto req.
+// It does nothing when ctx carries no valid span context.
+func Inject(ctx context.Context, req *http.Request) {
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	InjectHeader(ctx, req.Header)
+}
+
+// InjectHeader writes the traceparent and tracestate headers for the span context in ctx onto header.
+func InjectHeader(ctx context.Context, header http.Header) {
+	sc, ok := FromContext(ctx)
+	if !ok {
+		return
+	}
+	header.Set(TraceParentHeader, sc.TraceParent())
+	if state := sc.State.String(); state != "" {
+		header.Set(TraceStateHeader, state)
+	} else {
+		header.Del(TraceStateHeader)
+	}
+}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc, with its trace and span IDs and
+// sampled flag stored under the logger's typed context keys.
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	ctx = context.WithValue(ctx, spanContextKey{}, sc)
+	ctx = core.WithTraceID(ctx, sc.TraceIDHex())
+	ctx = core.WithSpanID(ctx, sc.SpanIDHex())
+	return core.WithTraceSampled(ctx, sc.IsSampled())
+}
+
+// FromContext returns the span context stored in ctx, if any.
+func FromContext(ctx context.Context) (SpanContext, bool) {
+	sc, ok := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc, ok && sc.IsValid()
+}
+
+// StartSpan creates a child span of the span context in ctx, keeping its trace ID, flags and
+// trace state. Without a parent it starts a new sampled trace.
+func StartSpan(ctx context.Context) (context.Context, SpanContext, error) {
+	parent, ok := FromContext(ctx)
+	var sc SpanContext
+	if ok {
+		sc.TraceID = parent.TraceID
+		sc.Flags = parent.Flags
+		sc.State = parent.State
+	} else {
+		if err := randomID(sc.TraceID[:]); err != nil {
+			return ctx, SpanContext{}, err
+		}
+		sc.Flags = FlagSampled
+	}
+	if err := randomID(sc.SpanID[:]); err != nil {
+		return ctx, SpanContext{}, err
+	}
+	return ContextWithSpanContext(ctx, sc), sc, nil
+}
+
+// randomID fills id with random bytes, retrying in the unlikely case that all bytes are zero.
+func randomID(id []byte) error {
+	for {
+		if _, err := rand.Read(id); err != nil {
+			return err
+		}
+		for _, b := range id {
+			if b != 0 {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package tracecontext
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"crystal/internal/core"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	tests := []struct {
+		input    string
+		hasError bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-extra", false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", true},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", true},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", true},
+		{"", true},
+	}
+
+	for _, tt := range tests {
+		sc, err := ParseTraceParent(tt.input)
+		if tt.hasError {
+			if err == nil {
+				t.Errorf("ParseTraceParent(%q) expected error, got none", tt.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseTraceParent(%q) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if tt.input[:2] == "00" && sc.TraceParent() != tt.input {
+			t.Errorf("TraceParent() = %q; expected %q", sc.TraceParent(), tt.input)
+		}
+	}
+}
+
+func TestParseTraceState(t *testing.T) {
+	ts, err := ParseTraceState("rojo=00f067aa0ba902b7, ,congo=t61rcWkgMzE,tenant@vendor=x")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ts.Len() != 3 {
+		t.Errorf("Expected 3 members, got %d", ts.Len())
+	}
+	updated, err := ts.Insert("congo", "new")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if updated.String() != "congo=new,rojo=00f067aa0ba902b7,tenant@vendor=x" {
+		t.Errorf("Unexpected tracestate after insert: %q", updated.String())
+	}
+
+	for _, invalid := range []string{"Rojo=1", "rojo=1,rojo=2", "rojo=a=b", "=1", "rojo="} {
+		if _, err := ParseTraceState(invalid); err == nil {
+			t.Errorf("ParseTraceState(%q) expected error, got none", invalid)
+		}
+	}
+}
+
+func TestExtractInjectAndLogging(t *testing.T) {
+	incoming := make(http.Header)
+	incoming.Set(TraceParentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	incoming.Add(TraceStateHeader, "rojo=00f067aa0ba902b7")
+	incoming.Add(TraceStateHeader, "congo=t61rcWkgMzE")
+
+	ctx, err := Extract(context.Background(), incoming)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	ctx, child, err := StartSpan(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if child.TraceIDHex() != "4bf92f3577b34da6a3ce929d0e0e4736" || child.SpanIDHex() == "00f067aa0ba902b7" {
+		t.Errorf("Expected child span in same trace with new span ID, got %s", child.TraceParent())
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	Inject(ctx, req)
+	if req.Header.Get(TraceParentHeader) != child.TraceParent() {
+		t.Errorf("Expected injected traceparent %q, got %q", child.TraceParent(), req.Header.Get(TraceParentHeader))
+	}
+	if req.Header.Get(TraceStateHeader) != "rojo=00f067aa0ba902b7,congo=t61rcWkgMzE" {
+		t.Errorf("Unexpected injected tracestate %q", req.Header.Get(TraceStateHeader))
+	}
+
+	var buf bytes.Buffer
+	logger := core.NewLogger(core.LoggerConfig{
+		Level:     core.INFO,
+		Output:    &buf,
+		Formatter: &core.JSONFormatter{ShowTraceInfo: true},
+	})
+	logger.InfoContext(ctx, "Outbound call")
+	output := buf.String()
+	if !strings.Contains(output, "4bf92f3577b34da6a3ce929d0e0e4736") || !strings.Contains(output, child.SpanIDHex()) {
+		t.Errorf("Expected trace and span IDs in log output, got %q", output)
+	}
+	if !strings.Contains(output, `"trace_sampled":true`) {
+		t.Errorf("Expected sampled flag in log output, got %q", output)
+	}
+}
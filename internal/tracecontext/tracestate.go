@@ -0,0 +1,173 @@
+package tracecontext
+
+import (
+	"strings"
+)
+
+const (
+	// maxTraceStateMembers is the maximum number of list members allowed in tracestate.
+	maxTraceStateMembers = 32
+
+	// maxTraceStateValueLen is the maximum length of a tracestate value.
+	maxTraceStateValueLen = 256
+)
+
+// TraceStateMember is a single vendor key-value pair in a tracestate header.
+type TraceStateMember struct {
+	Key   string
+	Value string
+}
+
+// TraceState holds the ordered list members of a tracestate header. The zero value is empty.
+type TraceState struct {
+	members []TraceStateMember
+}
+
+// ParseTraceState parses and validates a tracestate header value.
+// Empty list members are ignored; any malformed member or duplicate key invalidates the whole header.
+func ParseTraceState(value string) (TraceState, error) {
+	var ts TraceState
+	for _, part := range strings.Split(value, ",") {
+		part = strings.Trim(part, " \t")
+		if part == "" {
+			continue
+		}
+		eq := strings.IndexByte(part, '=')
+		if eq <= 0 {
+			return TraceState{}, ErrInvalidTraceState
+		}
+		key, val := part[:eq], part[eq+1:]
+		if !validKey(key) || !validValue(val) {
+			return TraceState{}, ErrInvalidTraceState
+		}
+		if _, exists := ts.Get(key); exists {
+			return TraceState{}, ErrInvalidTraceState
+		}
+		if len(ts.members) == maxTraceStateMembers {
+			return TraceState{}, ErrInvalidTraceState
+		}
+		ts.members = append(ts.members, TraceStateMember{Key: key, Value: val})
+	}
+	return ts, nil
+}
+
+// Members returns a copy of the list members in header order.
+func (ts TraceState) Members() []TraceStateMember {
+	members := make([]TraceStateMember, len(ts.members))
+	copy(members, ts.members)
+	return members
+}
+
+// Len returns the number of list members.
+func (ts TraceState) Len() int {
+	return len(ts.members)
+}
+
+// Get returns the value for key.
+func (ts TraceState) Get(key string) (string, bool) {
+	for _, m := range ts.members {
+		if m.Key == key {
+			return m.Value, true
+		}
+	}
+	return "", false
+}
+
+// Insert returns a new TraceState with key set to value and moved to the front, as the
+// specification requires when a vendor updates its entry. The rightmost member is dropped
+// when the list is full.
+func (ts TraceState) Insert(key, value string) (TraceState, error) {
+	if !validKey(key) || !validValue(value) {
+		return ts, ErrInvalidTraceState
+	}
+	members := make([]TraceStateMember, 0, len(ts.members)+1)
+	members = append(members, TraceStateMember{Key: key, Value: value})
+	for _, m := range ts.members {
+		if m.Key != key {
+			members = append(members, m)
+		}
+	}
+	if len(members) > maxTraceStateMembers {
+		members = members[:maxTraceStateMembers]
+	}
+	return TraceState{members: members}, nil
+}
+
+// Delete returns a new TraceState without key.
+func (ts TraceState) Delete(key string) TraceState {
+	members := make([]TraceStateMember, 0, len(ts.members))
+	for _, m := range ts.members {
+		if m.Key != key {
+			members = append(members, m)
+		}
+	}
+	return TraceState{members: members}
+}
+
+// String formats the list members as a tracestate header value.
+func (ts TraceState) String() string {
+	if len(ts.members) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, m := range ts.members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(m.Key)
+		b.WriteByte('=')
+		b.WriteString(m.Value)
+	}
+	return b.String()
+}
+
+// validKey checks a simple key or a multi-tenant tenant@system key.
+func validKey(key string) bool {
+	if at := strings.IndexByte(key, '@'); at >= 0 {
+		tenant, system := key[:at], key[at+1:]
+		if len(tenant) == 0 || len(tenant) > 241 || len(system) == 0 || len(system) > 14 {
+			return false
+		}
+		if !isLowerAlpha(tenant[0]) && !isDigit(tenant[0]) {
+			return false
+		}
+		return isLowerAlpha(system[0]) && validKeyChars(tenant[1:]) && validKeyChars(system[1:])
+	}
+	if len(key) == 0 || len(key) > 256 || !isLowerAlpha(key[0]) {
+		return false
+	}
+	return validKeyChars(key[1:])
+}
+
+// validKeyChars checks the characters allowed after the first character of a key.
+func validKeyChars(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !isLowerAlpha(c) && !isDigit(c) && c != '_' && c != '-' && c != '*' && c != '/' {
+			return false
+		}
+	}
+	return true
+}
+
+// validValue checks printable ASCII excluding ',' and '=' with no trailing space.
+func validValue(value string) bool {
+	if len(value) == 0 || len(value) > maxTraceStateValueLen || value[len(value)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+func isLowerAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}